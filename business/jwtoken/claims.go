@@ -4,11 +4,17 @@ import "github.com/golang-jwt/jwt/v5"
 
 type Claims struct {
 	JobNumber string           `json:"sub,omitzero"`
+	Actor     *Actor           `json:"act,omitzero"`
 	ExpiresAt *jwt.NumericDate `json:"exp,omitzero"`
 	NotBefore *jwt.NumericDate `json:"nbf,omitzero"`
 	IssuedAt  *jwt.NumericDate `json:"iat,omitzero"`
 }
 
+// Actor 实际操作人（RFC8693 act），管理员模拟其他用户登录时签发的 JWT 才有该字段。
+type Actor struct {
+	JobNumber string `json:"sub"`
+}
+
 func (c *Claims) GetExpirationTime() (*jwt.NumericDate, error) {
 	return c.ExpiresAt, nil
 }
@@ -32,9 +32,19 @@ func (iss *Issue) Secret() []byte {
 
 // Sign 签发 JWT。
 func (iss *Issue) Sign(jobNumber string, period time.Duration) (string, error) {
+	return iss.sign(jobNumber, nil, period)
+}
+
+// Impersonate 签发模拟登录的 JWT：actor 以 jobNumber 的身份访问。
+func (iss *Issue) Impersonate(actor, jobNumber string, period time.Duration) (string, error) {
+	return iss.sign(jobNumber, &Actor{JobNumber: actor}, period)
+}
+
+func (iss *Issue) sign(jobNumber string, actor *Actor, period time.Duration) (string, error) {
 	now := time.Now()
 	claim := &Claims{
 		JobNumber: jobNumber,
+		Actor:     actor,
 		ExpiresAt: jwt.NewNumericDate(now.Add(period)),
 		NotBefore: jwt.NewNumericDate(now),
 		IssuedAt:  jwt.NewNumericDate(now),
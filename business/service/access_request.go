@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+	"github.com/dfcfw/goproxy/integration/casauth"
+	"gorm.io/gorm"
+)
+
+func NewAccessRequest(qry *query.Query, cas casauth.Client, log *slog.Logger) *AccessRequest {
+	return &AccessRequest{
+		qry: qry,
+		cas: cas,
+		log: log,
+	}
+}
+
+// AccessRequest 访问申请：未注册的用户通过 CAS 认证后可以申请访问，由管理员审批。
+type AccessRequest struct {
+	qry *query.Query
+	cas casauth.Client
+	log *slog.Logger
+}
+
+// Submit 提交访问申请，同一个人重复提交时只会更新待审批的申请。
+//
+// 匿名接口必须先通过 CAS 认证再判断用户是否已存在，否则可以据此枚举已注册的工号。
+func (acr *AccessRequest) Submit(ctx context.Context, jobNumber, passwd string, req *request.AccessRequestSubmit) (*model.AccessRequest, error) {
+	profile, err := acr.cas.Auth(ctx, jobNumber, passwd)
+	if err != nil {
+		return nil, err
+	}
+	if acr.userExists(ctx, jobNumber) {
+		return nil, errcode.ErrUserExists
+	}
+
+	tbl := acr.qry.AccessRequest
+	dao := tbl.WithContext(ctx)
+	dat, err := dao.Where(tbl.JobNumber.Eq(jobNumber), tbl.Status.Eq(model.AccessRequestPending)).First()
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		dat = &model.AccessRequest{
+			JobNumber: jobNumber,
+			Name:      profile.Name,
+			Reason:    req.Reason,
+			Status:    model.AccessRequestPending,
+		}
+		if err = dao.Create(dat); err != nil {
+			return nil, err
+		}
+
+		return dat, nil
+	}
+
+	dat.Name, dat.Reason = profile.Name, req.Reason
+	if _, err = dao.Where(tbl.ID.Eq(dat.ID)).
+		UpdateColumnSimple(
+			tbl.Name.Value(dat.Name),
+			tbl.Reason.Value(dat.Reason),
+		); err != nil {
+		return nil, err
+	}
+
+	return dat, nil
+}
+
+// Latest 查询某人最近一次的访问申请。
+func (acr *AccessRequest) Latest(ctx context.Context, jobNumber, passwd string) (*model.AccessRequest, error) {
+	if _, err := acr.cas.Auth(ctx, jobNumber, passwd); err != nil {
+		return nil, err
+	}
+
+	tbl := acr.qry.AccessRequest
+	dao := tbl.WithContext(ctx)
+	dat, err := dao.Where(tbl.JobNumber.Eq(jobNumber)).Order(tbl.ID.Desc()).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errcode.ErrDataNotExists
+	}
+
+	return dat, err
+}
+
+func (acr *AccessRequest) List(ctx context.Context, req *request.AccessRequestList) ([]*model.AccessRequest, error) {
+	tbl := acr.qry.AccessRequest
+	dao := tbl.WithContext(ctx)
+	if status := req.Status; status != "" {
+		dao = dao.Where(tbl.Status.Eq(status))
+	}
+
+	return dao.Order(tbl.ID.Desc()).Find()
+}
+
+// Approve 同意访问申请，并创建对应的普通用户。
+func (acr *AccessRequest) Approve(ctx context.Context, reviewer string, req *request.AccessRequestReview) error {
+	return acr.qry.Transaction(func(tx *query.Query) error {
+		dat, err := acr.review(ctx, tx, reviewer, req, model.AccessRequestApproved)
+		if err != nil {
+			return err
+		}
+
+		user := &model.User{JobNumber: dat.JobNumber, Name: dat.Name}
+
+		return tx.User.WithContext(ctx).Create(user)
+	})
+}
+
+// Deny 拒绝访问申请。
+func (acr *AccessRequest) Deny(ctx context.Context, reviewer string, req *request.AccessRequestReview) error {
+	return acr.qry.Transaction(func(tx *query.Query) error {
+		_, err := acr.review(ctx, tx, reviewer, req, model.AccessRequestDenied)
+		return err
+	})
+}
+
+func (acr *AccessRequest) review(ctx context.Context, tx *query.Query, reviewer string, req *request.AccessRequestReview, status string) (*model.AccessRequest, error) {
+	tbl := tx.AccessRequest
+	dao := tbl.WithContext(ctx)
+	dat, err := dao.Where(tbl.ID.Eq(req.ID), tbl.Status.Eq(model.AccessRequestPending)).First()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errcode.ErrDataNotExists
+		}
+		return nil, err
+	}
+	AuditTarget(ctx, dat.JobNumber)
+	AuditDetail(ctx, "request_id", dat.ID)
+
+	if _, err = dao.Where(tbl.ID.Eq(dat.ID)).
+		UpdateColumnSimple(
+			tbl.Status.Value(status),
+			tbl.Reviewer.Value(reviewer),
+			tbl.Remark.Value(req.Remark),
+			tbl.ReviewedAt.Value(time.Now()),
+		); err != nil {
+		return nil, err
+	}
+
+	return dat, nil
+}
+
+func (acr *AccessRequest) userExists(ctx context.Context, jobNumber string) bool {
+	tbl := acr.qry.User
+	dao := tbl.WithContext(ctx)
+	cnt, _ := dao.Where(tbl.JobNumber.Eq(jobNumber)).Count()
+
+	return cnt != 0
+}
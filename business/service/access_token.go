@@ -36,6 +36,8 @@ func (pat *AccessToken) List(ctx context.Context, jobNumber string) ([]*model.Ac
 }
 
 func (pat *AccessToken) Create(ctx context.Context, jobNumber string, req *request.AccessTokenCreate) (*model.AccessToken, error) {
+	AuditTarget(ctx, req.Name)
+	AuditDetail(ctx, "expired_at", req.ExpiredAt)
 	now := time.Now()
 	buf := make([]byte, 28)
 	binary.LittleEndian.PutUint64(buf, uint64(now.UnixNano()))
@@ -67,6 +69,7 @@ func (pat *AccessToken) Create(ctx context.Context, jobNumber string, req *reque
 }
 
 func (pat *AccessToken) Delete(ctx context.Context, jobNumber, name string) error {
+	AuditTarget(ctx, name)
 	tbl := pat.qry.AccessToken
 	dao := tbl.WithContext(ctx)
 	ret, err := dao.Where(tbl.JobNumber.Eq(jobNumber), tbl.Name.Eq(name)).Delete()
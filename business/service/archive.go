@@ -0,0 +1,134 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	modzip "golang.org/x/mod/zip"
+)
+
+// 支持的压缩包格式。
+const (
+	ArchiveZip    = "zip"
+	ArchiveTar    = "tar"
+	ArchiveTarGz  = "tar.gz"
+	ArchiveTarZst = "tar.zst"
+)
+
+// DetectArchive 根据文件头的魔数识别压缩包格式，无法识别时返回空。
+func DetectArchive(r io.ReaderAt) string {
+	head := make([]byte, 512)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return ArchiveZip
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return ArchiveTarGz
+	case bytes.HasPrefix(head, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return ArchiveTarZst
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return ArchiveTar
+	}
+
+	return ""
+}
+
+// OpenArchive 打开 zip、tar、tar.gz、tar.zst 格式的压缩包。
+//
+// 非 zip 格式会原样转存为临时 zip 文件，调用方使用完毕后需要调用 cleanup 删除临时文件。
+func (gmd *Gomod) OpenArchive(r io.ReaderAt, size int64) (zr *zip.Reader, cleanup func(), err error) {
+	cleanup = func() {}
+	var rd io.Reader = io.NewSectionReader(r, 0, size)
+	switch DetectArchive(r) {
+	case ArchiveZip:
+		zr, err = zip.NewReader(r, size)
+		return zr, cleanup, err
+	case ArchiveTar:
+	case ArchiveTarGz:
+		gr, err := gzip.NewReader(rd)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		defer gr.Close()
+		rd = gr
+	case ArchiveTarZst:
+		zd, err := zstd.NewReader(rd)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		defer zd.Close()
+		rd = zd
+	default:
+		return nil, cleanup, errors.New("不支持的压缩包格式，仅支持 zip、tar、tar.gz、tar.zst")
+	}
+
+	temp, err := os.CreateTemp(os.TempDir(), "gomod_tar_*.zip")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+	}
+	if err = tarToZip(temp, rd); err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+	inf, err := temp.Stat()
+	if err == nil {
+		zr, err = zip.NewReader(temp, inf.Size())
+	}
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+
+	return zr, cleanup, nil
+}
+
+// tarToZip 将 tar 中的普通文件逐个写入 zip，解压后的总大小不能超过 go 模块的大小限制。
+func tarToZip(w io.Writer, r io.Reader) error {
+	tr := tar.NewReader(r)
+	zw := zip.NewWriter(w)
+	remain := int64(modzip.MaxZipFile)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+		if name == "." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			continue
+		}
+		if remain -= hdr.Size; remain < 0 {
+			return errors.New("压缩包解压后超过了大小限制")
+		}
+
+		fh := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: hdr.ModTime}
+		fh.SetMode(hdr.FileInfo().Mode())
+		fw, err := zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+		if _, err = io.CopyN(fw, tr, hdr.Size); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+)
+
+// likeEscaper 转义 LIKE 中的通配符，配合 ESCAPE '!' 使用（MySQL 与 SQLite 都支持）。
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func NewAudit(qry *query.Query, log *slog.Logger) *Audit {
+	return &Audit{
+		qry:     qry,
+		log:     log,
+		maxSize: 200,
+	}
+}
+
+// Audit 审计日志。
+type Audit struct {
+	qry     *query.Query
+	log     *slog.Logger
+	maxSize int64
+}
+
+// Write 写入一条审计日志，并合并 context 中由业务代码补充的操作对象与详细信息。
+func (aud *Audit) Write(ctx context.Context, evt *model.AuditEvent) {
+	if note := auditNoteFrom(ctx); note != nil {
+		target, details := note.load()
+		if target != "" {
+			evt.Target = target
+		}
+		if len(details) != 0 {
+			raw, _ := json.Marshal(details)
+			evt.Details = string(raw)
+		}
+	}
+
+	// 审计日志不能因为请求被取消而丢失。
+	ctx = context.WithoutCancel(ctx)
+	if err := aud.qry.AuditEvent.WithContext(ctx).Create(evt); err != nil {
+		aud.log.ErrorContext(ctx, "写入审计日志错误", slog.Any("event", evt), slog.Any("error", err))
+	}
+}
+
+func (aud *Audit) Page(ctx context.Context, req *request.AuditEventPage) (*response.Page[*model.AuditEvent], error) {
+	tbl := aud.qry.AuditEvent
+	dao := tbl.WithContext(ctx).Where(aud.conds(&req.AuditEventFilter)...)
+
+	offset, limit := req.Limit(aud.maxSize)
+	records, total, err := dao.Order(tbl.ID.Desc()).FindByPage(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Page[*model.AuditEvent]{Total: total, Records: records}, nil
+}
+
+// Export 按照 JSON Lines 格式导出审计日志。
+func (aud *Audit) Export(ctx context.Context, w io.Writer, req *request.AuditEventFilter) error {
+	tbl := aud.qry.AuditEvent
+	dao := tbl.WithContext(ctx).Where(aud.conds(req)...)
+
+	enc := json.NewEncoder(w)
+	var records []*model.AuditEvent
+	return dao.Order(tbl.ID).FindInBatches(&records, 500, func(tx gen.Dao, _ int) error {
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (aud *Audit) conds(req *request.AuditEventFilter) []gen.Condition {
+	tbl := aud.qry.AuditEvent
+	var conds []gen.Condition
+	if v := req.JobNumber; v != "" {
+		conds = append(conds, tbl.JobNumber.Eq(v))
+	}
+	if v := req.Action; v != "" {
+		conds = append(conds, tbl.Action.Eq(v))
+	}
+	if v := req.Outcome; v != "" {
+		conds = append(conds, tbl.Outcome.Eq(v))
+	}
+	if v := req.Target; v != "" {
+		// 模块路径中常见 _，需要转义 LIKE 的通配符。
+		pattern := "%" + likeEscaper.Replace(v) + "%"
+		conds = append(conds, field.NewUnsafeFieldRaw("? LIKE ? ESCAPE '!'", tbl.Target.RawExpr(), pattern))
+	}
+	if v := req.From; !v.IsZero() {
+		conds = append(conds, tbl.CreatedAt.Gte(v))
+	}
+	if v := req.To; !v.IsZero() {
+		conds = append(conds, tbl.CreatedAt.Lt(v))
+	}
+
+	return conds
+}
+
+// NewAuditContext 在 context 中放入审计信息的载体，业务代码可以通过
+// AuditTarget 与 AuditDetail 补充操作对象和详细信息。
+func NewAuditContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, auditNoteKey{}, new(auditNote))
+}
+
+// AuditTarget 设置审计日志的操作对象，例如：模块 path@version、工号等。
+func AuditTarget(ctx context.Context, target string) {
+	if note := auditNoteFrom(ctx); note != nil {
+		note.mutex.Lock()
+		note.target = target
+		note.mutex.Unlock()
+	}
+}
+
+// AuditDetail 补充审计日志的详细信息。
+func AuditDetail(ctx context.Context, key string, val any) {
+	if note := auditNoteFrom(ctx); note != nil {
+		note.mutex.Lock()
+		if note.details == nil {
+			note.details = make(map[string]any, 8)
+		}
+		note.details[key] = val
+		note.mutex.Unlock()
+	}
+}
+
+type auditNoteKey struct{}
+
+type auditNote struct {
+	mutex   sync.Mutex
+	target  string
+	details map[string]any
+}
+
+func (n *auditNote) load() (string, map[string]any) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.target, maps.Clone(n.details)
+}
+
+func auditNoteFrom(ctx context.Context) *auditNote {
+	note, _ := ctx.Value(auditNoteKey{}).(*auditNote)
+	return note
+}
@@ -11,29 +11,44 @@ import (
 	"io"
 	"log/slog"
 	"mime/multipart"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
 	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
 	"golang.org/x/mod/sumdb/dirhash"
 	modzip "golang.org/x/mod/zip"
+	"gorm.io/gen/field"
 )
 
 type Gomod struct {
-	dir string
-	log *slog.Logger
+	dir       string
+	trash     string        // 回收站目录
+	retention time.Duration // 回收站保留时长
+	qry       *query.Query
+	policy    *UploadPolicy
+	log       *slog.Logger
 }
 
-func NewGomod(dir string, log *slog.Logger) *Gomod {
+// NewGomod dir 为模块存储目录，删除的模块移入 trash 回收站目录保留 retention 后彻底删除。
+func NewGomod(dir, trash string, retention time.Duration, qry *query.Query, policy *UploadPolicy, log *slog.Logger) *Gomod {
 	return &Gomod{
-		dir: dir,
-		log: log,
+		dir:       dir,
+		trash:     trash,
+		retention: retention,
+		qry:       qry,
+		policy:    policy,
+		log:       log,
 	}
 }
 
@@ -104,8 +119,14 @@ func (gmd *Gomod) Walk(_ context.Context, rawpath string) (*response.GomodWalk,
 	return ret, nil
 }
 
-func (gmd *Gomod) Stat(_ context.Context, modpath, version string) (response.GomodFiles, error) {
+func (gmd *Gomod) Stat(ctx context.Context, modpath, version string) (*response.GomodStat, error) {
 	var err error
+	ret := new(response.GomodStat)
+	tbl := gmd.qry.GomodVersion
+	if pub, _ := tbl.WithContext(ctx).Where(tbl.Path.Eq(modpath), tbl.Version.Eq(version)).First(); pub != nil {
+		ret.Publisher = pub
+	}
+
 	if modpath, err = module.EscapePath(modpath); err != nil {
 		return nil, err
 	}
@@ -119,7 +140,7 @@ func (gmd *Gomod) Stat(_ context.Context, modpath, version string) (response.Gom
 		return nil, err
 	}
 
-	ret := make(response.GomodFiles, 0, 10)
+	files := make(response.GomodFiles, 0, 10)
 	for _, ent := range entries {
 		ename := ent.Name()
 		if ent.IsDir() {
@@ -143,119 +164,304 @@ func (gmd *Gomod) Stat(_ context.Context, modpath, version string) (response.Gom
 			gmf.ModifiedAt = inf.ModTime()
 		}
 
-		ret = append(ret, gmf)
+		files = append(files, gmf)
 	}
+	ret.Files = files
 
 	return ret, nil
 }
 
-func (gmd *Gomod) Sniff(mf multipart.File, size int64) (*response.GomodSniff, error) {
-	zr, err := zip.NewReader(mf, size)
+// Format 将普通的源码压缩包转换为 go 模块 zip。
+//
+// 压缩包只有唯一的顶层目录时（例如 GitHub 下载的 repo-main/）会自动剥离，模块位于子目录时根据
+// 模块路径查找对应的 go.mod，modpath 为空时根据 go.mod 自动检测。嵌套模块与 vendor 目录按照
+// go 模块 zip 的规则排除，剥离的目录与排除的文件都会在返回的报告中列出。
+func (gmd *Gomod) Format(w io.Writer, zr *zip.Reader, modpath, version string) (*response.GomodFormat, error) {
+	root, modpath, err := gmd.moduleRoot(zr, modpath)
 	if err != nil {
 		return nil, err
 	}
+	if err = module.CheckPath(modpath); err != nil {
+		return nil, err
+	}
+	if _, err = module.EscapeVersion(version); err != nil {
+		return nil, err
+	}
 
-	ret := new(response.GomodSniff)
+	ret := &response.GomodFormat{
+		Path:    modpath,
+		Version: version,
+		Root:    root,
+		Omitted: []*response.GomodCheckedFile{},
+	}
+	var files []modzip.File
 	for _, zf := range zr.File {
-		name := zf.Name
-		if !strings.Contains(name, "@v") {
+		if zf.FileInfo().IsDir() {
 			continue
 		}
-		before, after, _ := strings.Cut(name, "@")
-		after, _, _ = strings.Cut(after, "/")
-		if module.CheckPath(before) == nil &&
-			semver.IsValid(after) {
-			ret.Path = before
-			ret.Version = after
-			break
+		name, ok := strings.CutPrefix(zf.Name, root)
+		if !ok {
+			ret.Omitted = append(ret.Omitted, &response.GomodCheckedFile{Path: zf.Name, Reason: "不在模块目录内"})
+			continue
 		}
+		files = append(files, &zipFile{f: zf, name: name})
+	}
+	cf, _ := modzip.CheckFiles(files)
+	for _, fe := range cf.Omitted {
+		ret.Omitted = append(ret.Omitted, &response.GomodCheckedFile{Path: root + fe.Path, Reason: fe.Err.Error()})
+	}
+
+	mdv := module.Version{Path: modpath, Version: version}
+	if err = modzip.Create(w, mdv, files); err != nil {
+		return nil, err
 	}
 
 	return ret, nil
 }
 
-func (gmd *Gomod) Format(w io.Writer, zr *zip.Reader, modpath, version string) error {
-	var err error
-	rawpath := modpath
-	if modpath, err = module.EscapePath(modpath); err != nil {
-		return err
+// FormatTemp 将转换后的 zip 写入临时文件，避免在内存中缓存整个文件。
+//
+// 返回的文件已经定位到开头，调用方负责关闭并删除。
+func (gmd *Gomod) FormatTemp(zr *zip.Reader, modpath, version string) (*os.File, *response.GomodFormat, error) {
+	temp, err := os.CreateTemp(os.TempDir(), "gomod_format_*.zip")
+	if err != nil {
+		return nil, nil, err
 	}
-	if version, err = module.EscapeVersion(version); err != nil {
-		return err
+	ret, err := gmd.Format(temp, zr, modpath, version)
+	if err == nil {
+		_, err = temp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return nil, nil, err
 	}
 
-	var files []modzip.File
+	return temp, ret, nil
+}
+
+// moduleRoot 定位模块在压缩包中的根目录（以 / 结尾，位于压缩包根路径时为空），并返回模块路径。
+func (gmd *Gomod) moduleRoot(zr *zip.Reader, modpath string) (string, string, error) {
+	top := archiveTop(zr)
+	gomods := gmd.archiveGomods(zr)
+
+	var found *archiveGomod
+	for _, gm := range gomods {
+		if modpath != "" && gm.path != modpath {
+			continue
+		}
+		if modpath == "" && gm.dir != top {
+			continue
+		}
+		if found == nil || len(gm.dir) < len(found.dir) {
+			found = gm
+		}
+	}
+	if found == nil && modpath == "" && len(gomods) == 1 {
+		found = gomods[0]
+	}
+	if found != nil && found.path != "" {
+		return found.dir, found.path, nil
+	}
+	if modpath == "" {
+		if len(gomods) > 1 {
+			return "", "", errors.New("压缩包中包含多个模块，请输入模块名")
+		}
+		return "", "", errors.New("未检测到模块名，请输入模块名")
+	}
+	for _, gm := range gomods {
+		if gm.dir == top && gm.path != "" {
+			return "", "", fmt.Errorf("模块名不匹配 (输入为 %s, 检测到 %s)", modpath, gm.path)
+		}
+	}
+
+	return top, modpath, nil
+}
+
+// archiveTop 所有文件都位于同一个顶层目录下时返回该目录（以 / 结尾），否则返回空。
+func archiveTop(zr *zip.Reader) string {
+	var top string
+	for i, zf := range zr.File {
+		first, _, ok := strings.Cut(zf.Name, "/")
+		if !ok && !zf.FileInfo().IsDir() {
+			return ""
+		}
+		if i == 0 {
+			top = first
+		} else if first != top {
+			return ""
+		}
+	}
+	if top != "" {
+		top += "/"
+	}
+
+	return top
+}
+
+// archiveGomod 压缩包中的 go.mod 文件。
+type archiveGomod struct {
+	dir  string // go.mod 所在目录，以 / 结尾，位于根路径时为空
+	path string // go.mod 中声明的模块路径
+}
+
+// archiveGomods 查找压缩包中除 vendor 目录以外的所有 go.mod 文件。
+func (gmd *Gomod) archiveGomods(zr *zip.Reader) []*archiveGomod {
+	var gomods []*archiveGomod
 	for _, zf := range zr.File {
-		files = append(files, &zipFile{f: zf})
-		if zf.Name == "go.mod" {
-			modf, err := zf.Open()
-			if err != nil {
-				continue
-			}
-			buf, _ := io.ReadAll(modf)
-			pf, err := modfile.Parse(zf.Name, buf, nil)
-			if err != nil {
-				continue
-			}
-			m := pf.Module
-			if m == nil {
-				continue
-			}
-			if mp := m.Mod.Path; mp != rawpath {
-				return fmt.Errorf("模块名不匹配 (输入为 %s, 检测到 %s)", rawpath, mp)
-			}
+		dir, base := path.Split(zf.Name)
+		if base != "go.mod" || zf.FileInfo().IsDir() {
+			continue
 		}
+		if dir == "vendor/" || strings.HasPrefix(dir, "vendor/") || strings.Contains(dir, "/vendor/") {
+			continue
+		}
+		gomods = append(gomods, &archiveGomod{dir: dir, path: gmd.modulePath(zf)})
+	}
+
+	return gomods
+}
+
+// modulePath 读取 go.mod 中声明的模块路径，读取或解析失败返回空。
+func (gmd *Gomod) modulePath(zf *zip.File) string {
+	modf, err := zf.Open()
+	if err != nil {
+		return ""
+	}
+	defer modf.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(modf, modzip.MaxGoMod))
+	if err != nil {
+		return ""
 	}
-	mdv := module.Version{Path: modpath, Version: version}
 
-	return modzip.Create(w, mdv, files)
+	return modfile.ModulePath(buf)
 }
 
+// Publish 发布模块，zip 格式直接按照模块 zip 上传，tar 等源码压缩包先转换为模块 zip 再上传。
+func (gmd *Gomod) Publish(ctx context.Context, pub *request.GomodPublisher, mf multipart.File, size int64, modpath, version string, dryRun bool) (*response.GomodUpload, error) {
+	if DetectArchive(mf) == ArchiveZip {
+		return gmd.Upload(ctx, pub, mf, modpath, version, dryRun)
+	}
+
+	zr, cleanup, err := gmd.OpenArchive(mf, size)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	temp, report, err := gmd.FormatTemp(zr, modpath, version)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	ret, err := gmd.Upload(ctx, pub, temp, report.Path, report.Version, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	ret.Format = report
+
+	return ret, nil
+}
+
+// Upload 校验并发布模块 zip。已发布的版本不可变：哈希相同的重复上传直接返回成功，
+// 哈希不同返回 409，需要先删除旧版本才能重新发布。
+func (gmd *Gomod) Upload(ctx context.Context, pub *request.GomodPublisher, mf multipart.File, modpath, version string, dryRun bool) (*response.GomodUpload, error) {
+	return gmd.upload(ctx, pub, mf, modpath, version, dryRun, false)
+}
+
+// upload overwrite 为 true 时覆盖哈希不同的已有版本，只用于强制导入。
+//
 //goland:noinspection GoUnhandledErrorResult
-func (gmd *Gomod) Upload(mf multipart.File, modpath, version string) error {
-	now := time.Now()
-	minf := &moduleInfo{Version: version, Time: now}
+func (gmd *Gomod) upload(ctx context.Context, pub *request.GomodPublisher, mf multipart.File, modpath, version string, dryRun, overwrite bool) (*response.GomodUpload, error) {
+	AuditTarget(ctx, modpath+"@"+version)
+	if dryRun {
+		AuditDetail(ctx, "dry_run", true)
+	}
+	if pub.PipelineURL != "" && !isHTTPURL(pub.PipelineURL) {
+		return nil, errcode.ErrPipelineURL
+	}
+	vtime := pub.Time
+	if vtime.IsZero() {
+		vtime = time.Now()
+	}
+	minf := &response.GomodInfo{Version: version, Time: vtime}
+	if pub.Repository != "" || pub.CommitSHA != "" {
+		minf.Origin = &response.GomodOrigin{VCS: "git", URL: pub.Repository, Hash: pub.CommitSHA, Ref: pub.Ref}
+	}
 	mdv := module.Version{Path: modpath, Version: version}
+	record := &model.GomodVersion{
+		Path:        modpath,
+		Version:     version,
+		JobNumber:   pub.JobNumber,
+		TokenName:   pub.TokenName,
+		ClientIP:    pub.ClientIP,
+		CommitSHA:   pub.CommitSHA,
+		PipelineURL: pub.PipelineURL,
+		Repository:  pub.Repository,
+	}
 	var err error
 	if modpath, err = module.EscapePath(modpath); err != nil {
-		return err
+		return nil, err
 	}
 	if version, err = module.EscapeVersion(version); err != nil {
-		return err
-	}
-
-	dir := filepath.Join(gmd.dir, modpath, "@v")
-	if _, exx := os.Stat(dir); exx != nil {
-		if !os.IsNotExist(exx) {
-			return exx
-		}
-		if err = os.MkdirAll(dir, 0o755); err != nil {
-			return err
-		}
+		return nil, err
 	}
 
 	temp, err := os.CreateTemp(os.TempDir(), "gomod_*.zip")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	tempName := temp.Name()
-	_, err = io.Copy(temp, mf)
+	size, err := io.Copy(temp, mf)
 	_ = temp.Close()
 	defer os.Remove(tempName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if _, err = modzip.CheckZip(mdv, tempName); err != nil {
-		return errors.New("不是合法的 go 模块")
+	cf, err := modzip.CheckZip(mdv, tempName)
+	checked := checkedReport(cf)
+	if err != nil {
+		return nil, &checkError{err: err, report: checked}
 	}
 
 	// 读取 go.mod 文件
 	zr, err := zip.OpenReader(tempName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer zr.Close()
 
+	if vs := gmd.policy.Evaluate(mdv, &zr.Reader, size); len(vs) != 0 {
+		AuditDetail(ctx, "violations", vs)
+		return nil, vs
+	}
+	hash, err := dirhash.HashZip(tempName, dirhash.DefaultHash)
+	if err != nil {
+		return nil, err
+	}
+	AuditDetail(ctx, "hash", hash)
+	record.Hash = hash
+	ret := &response.GomodUpload{
+		Path:    mdv.Path,
+		Version: mdv.Version,
+		Hash:    hash,
+		Size:    size,
+		DryRun:  dryRun,
+		Checked: checked,
+	}
+	if stored := gmd.storedHash(mdv.Path, mdv.Version); stored == hash {
+		return ret, nil
+	} else if stored != "" && !overwrite {
+		AuditDetail(ctx, "stored_hash", stored)
+		return nil, errcode.FmtVersionConflict.Fmt(mdv.String(), stored)
+	}
+	if dryRun {
+		return ret, nil
+	}
+
 	var modok, mdok bool
 	modbuf := new(bytes.Buffer)
 	mdbuf := new(bytes.Buffer)
@@ -279,29 +485,36 @@ func (gmd *Gomod) Upload(mf multipart.File, modpath, version string) error {
 		}
 	}
 
+	dir := filepath.Join(gmd.dir, modpath, "@v")
+	if _, exx := os.Stat(dir); exx != nil {
+		if !os.IsNotExist(exx) {
+			return nil, exx
+		}
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
 	{
 		// 存放 .zip
 		zname := filepath.Join(dir, version+".zip")
 		zfile, err := os.OpenFile(zname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		tfile, err := os.Open(tempName)
 		if err != nil {
 			_ = zfile.Close()
-			return err
+			return nil, err
 		}
 		_, err = io.Copy(zfile, tfile)
 		_ = tfile.Close()
 		_ = zfile.Close()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if h, err := dirhash.HashZip(zfile.Name(), dirhash.DefaultHash); err == nil {
-			hname := filepath.Join(dir, version+".ziphash")
-			os.WriteFile(hname, []byte(h), 0o644)
-		}
+		hname := filepath.Join(dir, version+".ziphash")
+		os.WriteFile(hname, []byte(hash), 0o644)
 	}
 	if mdok && mdbuf.Len() != 0 {
 		// 提取 go.mod
@@ -319,23 +532,23 @@ func (gmd *Gomod) Upload(mf multipart.File, modpath, version string) error {
 		}
 		mfile, err := os.OpenFile(filepath.Join(dir, version+".mod"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		_, err = io.Copy(mfile, modbuf)
 		_ = mfile.Close()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	{
 		ifile, err := os.OpenFile(filepath.Join(dir, version+".info"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		err = json.NewEncoder(ifile).Encode(minf)
 		_ = ifile.Close()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	{
@@ -346,7 +559,7 @@ func (gmd *Gomod) Upload(mf multipart.File, modpath, version string) error {
 		if inf, exx := os.Stat(fstr); exx == nil && !inf.IsDir() {
 			old, err := os.Open(fstr)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			sc := bufio.NewScanner(old)
 			for sc.Scan() {
@@ -365,7 +578,7 @@ func (gmd *Gomod) Upload(mf multipart.File, modpath, version string) error {
 		semver.Sort(versions)
 		fd, err := os.OpenFile(fstr, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		for _, ver := range versions {
 			fd.WriteString(ver + "\n")
@@ -373,7 +586,20 @@ func (gmd *Gomod) Upload(mf multipart.File, modpath, version string) error {
 		_ = fd.Close()
 	}
 
-	return nil
+	// 记录发布人信息，重复上传同一版本时以最后一次为准。
+	err = gmd.qry.Transaction(func(tx *query.Query) error {
+		tbl := tx.GomodVersion
+		dao := tbl.WithContext(ctx)
+		if _, err := dao.Where(tbl.Path.Eq(mdv.Path), tbl.Version.Eq(mdv.Version)).Delete(); err != nil {
+			return err
+		}
+		return dao.Create(record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
 }
 
 func (gmd *Gomod) Open(rawpath string, filename string) (*os.File, error) {
@@ -394,50 +620,85 @@ func (gmd *Gomod) Open(rawpath string, filename string) (*os.File, error) {
 	return os.Open(fpath)
 }
 
-func (gmd *Gomod) Delete(rawpath string, rawversion string) error {
+// Delete 将模块版本移入回收站，Version 为空时删除整个模块（包括嵌套的子模块），此时 Confirm 必须为模块路径。
+//
+// 仓库中还有其它模块依赖待删除的版本，或者有使用方通过代理下载过时拒绝删除并列出依赖方与下载记录，
+// 除非 Force 并填写原因。
+func (gmd *Gomod) Delete(ctx context.Context, jobNumber string, req *request.GomodDelete) error {
+	rawpath, rawversion := req.Path, req.Version
+	if rawversion == "" {
+		AuditTarget(ctx, rawpath)
+		if req.Confirm != rawpath {
+			return errcode.FmtDeleteConfirm.Fmt(rawpath)
+		}
+	} else {
+		AuditTarget(ctx, rawpath+"@"+rawversion)
+	}
+	if req.Force {
+		if strings.TrimSpace(req.Reason) == "" {
+			return errcode.ErrDeleteReason
+		}
+		AuditDetail(ctx, "force", true)
+		AuditDetail(ctx, "reason", req.Reason)
+	}
+
+	blocked, err := gmd.deleteBlocked(ctx, rawpath, rawversion)
+	if err != nil {
+		return err
+	}
+	if len(blocked.Dependents) != 0 || blocked.Downloads != 0 {
+		AuditDetail(ctx, "dependents", len(blocked.Dependents))
+		AuditDetail(ctx, "downloads", blocked.Downloads)
+		if !req.Force {
+			return &deleteError{report: blocked}
+		}
+	}
+
+	return gmd.moveToTrash(ctx, jobNumber, rawpath, rawversion, req.Reason)
+}
+
+// nestedPath 匹配 modpath 目录下嵌套的模块路径（modpath/...）。
+//
+// 模块路径中可以包含 _ 等 LIKE 通配符，使用前缀比较而不是 LIKE，避免误匹配其它模块。
+func nestedPath(col field.String, modpath string) field.Expr {
+	prefix := modpath + "/"
+
+	return col.Substr(1, len(prefix)).Eq(prefix)
+}
+
+// remove 直接删除模块版本的文件与发布记录，不检查依赖方也不进入回收站，用于撤销刚发布的版本。
+func (gmd *Gomod) remove(ctx context.Context, rawpath string, rawversion string) error {
 	modpath, err := module.EscapePath(rawpath)
 	if err != nil {
 		return err
 	}
+	tbl := gmd.qry.GomodVersion
+	dao := tbl.WithContext(ctx)
 	if rawversion == "" {
 		dir := filepath.Join(gmd.dir, modpath)
-		return os.RemoveAll(dir)
+		if err = os.RemoveAll(dir); err != nil {
+			return err
+		}
+		// 目录下嵌套的模块（例如 /v2）也一并被删除了。
+		_, err = dao.Where(field.Or(tbl.Path.Eq(rawpath), nestedPath(tbl.Path, rawpath))).Delete()
+		return err
 	}
 	modversion, err := module.EscapeVersion(rawversion)
 	if err != nil {
 		return err
 	}
 
+	// list 中记录的是原始版本号，不是转义后的版本号。
 	flist := filepath.Join(gmd.dir, modpath, "@v", "list")
-	var versions []string
-	{
-		file, err := os.Open(flist)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return nil
-			}
-			return err
+	if _, err = os.Stat(flist); err != nil {
+		if os.IsNotExist(err) {
+			return nil
 		}
-
-		sc := bufio.NewScanner(file)
-		for sc.Scan() {
-			line := sc.Text()
-			if line != modversion {
-				versions = append(versions, line)
-			}
-		}
-		_ = file.Close()
-	}
-
-	semver.Sort(versions)
-	fd, err := os.OpenFile(flist, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
-	if err != nil {
 		return err
 	}
-	for _, ver := range versions {
-		fd.WriteString(ver + "\n")
+	if err = updateList(flist, nil, rawversion); err != nil {
+		return err
 	}
-	_ = fd.Close()
 
 	dir := filepath.Join(gmd.dir, modpath, "@v")
 	entries, _ := os.ReadDir(dir)
@@ -453,21 +714,28 @@ func (gmd *Gomod) Delete(rawpath string, rawversion string) error {
 		dname := filepath.Join(dir, name)
 		_ = os.Remove(dname)
 	}
+	_, err = dao.Where(tbl.Path.Eq(rawpath), tbl.Version.Eq(rawversion)).Delete()
 
-	return nil
+	return err
 }
 
-type moduleInfo struct {
-	Version string    `json:",omitempty"`
-	Time    time.Time `json:",omitempty"`
+// isHTTPURL 是否为 http 或 https 链接，流水线地址会在页面中展示为链接。
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
 type zipFile struct {
-	f *zip.File
+	f    *zip.File
+	name string // 相对于模块根目录的路径
 }
 
 func (z *zipFile) Path() string {
-	return strings.TrimRight(z.f.Name, "/")
+	return strings.TrimRight(z.name, "/")
 }
 
 func (z *zipFile) Lstat() (os.FileInfo, error) {
@@ -490,3 +758,43 @@ func dumpZip(f *zip.File) []byte {
 
 	return buf
 }
+
+// checkError 模块 zip 校验未通过，携带完整的校验报告。
+type checkError struct {
+	err    error
+	report *response.GomodChecked
+}
+
+func (e *checkError) Error() string {
+	return "不是合法的 go 模块：" + e.err.Error()
+}
+
+func (e *checkError) Unwrap() error {
+	return e.err
+}
+
+func (e *checkError) Details() any {
+	return e.report
+}
+
+func checkedReport(cf modzip.CheckedFiles) *response.GomodChecked {
+	ret := &response.GomodChecked{
+		Valid:   cf.Valid,
+		Omitted: make([]*response.GomodCheckedFile, 0, len(cf.Omitted)),
+		Invalid: make([]*response.GomodCheckedFile, 0, len(cf.Invalid)),
+	}
+	if ret.Valid == nil {
+		ret.Valid = []string{}
+	}
+	for _, fe := range cf.Omitted {
+		ret.Omitted = append(ret.Omitted, &response.GomodCheckedFile{Path: fe.Path, Reason: fe.Err.Error()})
+	}
+	for _, fe := range cf.Invalid {
+		ret.Invalid = append(ret.Invalid, &response.GomodCheckedFile{Path: fe.Path, Reason: fe.Err.Error()})
+	}
+	if cf.SizeError != nil {
+		ret.SizeError = cf.SizeError.Error()
+	}
+
+	return ret
+}
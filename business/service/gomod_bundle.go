@@ -0,0 +1,209 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/response"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// GomodBundle 离线依赖包，包含构建所需的全部模块文件，解压后可以直接作为
+// GOPROXY=file:///path/to/goproxy 使用，配合 GOFLAGS=-mod=mod GONOSUMDB 等在隔离网络中构建。
+type GomodBundle struct {
+	gmd    *Gomod
+	graph  *modGraph
+	report *response.GomodBundle
+}
+
+// BundleVersion 以仓库中的模块版本为根节点计算依赖。
+func (gmd *Gomod) BundleVersion(ctx context.Context, modpath, version string) (*GomodBundle, error) {
+	root := module.Version{Path: modpath, Version: version}
+	if err := module.Check(modpath, version); err != nil {
+		return nil, err
+	}
+	if _, err := gmd.readMod(root); err != nil {
+		return nil, errcode.FmtModuleNotFound.Fmt(modpath + "@" + version)
+	}
+
+	return gmd.bundle(gmd.loadGraph(ctx, root), modpath+"@"+version), nil
+}
+
+// BundleGomod 以上传的 go.mod 文件为根节点计算依赖。
+func (gmd *Gomod) BundleGomod(ctx context.Context, data []byte) (*GomodBundle, error) {
+	mf, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解析 go.mod 出错：%w", err)
+	}
+	if len(mf.Require) == 0 {
+		return nil, fmt.Errorf("go.mod 中没有任何依赖")
+	}
+
+	return gmd.bundle(gmd.loadGomodGraph(ctx, mf), modfile.ModulePath(data)), nil
+}
+
+func (gmd *Gomod) bundle(g *modGraph, root string) *GomodBundle {
+	ret := &response.GomodBundle{Root: root, GoMods: len(g.order)}
+	for _, mv := range g.buildList() {
+		target := g.resolve(mv)
+		mod := &response.GomodBundleModule{Path: mv.Path, Version: mv.Version}
+		if target != mv {
+			mod.Replace = strings.TrimSuffix(target.Path+"@"+target.Version, "@")
+		}
+		if _, missing := g.missing[mv]; !missing {
+			if mod.Hash = gmd.storedHash(target.Path, target.Version); mod.Hash == "" {
+				g.missing[mv] = "仓库中缺少 .zip 文件"
+			}
+		}
+		ret.Modules = append(ret.Modules, mod)
+	}
+	ret.Missing = g.missingList()
+
+	return &GomodBundle{gmd: gmd, graph: g, report: ret}
+}
+
+// Report 依赖清单：构建列表与缺失的依赖。
+func (b *GomodBundle) Report() *response.GomodBundle {
+	return b.report
+}
+
+// WriteTemp 将离线依赖包写入临时文件，打包出错时还没有向客户端发送任何内容，可以正常返回错误。
+//
+// 返回的文件已经定位到开头，调用方负责关闭并删除。
+func (b *GomodBundle) WriteTemp() (*os.File, error) {
+	temp, err := os.CreateTemp(os.TempDir(), "gomod_bundle_*.zip")
+	if err != nil {
+		return nil, err
+	}
+	err = b.WriteZip(temp)
+	if err == nil {
+		_, err = temp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return nil, err
+	}
+
+	return temp, nil
+}
+
+// WriteZip 将离线依赖包以 zip 格式写入 w，目录结构为：
+//
+//	goproxy/<转义的模块路径>/@v/{list,<版本>.info,<版本>.mod,<版本>.zip}
+//	go.sum       所有文件的哈希
+//	missing.txt  仓库中缺失的依赖
+//
+// 所有遍历到的版本都包含 .info 与 .mod 文件，只有构建列表中的版本才包含 .zip 文件。
+func (b *GomodBundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	g := b.graph
+	zips := make(map[module.Version]bool, len(g.selected))
+	for _, mv := range g.buildList() {
+		zips[g.resolve(mv)] = true
+	}
+
+	var sums []string
+	lists := make(map[string][]string, len(g.selected))
+	written := make(map[module.Version]bool, len(g.order))
+	for _, mv := range g.order {
+		target := g.resolve(mv)
+		if written[target] {
+			continue
+		}
+		written[target] = true
+		escpath, err := module.EscapePath(target.Path)
+		if err != nil {
+			return err
+		}
+		escver, err := module.EscapeVersion(target.Version)
+		if err != nil {
+			return err
+		}
+		dir := path.Join("goproxy", escpath, "@v")
+
+		data, err := b.gmd.readMod(target)
+		if err != nil {
+			return err
+		}
+		if err = writeZipFile(zw, path.Join(dir, escver+".mod"), bytes.NewReader(data), zip.Deflate); err != nil {
+			return err
+		}
+		modHash, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		})
+		if err != nil {
+			return err
+		}
+		sums = append(sums, target.Path+" "+target.Version+"/go.mod "+modHash)
+		if err = b.copyFile(zw, target, escver+".info", dir, zip.Deflate); err != nil {
+			return err
+		}
+		if !zips[target] {
+			continue
+		}
+		hash := b.gmd.storedHash(target.Path, target.Version)
+		if hash == "" {
+			continue
+		}
+		if err = b.copyFile(zw, target, escver+".zip", dir, zip.Store); err != nil {
+			return err
+		}
+		sums = append(sums, target.Path+" "+target.Version+" "+hash)
+		lists[dir] = append(lists[dir], target.Version)
+	}
+	for dir, versions := range lists {
+		semver.Sort(versions)
+		list := strings.NewReader(strings.Join(versions, "\n") + "\n")
+		if err := writeZipFile(zw, path.Join(dir, "list"), list, zip.Deflate); err != nil {
+			return err
+		}
+	}
+
+	sort.Strings(sums)
+	gosum := strings.NewReader(strings.Join(sums, "\n") + "\n")
+	if err := writeZipFile(zw, "go.sum", gosum, zip.Deflate); err != nil {
+		return err
+	}
+	missing := new(strings.Builder)
+	for _, m := range b.report.Missing {
+		missing.WriteString(m.Path + " " + m.Version + " " + m.Reason + "\n")
+	}
+	if err := writeZipFile(zw, "missing.txt", strings.NewReader(missing.String()), zip.Deflate); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// copyFile 将仓库中的文件复制到 zip 中，文件不存在时跳过。
+func (b *GomodBundle) copyFile(zw *zip.Writer, mv module.Version, name, dir string, method uint16) error {
+	file, err := b.gmd.Open(mv.Path, name)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	return writeZipFile(zw, path.Join(dir, name), file, method)
+}
+
+func writeZipFile(zw *zip.Writer, name string, r io.Reader, method uint16) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+
+	return err
+}
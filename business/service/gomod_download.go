@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+)
+
+// RecordDownload 记录一次通过代理下载模块 zip，匿名下载不记录。
+//
+// 同一用户使用同一 PAT 首次并发下载时可能因唯一索引冲突少记一次，不影响“是否有使用方”的判断，错误只记录日志。
+func (gmd *Gomod) RecordDownload(ctx context.Context, pub *request.GomodPublisher, modpath, version string) {
+	if pub.JobNumber == "" {
+		return
+	}
+	tbl := gmd.qry.GomodDownload
+	dao := tbl.WithContext(ctx)
+	ret, err := dao.Where(tbl.Path.Eq(modpath), tbl.Version.Eq(version), tbl.JobNumber.Eq(pub.JobNumber), tbl.TokenName.Eq(pub.TokenName)).
+		UpdateSimple(tbl.Times.Add(1), tbl.ClientIP.Value(pub.ClientIP), tbl.UpdatedAt.Value(time.Now()))
+	if err == nil && ret.RowsAffected == 0 {
+		err = dao.Create(&model.GomodDownload{
+			Path:      modpath,
+			Version:   version,
+			JobNumber: pub.JobNumber,
+			TokenName: pub.TokenName,
+			ClientIP:  pub.ClientIP,
+			Times:     1,
+		})
+	}
+	if err != nil {
+		gmd.log.Warn("记录模块下载出错", "path", modpath, "version", version, "error", err)
+	}
+}
+
+// deleteBlocked 查找待删除的模块版本的依赖方与下载记录，version 为空时查找整个模块（包括嵌套的子模块）。
+func (gmd *Gomod) deleteBlocked(ctx context.Context, modpath, version string) (*response.GomodDeleteBlocked, error) {
+	within := func(p string) bool { return p == modpath }
+	tbl := gmd.qry.GomodDownload
+	cond := []gen.Condition{tbl.Path.Eq(modpath)}
+	target := modpath + "@" + version
+	if version == "" {
+		target = modpath
+		within = func(p string) bool { return p == modpath || strings.HasPrefix(p, modpath+"/") }
+		cond = []gen.Condition{field.Or(tbl.Path.Eq(modpath), nestedPath(tbl.Path, modpath))}
+	} else {
+		cond = append(cond, tbl.Version.Eq(version))
+	}
+
+	deps, _, err := gmd.scanDependents(ctx, within, version)
+	if err != nil {
+		return nil, err
+	}
+	dao := tbl.WithContext(ctx).Where(cond...)
+	cnt, err := dao.Count()
+	if err != nil {
+		return nil, err
+	}
+	ret := &response.GomodDeleteBlocked{Target: target, Dependents: deps, Downloads: cnt}
+	if cnt != 0 {
+		if ret.Consumers, err = dao.Order(tbl.UpdatedAt.Desc()).Limit(100).Find(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+// deleteError 待删除的模块版本仍有依赖方或下载记录，拒绝删除。
+type deleteError struct {
+	report *response.GomodDeleteBlocked
+}
+
+func (e *deleteError) Error() string {
+	var msgs []string
+	if n := len(e.report.Dependents); n != 0 {
+		msgs = append(msgs, strconv.Itoa(n)+" 个模块版本依赖")
+	}
+	if n := e.report.Downloads; n != 0 {
+		msgs = append(msgs, strconv.FormatInt(n, 10)+" 条下载记录")
+	}
+
+	return e.report.Target + " 仍有 " + strings.Join(msgs, "、") + "，确认删除需要强制删除并填写原因"
+}
+
+func (e *deleteError) Details() any {
+	return e.report
+}
+
+func (e *deleteError) StatusCode() int {
+	return http.StatusConflict
+}
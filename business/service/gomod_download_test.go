@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/xgfone/ship/v5"
+)
+
+func TestGomodDelete(t *testing.T) {
+	ctx := context.Background()
+	gmd, _, dir := newGomod(t)
+	pub := &request.GomodPublisher{JobNumber: "1"}
+	publish := func(modpath, gomod string) {
+		file := createZip(t, modpath, "v1.0.0", map[string]string{"go.mod": gomod})
+		if _, err := gmd.Upload(ctx, pub, file, modpath, "v1.0.0", false); err != nil {
+			t.Fatalf("Upload(%s): %v", modpath, err)
+		}
+	}
+	publish("example.com/lib", "module example.com/lib\n")
+	publish("example.com/lib/sub", "module example.com/lib/sub\n")
+	publish("example.com/app", "module example.com/app\n\nrequire example.com/lib/sub v1.0.0\n")
+	gmd.RecordDownload(ctx, &request.GomodPublisher{JobNumber: "2"}, "example.com/lib", "v1.0.0")
+	gmd.RecordDownload(ctx, &request.GomodPublisher{JobNumber: "2"}, "example.com/lib_x", "v1.0.0")
+
+	// blocked 断言删除被拒绝：返回 409，并通过 Details 给出依赖方与下载记录。
+	blocked := func(req *request.GomodDelete) *response.GomodDeleteBlocked {
+		t.Helper()
+		err := gmd.Delete(ctx, "1", req)
+		var dt errcode.Detailer
+		var sc errcode.StatusCoder
+		if !errors.As(err, &dt) || !errors.As(err, &sc) || sc.StatusCode() != http.StatusConflict {
+			t.Fatalf("Delete(%+v) = %v, want 409 with details", req, err)
+		}
+		report, _ := dt.Details().(*response.GomodDeleteBlocked)
+		if report == nil {
+			t.Fatalf("Delete(%+v) details = %T", req, dt.Details())
+		}
+		return report
+	}
+
+	report := blocked(&request.GomodDelete{Path: "example.com/lib", Version: "v1.0.0"})
+	if report.Target != "example.com/lib@v1.0.0" || len(report.Dependents) != 0 || report.Downloads != 1 || len(report.Consumers) != 1 {
+		t.Errorf("blocked version = %+v", report)
+	}
+
+	// 删除整个模块需要确认，并且包括嵌套的子模块，但不包括 example.com/lib_x。
+	err := gmd.Delete(ctx, "1", &request.GomodDelete{Path: "example.com/lib"})
+	var he ship.HTTPServerError
+	if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Errorf("Delete without confirm = %v, want 400", err)
+	}
+	whole := &request.GomodDelete{Path: "example.com/lib", Confirm: "example.com/lib"}
+	report = blocked(whole)
+	if len(report.Dependents) != 1 || report.Dependents[0].Path != "example.com/app" || report.Dependents[0].Module != "example.com/lib/sub" || report.Downloads != 1 {
+		t.Errorf("blocked module = %+v", report)
+	}
+
+	// 强制删除必须填写原因。
+	whole.Force = true
+	if err = gmd.Delete(ctx, "1", whole); !errors.Is(err, errcode.ErrDeleteReason) {
+		t.Errorf("Delete(force) without reason = %v, want ErrDeleteReason", err)
+	}
+	whole.Reason = "  "
+	if err = gmd.Delete(ctx, "1", whole); !errors.Is(err, errcode.ErrDeleteReason) {
+		t.Errorf("Delete(force) with blank reason = %v, want ErrDeleteReason", err)
+	}
+	whole.Reason = "停止维护"
+	if err = gmd.Delete(ctx, "1", whole); err != nil {
+		t.Fatalf("Delete(force): %v", err)
+	}
+	for _, modpath := range []string{"example.com/lib", "example.com/lib/sub"} {
+		if _, err = os.Stat(filepath.Join(dir, modpath, "@v", "v1.0.0.zip")); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after delete: %v", modpath, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "example.com/app", "@v", "v1.0.0.zip")); err != nil {
+		t.Errorf("example.com/app should not be deleted: %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/response"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// modGraph 根据仓库中已存储的 .mod 文件构建的模块依赖图。
+//
+// 与 go 命令的最小版本选择（MVS）一致：遍历从根节点可达的所有模块版本，每个模块路径选择
+// 可达的最高版本组成构建列表。遍历不做图裁剪（go 1.17 的 module graph pruning），因此得到的
+// 是 go 命令可能需要的 .mod 文件的超集。只处理根 go.mod 中的 replace 指令，不处理 exclude 指令。
+type modGraph struct {
+	gmd      *Gomod
+	main     string                              // 根 go.mod 的模块路径，根节点是仓库中的版本时为空
+	replace  map[module.Version]module.Version   // replace 指令，Version 为空表示替换所有版本
+	reqs     map[module.Version][]module.Version // 已读取 .mod 文件的版本及其依赖
+	order    []module.Version                    // 已读取 .mod 文件的版本，按遍历顺序
+	missing  map[module.Version]string           // 无法读取 .mod 文件的版本及原因
+	selected map[string]string                   // 模块路径 -> MVS 选择的版本
+}
+
+// loadGraph 从 root 开始遍历依赖，root 是仓库中的模块版本。
+func (gmd *Gomod) loadGraph(ctx context.Context, root module.Version) *modGraph {
+	g := gmd.newGraph("", nil)
+	g.walk(ctx, []module.Version{root})
+
+	return g
+}
+
+// loadGomodGraph 以 go.mod 文件为根节点遍历依赖，go.mod 中的 replace 指令会被应用。
+func (gmd *Gomod) loadGomodGraph(ctx context.Context, mf *modfile.File) *modGraph {
+	replace := make(map[module.Version]module.Version, len(mf.Replace))
+	for _, rep := range mf.Replace {
+		replace[rep.Old] = rep.New
+	}
+	var main string
+	if mf.Module != nil {
+		main = mf.Module.Mod.Path
+	}
+	g := gmd.newGraph(main, replace)
+	roots := make([]module.Version, 0, len(mf.Require))
+	for _, req := range mf.Require {
+		roots = append(roots, req.Mod)
+	}
+	g.walk(ctx, roots)
+
+	return g
+}
+
+func (gmd *Gomod) newGraph(main string, replace map[module.Version]module.Version) *modGraph {
+	return &modGraph{
+		gmd:      gmd,
+		main:     main,
+		replace:  replace,
+		reqs:     make(map[module.Version][]module.Version, 64),
+		missing:  make(map[module.Version]string, 8),
+		selected: make(map[string]string, 64),
+	}
+}
+
+func (g *modGraph) walk(ctx context.Context, roots []module.Version) {
+	queue := append([]module.Version(nil), roots...)
+	seen := make(map[module.Version]bool, 64)
+	for len(queue) != 0 && ctx.Err() == nil {
+		mv := queue[0]
+		queue = queue[1:]
+		if seen[mv] || mv.Path == g.main {
+			continue
+		}
+		seen[mv] = true
+		if semver.Compare(mv.Version, g.selected[mv.Path]) > 0 {
+			g.selected[mv.Path] = mv.Version
+		}
+
+		target := g.resolve(mv)
+		if target.Version == "" {
+			g.missing[mv] = "替换为本地目录 " + target.Path + "，需要自行提供"
+			continue
+		}
+		reqs, err := g.gmd.readRequires(target)
+		if err != nil {
+			g.missing[mv] = "仓库中缺少 .mod 文件"
+			continue
+		}
+		g.reqs[mv] = reqs
+		g.order = append(g.order, mv)
+		queue = append(queue, reqs...)
+	}
+}
+
+// resolve 应用 replace 指令，替换为本地目录时返回的 Version 为空。
+func (g *modGraph) resolve(mv module.Version) module.Version {
+	if rep, ok := g.replace[mv]; ok {
+		return rep
+	}
+	if rep, ok := g.replace[module.Version{Path: mv.Path}]; ok {
+		return rep
+	}
+
+	return mv
+}
+
+// buildList MVS 选择的构建列表，按模块路径排序。
+func (g *modGraph) buildList() []module.Version {
+	list := make([]module.Version, 0, len(g.selected))
+	for modpath, version := range g.selected {
+		list = append(list, module.Version{Path: modpath, Version: version})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+
+	return list
+}
+
+// missingList 无法读取 .mod 文件的版本，按模块路径与版本排序。
+func (g *modGraph) missingList() []*response.GomodBundleMissing {
+	ret := make([]*response.GomodBundleMissing, 0, len(g.missing))
+	for mv, reason := range g.missing {
+		ret = append(ret, &response.GomodBundleMissing{Path: mv.Path, Version: mv.Version, Reason: reason})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Path != ret[j].Path {
+			return ret[i].Path < ret[j].Path
+		}
+		return semver.Compare(ret[i].Version, ret[j].Version) < 0
+	})
+
+	return ret
+}
+
+// Graph 仓库中模块版本的依赖图：直接依赖、MVS 选择的构建列表以及所有遍历到的依赖关系。
+func (gmd *Gomod) Graph(ctx context.Context, modpath, version string) (*response.GomodGraph, error) {
+	root := module.Version{Path: modpath, Version: version}
+	if err := module.Check(modpath, version); err != nil {
+		return nil, err
+	}
+	data, err := gmd.readMod(root)
+	if err != nil {
+		return nil, errcode.FmtModuleNotFound.Fmt(modpath + "@" + version)
+	}
+	mf, err := modfile.ParseLax(modpath+"@"+version+"/go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g := gmd.loadGraph(ctx, root)
+	ret := &response.GomodGraph{Root: modpath + "@" + version}
+	direct := make(map[string]bool, len(mf.Require))
+	for _, req := range mf.Require {
+		direct[req.Mod.Path] = true
+		ret.Requires = append(ret.Requires, &response.GomodGraphRequire{
+			Path:     req.Mod.Path,
+			Version:  req.Mod.Version,
+			Selected: g.selected[req.Mod.Path],
+			Indirect: req.Indirect,
+		})
+	}
+	for _, mv := range g.buildList() {
+		if mv.Path == modpath {
+			continue
+		}
+		ret.Modules = append(ret.Modules, &response.GomodGraphModule{Path: mv.Path, Version: mv.Version, Direct: direct[mv.Path]})
+	}
+	for _, mv := range g.order {
+		for _, req := range g.reqs[mv] {
+			ret.Edges = append(ret.Edges, &response.GomodGraphEdge{From: mv.String(), To: req.String()})
+		}
+	}
+	ret.Missing = g.missingList()
+
+	return ret, nil
+}
+
+// Dependents 扫描仓库中所有的 .mod 文件，查找直接依赖了 modpath 的模块版本，version 不为空时
+// 只查找依赖此版本的。每次查询都会重新扫描，结果总是与仓库一致。
+func (gmd *Gomod) Dependents(ctx context.Context, modpath, version string) (*response.GomodDependents, error) {
+	if err := module.CheckPath(modpath); err != nil {
+		return nil, err
+	}
+	if version != "" {
+		if err := module.Check(modpath, version); err != nil {
+			return nil, err
+		}
+	}
+
+	within := func(p string) bool { return p == modpath }
+	deps, scanned, err := gmd.scanDependents(ctx, within, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.GomodDependents{Path: modpath, Scanned: scanned, Dependents: deps}, nil
+}
+
+// scanDependents 扫描仓库中所有的 .mod 文件，查找依赖了 within 范围内模块的版本，范围内的模块之间的依赖不计入。
+// version 不为空时只查找依赖此版本的，返回依赖方与扫描的 .mod 文件个数。
+func (gmd *Gomod) scanDependents(ctx context.Context, within func(modpath string) bool, version string) ([]*response.GomodDependent, int, error) {
+	var scanned int
+	var deps []*response.GomodDependent
+	latest := make(map[string]string, 64)
+	root := filepath.Clean(gmd.dir)
+	err := filepath.WalkDir(root, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		mv, ok := storedMod(root, fpath, d)
+		if !ok {
+			return nil
+		}
+		scanned++
+		if semver.Compare(mv.Version, latest[mv.Path]) > 0 {
+			latest[mv.Path] = mv.Version
+		}
+		if within(mv.Path) {
+			return nil
+		}
+		data, err := os.ReadFile(fpath)
+		if err != nil {
+			return nil
+		}
+		mf, err := modfile.ParseLax(mv.String()+"/go.mod", data, nil)
+		if err != nil {
+			return nil
+		}
+		for _, req := range mf.Require {
+			if !within(req.Mod.Path) || (version != "" && req.Mod.Version != version) {
+				continue
+			}
+			deps = append(deps, &response.GomodDependent{
+				Path:     mv.Path,
+				Version:  mv.Version,
+				Module:   req.Mod.Path,
+				Requires: req.Mod.Version,
+				Indirect: req.Indirect,
+			})
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, 0, err
+	}
+	for _, dep := range deps {
+		dep.Latest = latest[dep.Path] == dep.Version
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		a, b := deps[i], deps[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return semver.Compare(a.Version, b.Version) < 0
+	})
+
+	return deps, scanned, nil
+}
+
+// storedMod 根据仓库中的文件路径识别 .mod 文件所属的模块版本：<转义的模块路径>/@v/<转义的版本>.mod。
+func storedMod(root, fpath string, d fs.DirEntry) (module.Version, bool) {
+	escver, ok := strings.CutSuffix(d.Name(), ".mod")
+	if !ok || d.IsDir() {
+		return module.Version{}, false
+	}
+	dir := filepath.Dir(fpath)
+	if filepath.Base(dir) != "@v" {
+		return module.Version{}, false
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(dir))
+	if err != nil {
+		return module.Version{}, false
+	}
+	modpath, err := module.UnescapePath(filepath.ToSlash(rel))
+	if err != nil {
+		return module.Version{}, false
+	}
+	version, err := module.UnescapeVersion(escver)
+	if err != nil || module.Check(modpath, version) != nil {
+		return module.Version{}, false
+	}
+
+	return module.Version{Path: modpath, Version: version}, true
+}
+
+// readRequires 读取仓库中 .mod 文件的依赖。
+func (gmd *Gomod) readRequires(mv module.Version) ([]module.Version, error) {
+	data, err := gmd.readMod(mv)
+	if err != nil {
+		return nil, err
+	}
+	mf, err := modfile.ParseLax(mv.Path+"@"+mv.Version+"/go.mod", data, nil)
+	if err != nil {
+		return nil, err
+	}
+	reqs := make([]module.Version, 0, len(mf.Require))
+	for _, req := range mf.Require {
+		reqs = append(reqs, req.Mod)
+	}
+
+	return reqs, nil
+}
+
+// readMod 读取仓库中的 .mod 文件。
+func (gmd *Gomod) readMod(mv module.Version) ([]byte, error) {
+	escver, err := module.EscapeVersion(mv.Version)
+	if err != nil {
+		return nil, err
+	}
+	file, err := gmd.Open(mv.Path, escver+".mod")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, 16<<20))
+}
@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfcfw/goproxy/contract/response"
+)
+
+func TestGomodGraph(t *testing.T) {
+	ctx := context.Background()
+	gmd, _, dir := newGomod(t)
+	// store 直接在仓库中写入 .mod 与 .ziphash 文件，requires 的格式为 path@version。
+	store := func(modpath, version string, requires ...string) {
+		var sb strings.Builder
+		sb.WriteString("module " + modpath + "\n")
+		for _, req := range requires {
+			p, v, _ := strings.Cut(req, "@")
+			sb.WriteString("require " + p + " " + v + "\n")
+		}
+		base := filepath.Join(dir, modpath, "@v", version)
+		_ = os.MkdirAll(filepath.Dir(base), 0o755)
+		if err := os.WriteFile(base+".mod", []byte(sb.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(base+".ziphash", []byte("h1:"+modpath+"@"+version), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store("example.com/a", "v1.0.0", "example.com/b@v1.1.0", "example.com/c@v1.0.0")
+	store("example.com/b", "v1.0.0", "example.com/c@v1.0.0")
+	store("example.com/b", "v1.1.0", "example.com/c@v1.2.0", "example.com/d@v1.0.0") // d 缺少 .mod 文件
+	store("example.com/c", "v1.0.0")
+	store("example.com/c", "v1.2.0")
+	store("example.com/e", "v1.0.0")
+
+	graph, err := gmd.Graph(ctx, "example.com/a", "v1.0.0")
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	var modules []string
+	for _, mod := range graph.Modules {
+		modules = append(modules, mod.Path+"@"+mod.Version)
+	}
+	// c 选择了 b 要求的更高版本，而不是根节点直接要求的 v1.0.0。
+	if got := strings.Join(modules, " "); got != "example.com/b@v1.1.0 example.com/c@v1.2.0 example.com/d@v1.0.0" {
+		t.Errorf("Graph modules = %s", got)
+	}
+	if req := graph.Requires[1]; req.Path != "example.com/c" || req.Version != "v1.0.0" || req.Selected != "v1.2.0" {
+		t.Errorf("Graph requires[1] = %+v, want c v1.0.0 selected v1.2.0", req)
+	}
+	if len(graph.Missing) != 1 || graph.Missing[0].Path != "example.com/d" {
+		t.Errorf("Graph missing = %+v, want example.com/d", graph.Missing)
+	}
+	if len(graph.Edges) != 4 {
+		t.Errorf("Graph edges = %d, want 4", len(graph.Edges))
+	}
+	if _, err = gmd.Graph(ctx, "example.com/d", "v1.0.0"); err == nil {
+		t.Errorf("Graph of a version without .mod should fail")
+	}
+
+	// 根 go.mod 中的 replace 指令：替换为其它模块时读取替换后的 .mod 文件，替换为本地目录时不再继续遍历。
+	gomod := "module example.com/main\n\nrequire example.com/a v1.0.0\n\n" +
+		"replace example.com/c => example.com/e v1.0.0\n\nreplace example.com/b v1.1.0 => ../b\n"
+	bdl, err := gmd.BundleGomod(ctx, []byte(gomod))
+	if err != nil {
+		t.Fatalf("BundleGomod: %v", err)
+	}
+	report := bdl.Report()
+	want := map[string]*response.GomodBundleModule{
+		"example.com/a": {Path: "example.com/a", Version: "v1.0.0", Hash: "h1:example.com/a@v1.0.0"},
+		"example.com/b": {Path: "example.com/b", Version: "v1.1.0", Replace: "../b"},
+		"example.com/c": {Path: "example.com/c", Version: "v1.0.0", Replace: "example.com/e@v1.0.0", Hash: "h1:example.com/e@v1.0.0"},
+	}
+	if len(report.Modules) != len(want) {
+		t.Errorf("BundleGomod modules = %d, want %d", len(report.Modules), len(want))
+	}
+	for _, mod := range report.Modules {
+		if w := want[mod.Path]; w == nil || *w != *mod {
+			t.Errorf("BundleGomod module = %+v, want %+v", mod, w)
+		}
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Path != "example.com/b" || report.GoMods != 2 {
+		t.Errorf("BundleGomod missing = %+v, go_mods = %d", report.Missing, report.GoMods)
+	}
+
+	// 只有依赖方的最新版本标记为 Latest。
+	deps, err := gmd.Dependents(ctx, "example.com/c", "")
+	if err != nil {
+		t.Fatalf("Dependents: %v", err)
+	}
+	var got []string
+	for _, dep := range deps.Dependents {
+		s := dep.Path + "@" + dep.Version + "->" + dep.Requires
+		if dep.Latest {
+			s += "(latest)"
+		}
+		got = append(got, s)
+	}
+	if s := strings.Join(got, " "); s != "example.com/a@v1.0.0->v1.0.0(latest) example.com/b@v1.0.0->v1.0.0 example.com/b@v1.1.0->v1.2.0(latest)" {
+		t.Errorf("Dependents = %s", s)
+	}
+	if deps.Scanned != 6 {
+		t.Errorf("Dependents scanned = %d, want 6", deps.Scanned)
+	}
+	if deps, _ = gmd.Dependents(ctx, "example.com/c", "v1.2.0"); len(deps.Dependents) != 1 || deps.Dependents[0].Path != "example.com/b" {
+		t.Errorf("Dependents(v1.2.0) = %+v", deps.Dependents)
+	}
+}
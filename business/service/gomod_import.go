@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// GomodImportEntry 待导入的模块版本。
+type GomodImportEntry struct {
+	Path     string // 模块路径
+	Version  string // 版本号
+	Zip      string // zip 文件
+	Info     string // .info 文件，不存在时为空
+	Hash     string // 期望的 h1 哈希，来自 .ziphash 或 go.sum，为空说明无法校验
+	HashFrom string // 期望哈希的来源文件
+}
+
+// ScanImport 扫描其它代理的磁盘目录，支持两种布局：
+//
+//   - GOPROXY 协议布局，例如 $GOMODCACHE/cache/download：<转义的模块路径>/@v/<版本>.zip，
+//     同目录下的 .ziphash、.info 一起读取；
+//   - Athens 磁盘存储布局：<模块路径>/<版本>/source.zip，同目录下的 <版本>.info 一起读取。
+//
+// sums 为 go.sum 中的哈希（见 ReadGoSum），没有 .ziphash 文件时使用。
+func ScanImport(root string, sums map[module.Version]string) ([]*GomodImportEntry, error) {
+	var ents []*GomodImportEntry
+	err := filepath.WalkDir(root, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "sumdb" && filepath.Dir(fpath) == root {
+				return filepath.SkipDir // $GOMODCACHE/cache/download/sumdb 是校验和数据库的缓存
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, fpath)
+		if err != nil {
+			return err
+		}
+		if ent := importEntry(filepath.ToSlash(rel)); ent != nil {
+			ent.Zip = fpath
+			ents = append(ents, ent)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ent := range ents {
+		dir := filepath.Dir(ent.Zip)
+		if strings.HasSuffix(ent.Zip, ".zip") && filepath.Base(ent.Zip) != "source.zip" {
+			base := strings.TrimSuffix(ent.Zip, ".zip")
+			if raw, err := os.ReadFile(base + ".ziphash"); err == nil {
+				ent.Hash, ent.HashFrom = strings.TrimSpace(string(raw)), base+".ziphash"
+			}
+			ent.Info = existFile(base + ".info")
+		} else {
+			ent.Info = existFile(filepath.Join(dir, ent.Version+".info"))
+		}
+		if ent.Hash == "" {
+			if hash := sums[module.Version{Path: ent.Path, Version: ent.Version}]; hash != "" {
+				ent.Hash, ent.HashFrom = hash, "go.sum"
+			}
+		}
+	}
+	sort.Slice(ents, func(i, j int) bool {
+		if ents[i].Path != ents[j].Path {
+			return ents[i].Path < ents[j].Path
+		}
+		return semver.Compare(ents[i].Version, ents[j].Version) < 0
+	})
+
+	return ents, nil
+}
+
+// importEntry 根据相对路径识别模块路径与版本号，不是模块 zip 时返回 nil。
+func importEntry(rel string) *GomodImportEntry {
+	if escpath, file, ok := strings.Cut(rel, "/@v/"); ok {
+		escver, ok := strings.CutSuffix(file, ".zip")
+		if !ok || strings.Contains(escver, "/") {
+			return nil
+		}
+		modpath, err := module.UnescapePath(escpath)
+		if err != nil {
+			return nil
+		}
+		version, err := module.UnescapeVersion(escver)
+		if err != nil || module.Check(modpath, version) != nil {
+			return nil
+		}
+		return &GomodImportEntry{Path: modpath, Version: version}
+	}
+
+	dir, ok := strings.CutSuffix(rel, "/source.zip")
+	if !ok {
+		return nil
+	}
+	rawpath, version := path.Dir(dir), path.Base(dir)
+	modpath, err := module.UnescapePath(rawpath)
+	if err != nil {
+		modpath = rawpath // Athens 部分版本不转义模块路径
+	}
+	if module.Check(modpath, version) != nil {
+		return nil
+	}
+
+	return &GomodImportEntry{Path: modpath, Version: version}
+}
+
+func existFile(name string) string {
+	if inf, err := os.Stat(name); err == nil && inf.Mode().IsRegular() {
+		return name
+	}
+
+	return ""
+}
+
+// ReadGoSum 读取 go.sum 文件中模块 zip 的哈希，忽略 /go.mod 行。
+func ReadGoSum(files ...string) (map[module.Version]string, error) {
+	sums := make(map[module.Version]string, 64)
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		sc := bufio.NewScanner(file)
+		for sc.Scan() {
+			fields := strings.Fields(sc.Text())
+			if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+				continue
+			}
+			sums[module.Version{Path: fields[0], Version: fields[1]}] = fields[2]
+		}
+		err = sc.Err()
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 出错：%w", name, err)
+		}
+	}
+
+	return sums, nil
+}
+
+// Import 校验并导入一个模块版本，可以重复执行：仓库中已存在哈希相同的版本时跳过，
+// 哈希不同时视为冲突不会覆盖，除非 force。
+//
+// 没有期望哈希（.ziphash 或 go.sum）的版本只有 unverified 时才会导入。版本时间与来源沿用原 .info 文件。
+func (gmd *Gomod) Import(ctx context.Context, pub *request.GomodPublisher, ent *GomodImportEntry, unverified, force, dryRun bool) *response.GomodImported {
+	ret := &response.GomodImported{Path: ent.Path, Version: ent.Version}
+	if inf, err := os.Stat(ent.Zip); err == nil {
+		ret.Size = inf.Size()
+	}
+	hash, err := dirhash.HashZip(ent.Zip, dirhash.DefaultHash)
+	if err != nil {
+		ret.Status, ret.Reason = response.GomodImportFailed, "读取 zip 出错："+err.Error()
+		return ret
+	}
+	ret.Hash = hash
+	if ent.Hash == "" && !unverified {
+		ret.Status, ret.Reason = response.GomodImportFailed, "缺少 .ziphash 或 go.sum 记录，无法校验"
+		return ret
+	}
+	if ent.Hash != "" && ent.Hash != hash {
+		ret.Status, ret.Reason = response.GomodImportFailed, fmt.Sprintf("哈希与 %s 不一致：%s", ent.HashFrom, ent.Hash)
+		return ret
+	}
+
+	if stored := gmd.storedHash(ent.Path, ent.Version); stored == hash {
+		ret.Status = response.GomodImportExists
+		return ret
+	} else if stored != "" && !force {
+		ret.Status, ret.Reason = response.GomodImportConflict, "仓库中已存在内容不同的同名版本："+stored
+		return ret
+	}
+
+	vpub := *pub
+	if minf := readInfo(ent.Info); minf != nil {
+		vpub.Time = minf.Time
+		if org := minf.Origin; org != nil {
+			vpub.Repository, vpub.CommitSHA, vpub.Ref = org.URL, org.Hash, org.Ref
+		}
+	}
+	file, err := os.Open(ent.Zip)
+	if err != nil {
+		ret.Status, ret.Reason = response.GomodImportFailed, err.Error()
+		return ret
+	}
+	defer file.Close()
+
+	if _, err = gmd.upload(ctx, &vpub, file, ent.Path, ent.Version, dryRun, force); err != nil {
+		ret.Status, ret.Reason = response.GomodImportFailed, err.Error()
+		return ret
+	}
+	ret.Status = response.GomodImportImported
+	if dryRun {
+		ret.Status = response.GomodImportValid
+	}
+
+	return ret
+}
+
+// storedHash 仓库中已存在版本的哈希，不存在时返回空。
+func (gmd *Gomod) storedHash(modpath, version string) string {
+	escver, err := module.EscapeVersion(version)
+	if err != nil {
+		return ""
+	}
+	file, err := gmd.Open(modpath, escver+".ziphash")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	sc.Scan()
+
+	return strings.TrimSpace(sc.Text())
+}
+
+func readInfo(name string) *response.GomodInfo {
+	if name == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	minf := new(response.GomodInfo)
+	if json.Unmarshal(raw, minf) != nil {
+		return nil
+	}
+
+	return minf
+}
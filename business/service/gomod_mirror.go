@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/library/gitx"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// GitMirror 模块路径与本地 git 仓库的对应关系。
+type GitMirror struct {
+	Module string // 仓库根目录对应的模块路径
+	Dir    string // 本地 git 仓库目录
+}
+
+// GomodMirror 直接从本地 git 镜像提供模块。
+//
+// 版本列表来自仓库标签，分支、提交哈希等查询解析为伪版本号，.mod 与 .zip 在首次下载时生成并
+// 缓存到模块存储中，之后与上传的模块一样直接读取文件。回收站中的版本视为不存在，不会重新生成，
+// 回收站清理之后才会重新从 git 镜像提供。
+type GomodMirror struct {
+	mirrors []GitMirror
+	gmd     *Gomod
+	log     *slog.Logger
+	locks   [64]sync.Mutex // 按 path@version 分片加锁，避免并发生成同一个版本
+}
+
+func NewGomodMirror(mirrors []GitMirror, gmd *Gomod, log *slog.Logger) *GomodMirror {
+	return &GomodMirror{
+		mirrors: mirrors,
+		gmd:     gmd,
+		log:     log,
+	}
+}
+
+// Match 模块是否由 git 镜像提供。
+func (gm *GomodMirror) Match(modpath string) bool {
+	return gm.match(modpath) != nil
+}
+
+// List 模块的版本列表：仓库中符合该模块标签前缀的版本以及已经上传的版本，不包含伪版本号与回收站中的版本。
+func (gm *GomodMirror) List(ctx context.Context, modpath string) ([]string, error) {
+	trashed, err := gm.trashed(ctx, modpath)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := gm.open(ctx, modpath)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := mm.repo.Tags(ctx, mm.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]struct{}, len(tags))
+	var versions []string
+	add := func(version string) {
+		if _, exists := index[version]; exists || module.IsPseudoVersion(version) {
+			return
+		}
+		index[version] = struct{}{}
+		versions = append(versions, version)
+	}
+	for _, tag := range tags {
+		version := strings.TrimPrefix(tag, mm.prefix)
+		if module.CanonicalVersion(version) == version && module.Check(modpath, version) == nil && !trashed(version) {
+			add(version)
+		}
+	}
+	for _, version := range gm.gmd.storedVersions(modpath) {
+		add(version)
+	}
+	semver.Sort(versions)
+
+	return versions, nil
+}
+
+// Query 解析版本查询对应的版本信息，query 可以是版本号、分支、标签或者提交哈希。
+func (gm *GomodMirror) Query(ctx context.Context, modpath, query string) (*response.GomodInfo, error) {
+	trashed, err := gm.trashed(ctx, modpath)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := gm.open(ctx, modpath)
+	if err != nil {
+		return nil, err
+	}
+	rev, _, err := mm.resolve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if trashed(rev.Version) {
+		return nil, errcode.FmtModuleNotFound.Fmt(modpath + "@" + query)
+	}
+
+	return mm.info(rev), nil
+}
+
+// Latest 模块的最新版本：优先使用最新的正式版本，其次是预发布版本，没有任何版本时使用默认分支的伪版本号。
+func (gm *GomodMirror) Latest(ctx context.Context, modpath string) (*response.GomodInfo, error) {
+	versions, err := gm.List(ctx, modpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest string
+	for _, version := range versions {
+		if semver.Prerelease(version) == "" {
+			latest = version
+		}
+	}
+	if latest == "" && len(versions) != 0 {
+		latest = versions[len(versions)-1]
+	}
+	if latest == "" {
+		return gm.Query(ctx, modpath, "HEAD")
+	}
+	if info := gm.storedInfo(modpath, latest); info != nil {
+		return info, nil
+	}
+
+	return gm.Query(ctx, modpath, latest)
+}
+
+// Download 确保该版本的 .info、.mod、.zip 已缓存到模块存储中，不存在时从 git 镜像生成。
+//
+// 缓存的版本不是某个人发布的，发布记录中只有 git 镜像的信息，不记录触发下载的用户。
+func (gm *GomodMirror) Download(ctx context.Context, modpath, version string) error {
+	if module.CanonicalVersion(version) != version {
+		return errcode.FmtModuleNotFound.Fmt(modpath + "@" + version)
+	}
+	mu := gm.lock(modpath + "@" + version)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if gm.cached(modpath, version) {
+		return nil
+	}
+	trashed, err := gm.trashed(ctx, modpath)
+	if err != nil {
+		return err
+	}
+	if trashed(version) {
+		return errcode.FmtModuleNotFound.Fmt(modpath + "@" + version)
+	}
+	mm, err := gm.open(ctx, modpath)
+	if err != nil {
+		return err
+	}
+	rev, subdir, err := mm.resolve(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(os.TempDir(), "gomod_mirror_*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	mdv := module.Version{Path: modpath, Version: version}
+	if err = createFromRepo(ctx, temp, mm.repo, mdv, rev.Hash, subdir); err == nil {
+		_, err = temp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return err
+	}
+
+	mirrorPub := &request.GomodPublisher{
+		Repository: mm.mirror.Dir,
+		CommitSHA:  rev.Hash,
+		Ref:        rev.Ref,
+		Time:       rev.Time,
+	}
+	if _, err = gm.gmd.Upload(ctx, mirrorPub, temp, modpath, version, false); err != nil {
+		return err
+	}
+	gm.log.Info("已从 git 镜像缓存模块", "path", modpath, "version", version, "commit", rev.Hash)
+
+	return nil
+}
+
+// match 查找模块所属的 git 镜像，多个镜像匹配时使用模块路径最长的一个。
+func (gm *GomodMirror) match(modpath string) *GitMirror {
+	var ret *GitMirror
+	for i := range gm.mirrors {
+		m := &gm.mirrors[i]
+		if modpath != m.Module && !strings.HasPrefix(modpath, m.Module+"/") {
+			continue
+		}
+		if ret == nil || len(m.Module) > len(ret.Module) {
+			ret = m
+		}
+	}
+
+	return ret
+}
+
+func (gm *GomodMirror) open(ctx context.Context, modpath string) (*mirrorModule, error) {
+	m := gm.match(modpath)
+	if m == nil {
+		return nil, errcode.FmtModuleNotFound.Fmt(modpath)
+	}
+	if err := module.CheckPath(modpath); err != nil {
+		return nil, err
+	}
+	repo, err := gitx.Open(ctx, m.Dir)
+	if err != nil {
+		return nil, err
+	}
+	subdir := strings.TrimPrefix(strings.TrimPrefix(modpath, m.Module), "/")
+
+	return &mirrorModule{
+		mirror:  m,
+		repo:    repo,
+		modpath: modpath,
+		subdir:  subdir,
+		prefix:  gitx.TagPrefix(subdir, modpath),
+	}, nil
+}
+
+// trashed 查询模块在回收站中的版本，返回判断版本是否已被删除的函数。
+// 整个模块（或者所在的父模块目录）被删除时所有版本都视为已删除。
+func (gm *GomodMirror) trashed(ctx context.Context, modpath string) (func(version string) bool, error) {
+	paths := []string{modpath}
+	for dir := modpath; strings.Contains(dir, "/"); {
+		dir = path.Dir(dir)
+		paths = append(paths, dir)
+	}
+	tbl := gm.gmd.qry.GomodTrash
+	recs, err := tbl.WithContext(ctx).
+		Select(tbl.Path, tbl.Version).
+		Where(tbl.Path.In(paths...)).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]struct{}, len(recs))
+	for _, rec := range recs {
+		if rec.Version == "" {
+			return func(string) bool { return true }, nil
+		}
+		if rec.Path == modpath {
+			versions[rec.Version] = struct{}{}
+		}
+	}
+
+	return func(version string) bool {
+		_, exists := versions[version]
+		return exists
+	}, nil
+}
+
+func (gm *GomodMirror) lock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return &gm.locks[h.Sum32()%uint32(len(gm.locks))]
+}
+
+// cached 该版本的文件是否都已经在模块存储中。
+func (gm *GomodMirror) cached(modpath, version string) bool {
+	escver, err := module.EscapeVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, ext := range []string{".info", ".mod", ".zip"} {
+		file, err := gm.gmd.Open(modpath, escver+ext)
+		if err != nil {
+			return false
+		}
+		_ = file.Close()
+	}
+
+	return true
+}
+
+// storedInfo 读取模块存储中的 .info 文件，不存在时返回 nil。
+func (gm *GomodMirror) storedInfo(modpath, version string) *response.GomodInfo {
+	escver, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil
+	}
+	file, err := gm.gmd.Open(modpath, escver+".info")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	info := new(response.GomodInfo)
+	if err = json.NewDecoder(file).Decode(info); err != nil {
+		return nil
+	}
+
+	return info
+}
+
+// mirrorModule git 镜像中的某个模块。
+type mirrorModule struct {
+	mirror  *GitMirror
+	repo    *gitx.Repo
+	modpath string
+	subdir  string // 模块路径对应的仓库子目录
+	prefix  string // 标签前缀
+}
+
+// resolve 解析版本查询对应的提交与版本号，并返回模块在该提交中所在的目录。
+//
+// 规范的版本号只匹配对应的标签或伪版本号中的提交，其它查询（分支、提交哈希等）交给 git 解析。
+func (mm *mirrorModule) resolve(ctx context.Context, query string) (*gitx.Revision, string, error) {
+	notFound := errcode.FmtModuleNotFound.Fmt(mm.modpath + "@" + query)
+	ref := query
+	canonical := module.CanonicalVersion(query) == query
+	if module.IsPseudoVersion(query) {
+		ref, _ = module.PseudoVersionRev(query)
+	} else if canonical {
+		ref = "refs/tags/" + mm.prefix + query
+	}
+
+	commit, err := mm.repo.Commit(ctx, ref)
+	if err != nil {
+		return nil, "", notFound
+	}
+	if module.IsPseudoVersion(query) && !mm.repo.PseudoValid(ctx, query, commit, mm.prefix, mm.modpath) {
+		return nil, "", notFound
+	}
+	subdir, err := mm.subdirAt(ctx, commit.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	rev, err := mm.repo.Resolve(ctx, commit.Hash, subdir, mm.modpath)
+	if err != nil {
+		return nil, "", err
+	}
+	if canonical {
+		rev.Version = query
+		if !module.IsPseudoVersion(query) {
+			rev.Ref = ref
+		}
+	}
+
+	return rev, subdir, nil
+}
+
+// subdirAt 模块在该提交中所在的目录。主版本号子目录（例如 api/v2）中没有该模块时，
+// 按照主版本号分支的约定使用去掉主版本号后缀的目录。
+func (mm *mirrorModule) subdirAt(ctx context.Context, hash string) (string, error) {
+	dirs := []string{mm.subdir}
+	if _, major, ok := module.SplitPathVersion(mm.modpath); ok && strings.HasPrefix(major, "/") {
+		dir := strings.TrimSuffix(strings.TrimSuffix(mm.subdir, major[1:]), "/")
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range dirs {
+		raw, err := mm.repo.ReadFile(ctx, hash, path.Join(dir, "go.mod"))
+		if err == nil && modfile.ModulePath(raw) == mm.modpath {
+			return dir, nil
+		}
+	}
+
+	return "", errcode.FmtModuleNotFound.Fmt(mm.modpath + "@" + hash)
+}
+
+func (mm *mirrorModule) info(rev *gitx.Revision) *response.GomodInfo {
+	return &response.GomodInfo{
+		Version: rev.Version,
+		Time:    rev.Time,
+		Origin: &response.GomodOrigin{
+			VCS:  "git",
+			URL:  mm.mirror.Dir,
+			Hash: rev.Hash,
+			Ref:  rev.Ref,
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+	"github.com/glebarez/sqlite"
+	"golang.org/x/mod/module"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newGomod 使用临时目录与内存数据库创建模块服务。
+func newGomod(t *testing.T) (*service.Gomod, *query.Query, string) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(model.All()...); err != nil {
+		t.Fatal(err)
+	}
+	qry := query.Use(db)
+	dir := t.TempDir()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gmd := service.NewGomod(dir, t.TempDir(), 0, qry, nil, log)
+
+	return gmd, qry, dir
+}
+
+func TestGomodMirror(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("go.mod", "module example.com/repo\n\ngo 1.16\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+	git("tag", "v1.2.0")
+	git("checkout", "-q", "-b", "dev")
+	write("a.go", "package repo\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "next")
+	head := git("rev-parse", "HEAD")
+
+	const modpath = "example.com/repo"
+	ctx := context.Background()
+	gmd, _, _ := newGomod(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mirror := service.NewGomodMirror([]service.GitMirror{{Module: modpath, Dir: dir}}, gmd, log)
+
+	// @v/list
+	if versions, err := mirror.List(ctx, modpath); err != nil || len(versions) != 1 || versions[0] != "v1.2.0" {
+		t.Errorf("List = %v, %v, want [v1.2.0]", versions, err)
+	}
+
+	// 分支的 .info
+	info, err := mirror.Query(ctx, modpath, "dev")
+	if err != nil {
+		t.Fatalf("Query(dev): %v", err)
+	}
+	if !module.IsPseudoVersion(info.Version) || !strings.HasSuffix(info.Version, head[:12]) || info.Origin.Hash != head {
+		t.Fatalf("Query(dev) = %s %s, want pseudo-version of %s", info.Version, info.Origin.Hash, head)
+	}
+	if base, _ := module.PseudoVersionBase(info.Version); base != "v1.2.0" {
+		t.Errorf("Query(dev) base = %q, want v1.2.0", base)
+	}
+
+	// 伪版本号的 .zip
+	if err = mirror.Download(ctx, modpath, info.Version); err != nil {
+		t.Fatalf("Download(%s): %v", info.Version, err)
+	}
+	escver, _ := module.EscapeVersion(info.Version)
+	file, err := gmd.Open(modpath, escver+".zip")
+	if err != nil {
+		t.Fatalf("Download(%s) did not store the zip: %v", info.Version, err)
+	}
+	_ = file.Close()
+
+	// 基础版本不是可以追溯到的标签、修订号或者时间与提交不一致的伪版本号不存在。
+	bad := []string{
+		module.PseudoVersion("v1", "", info.Time, head[:12]),
+		module.PseudoVersion("", "v1.1.0", info.Time, head[:12]),
+		module.PseudoVersion("", "v1.2.0", info.Time.Add(1e9), head[:12]),
+		module.PseudoVersion("", "v1.2.0", info.Time, head[:8]),
+	}
+	for _, version := range bad {
+		if err = mirror.Download(ctx, modpath, version); err == nil {
+			t.Errorf("Download(%s) should fail", version)
+		}
+	}
+
+	// 回收站中的版本不再列出，也不会重新生成。
+	for _, version := range []string{"v1.2.0", info.Version} {
+		if err = mirror.Download(ctx, modpath, version); err != nil {
+			t.Fatalf("Download(%s): %v", version, err)
+		}
+		if err = gmd.Delete(ctx, "1", &request.GomodDelete{Path: modpath, Version: version}); err != nil {
+			t.Fatalf("Delete(%s): %v", version, err)
+		}
+		if err = mirror.Download(ctx, modpath, version); err == nil {
+			t.Errorf("Download(%s) should fail after delete", version)
+		}
+		if _, err = mirror.Query(ctx, modpath, version); err == nil {
+			t.Errorf("Query(%s) should fail after delete", version)
+		}
+	}
+	if versions, err := mirror.List(ctx, modpath); err != nil || len(versions) != 0 {
+		t.Errorf("List = %v, %v after delete, want empty", versions, err)
+	}
+}
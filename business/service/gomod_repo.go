@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/library/gitx"
+	"golang.org/x/mod/semver"
+)
+
+// PublishRepo 上传包含多个模块的仓库压缩包，发现其中所有的模块并按照标签一起发布。
+//
+// 标签的写法与 go 的约定一致：根目录的模块使用 vX.Y.Z，子目录的模块使用 subdir/vX.Y.Z，
+// 主版本号子目录（例如 api/v2）的标签前缀不包含主版本号目录（即 api/v2.0.0）。与 go 命令一致，
+// 嵌套模块不会使用根目录的标签，没有对应前缀标签的模块会被跳过。
+//
+// 所有模块先全部校验通过后才会发布，发布过程中出错会撤销本次发布的版本。为了撤销时不误删
+// 已有的版本，仓库中已经存在的版本不允许通过多模块发布覆盖。
+func (gmd *Gomod) PublishRepo(ctx context.Context, pub *request.GomodPublisher, mf multipart.File, size int64, tags []string, dryRun bool) (*response.GomodRepo, error) {
+	versions := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		idx := strings.LastIndex(tag, "/")
+		prefix, version := tag[:idx+1], tag[idx+1:]
+		if !semver.IsValid(version) {
+			return nil, fmt.Errorf("标签 %s 格式错误，正确格式：vX.Y.Z 或 subdir/vX.Y.Z", tag)
+		}
+		versions[prefix] = version
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("至少需要一个标签")
+	}
+
+	zr, cleanup, err := gmd.OpenArchive(mf, size)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	top := archiveTop(zr)
+	ret := &response.GomodRepo{DryRun: dryRun, Root: top}
+	for _, gm := range gmd.archiveGomods(zr) {
+		dir, ok := strings.CutPrefix(gm.dir, top)
+		if !ok {
+			continue
+		}
+		mod := &response.GomodRepoModule{Dir: strings.TrimSuffix(dir, "/"), Path: gm.path}
+		ret.Modules = append(ret.Modules, mod)
+		if gm.path == "" {
+			mod.Status, mod.Reason = response.GomodRepoSkipped, "go.mod 中缺少 module 声明"
+			continue
+		}
+
+		prefix := gitx.TagPrefix(dir, gm.path)
+		version, exists := versions[prefix]
+		if !exists {
+			mod.Status, mod.Reason = response.GomodRepoSkipped, "没有匹配的标签："+prefix+"vX.Y.Z"
+			continue
+		}
+		mod.Tag, mod.Version = prefix+version, version
+	}
+	if len(ret.Modules) == 0 {
+		return nil, fmt.Errorf("压缩包中没有找到 go.mod")
+	}
+
+	// 先全部校验，再依次发布。
+	type pending struct {
+		mod  *response.GomodRepoModule
+		file *os.File
+	}
+	var pendings []*pending
+	defer func() {
+		for _, p := range pendings {
+			_ = p.file.Close()
+			_ = os.Remove(p.file.Name())
+		}
+	}()
+	var failed bool
+	for _, mod := range ret.Modules {
+		if mod.Status == response.GomodRepoSkipped {
+			continue
+		}
+		if slices.Contains(gmd.storedVersions(mod.Path), mod.Version) {
+			failed = true
+			mod.Status, mod.Reason = response.GomodRepoFailed, "版本已存在，多模块发布不允许覆盖已有版本"
+			continue
+		}
+		temp, report, err := gmd.FormatTemp(zr, mod.Path, mod.Version)
+		if err == nil {
+			pendings = append(pendings, &pending{mod: mod, file: temp})
+			mod.Result, err = gmd.Upload(ctx, pub, temp, mod.Path, mod.Version, true)
+		}
+		if err != nil {
+			failed = true
+			mod.Status, mod.Reason = response.GomodRepoFailed, err.Error()
+			continue
+		}
+		mod.Status = response.GomodRepoValid
+		mod.Result.Format = report
+	}
+	defer func() { AuditTarget(ctx, ret.Target()) }()
+	if failed {
+		return nil, &repoError{report: ret}
+	}
+	if len(pendings) == 0 {
+		return nil, &repoError{report: ret, msg: "没有可以发布的模块"}
+	}
+	if dryRun {
+		return ret, nil
+	}
+
+	var published []*response.GomodRepoModule
+	for _, p := range pendings {
+		mod := p.mod
+		if _, err = p.file.Seek(0, 0); err == nil {
+			var res *response.GomodUpload
+			if res, err = gmd.Upload(ctx, pub, p.file, mod.Path, mod.Version, false); err == nil {
+				res.Format, mod.Result = mod.Result.Format, res
+			}
+		}
+		if err != nil {
+			mod.Status, mod.Reason = response.GomodRepoFailed, err.Error()
+			break
+		}
+		mod.Status = response.GomodRepoPublished
+		published = append(published, mod)
+	}
+	if err != nil {
+		for _, mod := range published {
+			if exx := gmd.remove(context.WithoutCancel(ctx), mod.Path, mod.Version); exx != nil {
+				gmd.log.Warn("撤销已发布的模块出错", "path", mod.Path, "version", mod.Version, "error", exx)
+				continue
+			}
+			mod.Status, mod.Reason = response.GomodRepoFailed, "其它模块发布失败，已撤销"
+		}
+		return nil, &repoError{report: ret}
+	}
+
+	return ret, nil
+}
+
+// repoError 多模块仓库校验或发布失败，携带每个模块的处理结果。
+type repoError struct {
+	report *response.GomodRepo
+	msg    string
+}
+
+func (e *repoError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	var msgs []string
+	for _, mod := range e.report.Modules {
+		if mod.Status == response.GomodRepoFailed {
+			msgs = append(msgs, mod.Path+"@"+mod.Version+": "+mod.Reason)
+		}
+	}
+
+	return "多模块发布失败：" + strings.Join(msgs, "；")
+}
+
+func (e *repoError) Details() any {
+	return e.report
+}
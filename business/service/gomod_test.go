@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/xgfone/ship/v5"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// createZip 将 files 打包为模块 zip，files 的键为相对模块根目录的文件名。
+func createZip(t *testing.T, modpath, version string, files map[string]string) *os.File {
+	t.Helper()
+	src := t.TempDir()
+	for name, data := range files {
+		name = filepath.Join(src, name)
+		_ = os.MkdirAll(filepath.Dir(name), 0o755)
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.CreateTemp(t.TempDir(), "*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = file.Close() })
+	mdv := module.Version{Path: modpath, Version: version}
+	if err = modzip.CreateFromDir(file, mdv, src); err == nil {
+		_, err = file.Seek(0, 0)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestGomodUploadImmutable(t *testing.T) {
+	const modpath = "example.com/immutable"
+	ctx := context.Background()
+	gmd, _, _ := newGomod(t)
+	pub := &request.GomodPublisher{JobNumber: "1"}
+	v1 := map[string]string{"go.mod": "module " + modpath + "\n"}
+	v2 := map[string]string{"go.mod": "module " + modpath + "\n", "a.go": "package immutable\n"}
+
+	first, err := gmd.Upload(ctx, pub, createZip(t, modpath, "v1.0.0", v1), modpath, "v1.0.0", false)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	// 内容相同的重复上传直接成功。
+	if ret, err := gmd.Upload(ctx, pub, createZip(t, modpath, "v1.0.0", v1), modpath, "v1.0.0", false); err != nil || ret.Hash != first.Hash {
+		t.Fatalf("Upload(same) = %+v, %v", ret, err)
+	}
+	// 内容不同的同名版本返回 409，试运行也一样。
+	for _, dryRun := range []bool{true, false} {
+		_, err = gmd.Upload(ctx, pub, createZip(t, modpath, "v1.0.0", v2), modpath, "v1.0.0", dryRun)
+		var he ship.HTTPServerError
+		if !errors.As(err, &he) || he.Code != http.StatusConflict {
+			t.Errorf("Upload(different, dryRun=%v) = %v, want 409", dryRun, err)
+		}
+	}
+	file, err := gmd.Open(modpath, "v1.0.0.ziphash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	raw := make([]byte, len(first.Hash))
+	if _, err = file.Read(raw); err != nil || string(raw) != first.Hash {
+		t.Errorf("ziphash = %q after conflict, want %q", raw, first.Hash)
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+)
+
+// moveToTrash 将模块版本的文件移入回收站，version 为空时移入整个模块目录（包括嵌套的子模块）。
+// 回收站中的目录结构与模块存储相同：<回收站>/<ID>/<转义的模块路径>/@v/...，删除单个版本时会附带只含该版本的 list 文件。
+func (gmd *Gomod) moveToTrash(ctx context.Context, jobNumber, rawpath, rawversion, reason string) error {
+	escpath, err := module.EscapePath(rawpath)
+	if err != nil {
+		return err
+	}
+	var escver string
+	if rawversion != "" {
+		if escver, err = module.EscapeVersion(rawversion); err != nil {
+			return err
+		}
+	}
+	src := filepath.Join(gmd.dir, escpath)
+	names := gmd.versionFiles(src, escver)
+	if rawversion == "" {
+		if _, err = os.Stat(src); os.IsNotExist(err) {
+			return nil
+		}
+	} else if len(names) == 0 {
+		return nil
+	}
+
+	tbl := gmd.qry.GomodVersion
+	cond := []gen.Condition{tbl.Path.Eq(rawpath), tbl.Version.Eq(rawversion)}
+	if rawversion == "" {
+		cond = []gen.Condition{field.Or(tbl.Path.Eq(rawpath), nestedPath(tbl.Path, rawpath))}
+	}
+	versions, err := tbl.WithContext(ctx).Where(cond...).Find()
+	if err != nil {
+		return err
+	}
+	rec := &model.GomodTrash{
+		Path:      rawpath,
+		Version:   rawversion,
+		JobNumber: jobNumber,
+		Reason:    reason,
+		Versions:  versions,
+		ExpiredAt: time.Now().Add(gmd.retention),
+	}
+	if err = gmd.qry.GomodTrash.WithContext(ctx).Create(rec); err != nil {
+		return err
+	}
+	AuditDetail(ctx, "trash_id", rec.ID)
+
+	dest := filepath.Join(gmd.trashDir(rec.ID), escpath)
+	if rawversion == "" {
+		err = moveFile(src, dest)
+	} else {
+		err = gmd.moveVersion(src, dest, rawversion, names)
+	}
+	if err != nil {
+		// 移动到一半失败时先将已经移入回收站的文件移回原处，全部移回后才能删除回收站目录，
+		// 否则保留回收站记录，以便管理员恢复。
+		if exx := moveBack(src, dest, names); exx != nil {
+			gmd.log.Error("移入回收站失败且无法撤销，文件保留在回收站中", "id", rec.ID, "error", exx)
+			return err
+		}
+		_, _ = gmd.qry.GomodTrash.WithContext(ctx).Where(gmd.qry.GomodTrash.ID.Eq(rec.ID)).Delete()
+		_ = os.RemoveAll(gmd.trashDir(rec.ID))
+		return err
+	}
+	_, err = tbl.WithContext(ctx).Where(cond...).Delete()
+
+	return err
+}
+
+// moveVersion 将 src/@v 下的版本文件移动到 dest/@v，并从 list 中移除该版本，list 中记录的是未转义的版本号。
+func (gmd *Gomod) moveVersion(src, dest, rawversion string, names []string) error {
+	srcdir, destdir := filepath.Join(src, "@v"), filepath.Join(dest, "@v")
+	if err := os.MkdirAll(destdir, 0o755); err != nil {
+		return err
+	}
+	if err := updateList(filepath.Join(destdir, "list"), []string{rawversion}, ""); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := moveFile(filepath.Join(srcdir, name), filepath.Join(destdir, name)); err != nil {
+			return err
+		}
+	}
+
+	return updateList(filepath.Join(srcdir, "list"), nil, rawversion)
+}
+
+// moveBack 将已经移入回收站 dest/@v 的版本文件移回 src/@v，用于撤销移动到一半失败的 moveVersion，
+// src/@v 中仍然存在的文件说明还没有被移动。
+func moveBack(src, dest string, names []string) error {
+	var errs []error
+	for _, name := range names {
+		from, to := filepath.Join(dest, "@v", name), filepath.Join(src, "@v", name)
+		if _, err := os.Lstat(to); err == nil { // 还没有被移动
+			continue
+		}
+		if err := os.Rename(from, to); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// versionFiles 模块目录 @v 下属于该版本的文件名，例如 v1.0.0.zip、v1.0.0.mod。
+func (gmd *Gomod) versionFiles(dir, escver string) []string {
+	if escver == "" {
+		return nil
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "@v"))
+	var names []string
+	for _, ent := range entries {
+		name := ent.Name()
+		if ext := path.Ext(name); ext != "" && name == escver+ext && !ent.IsDir() {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Trash 回收站中的记录，modpath 不为空时只查看该模块（包括嵌套的子模块）。
+func (gmd *Gomod) Trash(ctx context.Context, modpath string) ([]*model.GomodTrash, error) {
+	tbl := gmd.qry.GomodTrash
+	dao := tbl.WithContext(ctx)
+	if modpath != "" {
+		dao = dao.Where(field.Or(tbl.Path.Eq(modpath), nestedPath(tbl.Path, modpath)))
+	}
+
+	return dao.Order(tbl.ID.Desc()).Find()
+}
+
+// Restore 从回收站恢复，仓库中已经重新发布了同名版本时拒绝恢复。
+func (gmd *Gomod) Restore(ctx context.Context, id int64) (*model.GomodTrash, error) {
+	tbl := gmd.qry.GomodTrash
+	rec, err := tbl.WithContext(ctx).Where(tbl.ID.Eq(id)).First()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errcode.ErrDataNotExists
+		}
+		return nil, err
+	}
+	if rec.Version == "" {
+		AuditTarget(ctx, rec.Path)
+	} else {
+		AuditTarget(ctx, rec.Path+"@"+rec.Version)
+	}
+	AuditDetail(ctx, "trash_id", rec.ID)
+
+	root := gmd.trashDir(rec.ID)
+	var files []string
+	err = filepath.WalkDir(root, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, fpath)
+		if err != nil {
+			return err
+		}
+		if d.Name() != "list" {
+			if _, exx := os.Stat(filepath.Join(gmd.dir, rel)); exx == nil {
+				return errcode.FmtTrashConflict.Fmt(filepath.ToSlash(rel))
+			}
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 先移动版本文件再合并 list，避免代理读到 list 中的版本时文件还不存在。
+	var lists []string
+	for _, rel := range files {
+		if filepath.Base(rel) == "list" {
+			lists = append(lists, rel)
+			continue
+		}
+		if err = moveFile(filepath.Join(root, rel), filepath.Join(gmd.dir, rel)); err != nil {
+			return nil, err
+		}
+	}
+	for _, rel := range lists {
+		versions, err := readList(filepath.Join(root, rel))
+		if err != nil {
+			return nil, err
+		}
+		if err = updateList(filepath.Join(gmd.dir, rel), versions, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	err = gmd.qry.Transaction(func(tx *query.Query) error {
+		vtbl := tx.GomodVersion
+		for _, ver := range rec.Versions {
+			ver.ID = 0
+			if _, err := vtbl.WithContext(ctx).Where(vtbl.Path.Eq(ver.Path), vtbl.Version.Eq(ver.Version)).Delete(); err != nil {
+				return err
+			}
+			if err := vtbl.WithContext(ctx).Create(ver); err != nil {
+				return err
+			}
+		}
+		_, err := tx.GomodTrash.WithContext(ctx).Where(tx.GomodTrash.ID.Eq(rec.ID)).Delete()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = os.RemoveAll(root)
+
+	return rec, nil
+}
+
+// Purge 彻底删除回收站中过期的记录，返回删除的个数。
+func (gmd *Gomod) Purge(ctx context.Context) (int, error) {
+	tbl := gmd.qry.GomodTrash
+	recs, err := tbl.WithContext(ctx).
+		Select(tbl.ID).
+		Where(tbl.ExpiredAt.Lte(time.Now())).
+		Find()
+	if err != nil {
+		return 0, err
+	}
+
+	var cnt int
+	for _, rec := range recs {
+		if err = os.RemoveAll(gmd.trashDir(rec.ID)); err != nil {
+			return cnt, err
+		}
+		if _, err = tbl.WithContext(ctx).Where(tbl.ID.Eq(rec.ID)).Delete(); err != nil {
+			return cnt, err
+		}
+		cnt++
+	}
+
+	return cnt, nil
+}
+
+// RunPurge 定期清理回收站中过期的记录，直到 ctx 结束。
+func (gmd *Gomod) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if cnt, err := gmd.Purge(ctx); err != nil {
+			gmd.log.Warn("清理回收站出错", "error", err)
+		} else if cnt != 0 {
+			gmd.log.Info("清理了回收站中过期的模块", "count", cnt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (gmd *Gomod) trashDir(id int64) string {
+	return filepath.Join(gmd.trash, strconv.FormatInt(id, 10))
+}
+
+// moveFile 移动文件或目录，目标的父目录不存在时自动创建。
+func moveFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	return os.Rename(src, dest)
+}
+
+func readList(fstr string) ([]string, error) {
+	file, err := os.Open(fstr)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var versions []string
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			versions = append(versions, line)
+		}
+	}
+
+	return versions, sc.Err()
+}
+
+// updateList 向 list 文件中添加版本 add 并移除版本 del，版本按 semver 排序。
+func updateList(fstr string, add []string, del string) error {
+	versions, err := readList(fstr)
+	if err != nil {
+		return err
+	}
+	index := make(map[string]struct{}, len(versions)+len(add))
+	merged := make([]string, 0, len(versions)+len(add))
+	for _, ver := range append(versions, add...) {
+		if _, exists := index[ver]; exists || ver == del {
+			continue
+		}
+		index[ver] = struct{}{}
+		merged = append(merged, ver)
+	}
+	semver.Sort(merged)
+
+	fd, err := os.OpenFile(fstr, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, ver := range merged {
+		_, _ = fd.WriteString(ver + "\n")
+	}
+
+	return fd.Close()
+}
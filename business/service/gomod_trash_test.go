@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+func TestGomodTrash(t *testing.T) {
+	const modpath = "example.com/trash"
+	ctx := context.Background()
+	gmd, qry, dir := newGomod(t)
+
+	publish := func(version string) {
+		src := t.TempDir()
+		if err := os.WriteFile(filepath.Join(src, "go.mod"), []byte("module "+modpath+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.CreateTemp(t.TempDir(), "*.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		mdv := module.Version{Path: modpath, Version: version}
+		if err = modzip.CreateFromDir(file, mdv, src); err == nil {
+			_, err = file.Seek(0, 0)
+		}
+		if err == nil {
+			_, err = gmd.Upload(ctx, &request.GomodPublisher{JobNumber: "1"}, file, modpath, version, false)
+		}
+		if err != nil {
+			t.Fatalf("Upload(%s): %v", version, err)
+		}
+	}
+	listed := func() []string {
+		raw, _ := os.ReadFile(filepath.Join(dir, modpath, "@v", "list"))
+		return strings.Fields(string(raw))
+	}
+	exists := func(version string) bool {
+		escver, _ := module.EscapeVersion(version)
+		_, err := os.Stat(filepath.Join(dir, modpath, "@v", escver+".zip"))
+		return err == nil
+	}
+
+	// 带大写字母的版本号转义后与 list 中记录的版本号不同。
+	const version = "v1.1.0-RC1"
+	publish("v1.0.0")
+	publish(version)
+
+	del := &request.GomodDelete{Path: modpath, Version: version}
+	if err := gmd.Delete(ctx, "1", del); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if slices.Contains(listed(), version) || exists(version) {
+		t.Fatalf("list = %v after delete, want %s removed", listed(), version)
+	}
+	recs, err := gmd.Trash(ctx, modpath)
+	if err != nil || len(recs) != 1 || recs[0].Version != version || len(recs[0].Versions) != 1 {
+		t.Fatalf("Trash = %v, %v", recs, err)
+	}
+	if cnt, _ := qry.GomodVersion.WithContext(ctx).Where(qry.GomodVersion.Version.Eq(version)).Count(); cnt != 0 {
+		t.Errorf("version record still exists after delete")
+	}
+
+	if _, err = gmd.Restore(ctx, recs[0].ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := listed(); !slices.Equal(got, []string{"v1.0.0", version}) || !exists(version) {
+		t.Fatalf("list = %v after restore", got)
+	}
+	if cnt, _ := qry.GomodVersion.WithContext(ctx).Where(qry.GomodVersion.Version.Eq(version)).Count(); cnt != 1 {
+		t.Errorf("version record not restored")
+	}
+	if recs, _ = gmd.Trash(ctx, modpath); len(recs) != 0 {
+		t.Errorf("Trash = %v after restore, want empty", recs)
+	}
+
+	// 回收站保留时间为 0，删除后立即过期。
+	if err = gmd.Delete(ctx, "1", del); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	recs, _ = gmd.Trash(ctx, modpath)
+	if cnt, err := gmd.Purge(ctx); err != nil || cnt != 1 {
+		t.Fatalf("Purge = %d, %v, want 1", cnt, err)
+	}
+	if rest, _ := gmd.Trash(ctx, ""); len(rest) != 0 {
+		t.Errorf("Trash = %v after purge, want empty", rest)
+	}
+	if _, err = gmd.Restore(ctx, recs[0].ID); err == nil {
+		t.Errorf("Restore after purge should fail")
+	}
+	if got := listed(); !slices.Equal(got, []string{"v1.0.0"}) {
+		t.Errorf("list = %v after purge, want [v1.0.0]", got)
+	}
+}
+
+func TestGomodTrashRollback(t *testing.T) {
+	const modpath = "example.com/rollback"
+	ctx := context.Background()
+	_, qry, dir := newGomod(t)
+	trash := t.TempDir()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gmd := service.NewGomod(dir, trash, 0, qry, nil, log)
+
+	file := createZip(t, modpath, "v1.0.0", map[string]string{"go.mod": "module " + modpath + "\n"})
+	if _, err := gmd.Upload(ctx, &request.GomodPublisher{JobNumber: "1"}, file, modpath, "v1.0.0", false); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	srcdir := filepath.Join(dir, modpath, "@v")
+	before, _ := os.ReadDir(srcdir)
+
+	// 回收站中占位的非空目录使 .zip 无法移入，此时 .info、.mod 已经移入回收站。
+	blocker := filepath.Join(trash, "1", modpath, "@v", "v1.0.0.zip")
+	if err := os.MkdirAll(filepath.Join(blocker, "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	del := &request.GomodDelete{Path: modpath, Version: "v1.0.0"}
+	if err := gmd.Delete(ctx, "1", del); err == nil {
+		t.Fatalf("Delete should fail")
+	}
+	after, _ := os.ReadDir(srcdir)
+	if len(after) != len(before) {
+		t.Fatalf("files = %v after failed delete, want %v", after, before)
+	}
+	for i := range before {
+		if before[i].Name() != after[i].Name() {
+			t.Fatalf("files = %v after failed delete, want %v", after, before)
+		}
+	}
+	if recs, _ := gmd.Trash(ctx, ""); len(recs) != 0 {
+		t.Errorf("Trash = %v after failed delete, want empty", recs)
+	}
+	if _, err := os.Stat(filepath.Join(trash, "1")); !os.IsNotExist(err) {
+		t.Errorf("trash dir still exists after rollback: %v", err)
+	}
+
+	if err := gmd.Delete(ctx, "1", del); err != nil {
+		t.Fatalf("Delete after rollback: %v", err)
+	}
+	if recs, _ := gmd.Trash(ctx, ""); len(recs) != 1 || len(recs[0].Versions) != 1 {
+		t.Errorf("Trash = %v after delete", recs)
+	}
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+	modzip "golang.org/x/mod/zip"
+)
+
+// GomodUpload 断点续传：创建会话、分块追加、查询进度、最终校验发布。
+//
+// 分块文件存放在 dir 目录下，未完成的会话过期后由 Cleanup 清理。
+type GomodUpload struct {
+	dir   string
+	gmd   *Gomod
+	qry   *query.Query
+	log   *slog.Logger
+	ttl   time.Duration
+	locks sync.Map
+}
+
+func NewGomodUpload(dir string, gmd *Gomod, qry *query.Query, log *slog.Logger) *GomodUpload {
+	return &GomodUpload{
+		dir: dir,
+		gmd: gmd,
+		qry: qry,
+		log: log,
+		ttl: 24 * time.Hour,
+	}
+}
+
+// MaxSize 允许上传的最大字节数，与 go 模块 zip 的大小限制一致。
+func (gu *GomodUpload) MaxSize() int64 {
+	return modzip.MaxZipFile
+}
+
+func (gu *GomodUpload) Create(ctx context.Context, jobNumber string, req *request.GomodUploadCreate) (*model.GomodUpload, error) {
+	AuditTarget(ctx, req.Path+"@"+req.Version)
+	if req.Length <= 0 {
+		return nil, errors.New("文件大小必须大于 0")
+	}
+	if req.Length > gu.MaxSize() {
+		return nil, errcode.ErrUploadTooLarge
+	}
+	if err := os.MkdirAll(gu.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	dat := &model.GomodUpload{
+		JobNumber: jobNumber,
+		Path:      req.Path,
+		Version:   req.Version,
+		DryRun:    req.DryRun,
+		Length:    req.Length,
+		ExpiredAt: time.Now().Add(gu.ttl),
+	}
+	if err := gu.qry.GomodUpload.WithContext(ctx).Create(dat); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(gu.filename(dat.ID), nil, 0o600); err != nil {
+		_ = gu.remove(ctx, dat.ID)
+		return nil, err
+	}
+	AuditDetail(ctx, "upload_id", dat.ID)
+
+	return dat, nil
+}
+
+// Get 查询上传进度，只能查询自己创建且未过期的会话。
+//
+// 会话已过期但尚未被清理时返回 410，tus 客户端据此重新创建会话。
+func (gu *GomodUpload) Get(ctx context.Context, jobNumber string, id int64) (*model.GomodUpload, error) {
+	tbl := gu.qry.GomodUpload
+	dat, err := tbl.WithContext(ctx).
+		Where(tbl.ID.Eq(id), tbl.JobNumber.Eq(jobNumber)).
+		First()
+	if err != nil {
+		return nil, errcode.ErrNotFound
+	}
+	if !dat.ExpiredAt.After(time.Now()) {
+		return nil, errcode.ErrUploadExpired
+	}
+
+	return dat, nil
+}
+
+// Append 从 offset 处追加写入分块，offset 必须与已上传的字节数一致。
+//
+// 连接中断时已经写入的部分依然有效，客户端查询进度后从新的偏移量继续上传即可。
+func (gu *GomodUpload) Append(ctx context.Context, jobNumber string, id, offset int64, r io.Reader) (*model.GomodUpload, error) {
+	unlock, ok := gu.lock(id)
+	if !ok {
+		return nil, errcode.ErrUploadLocked
+	}
+	defer unlock()
+
+	dat, err := gu.Get(ctx, jobNumber, id)
+	if err != nil {
+		return nil, err
+	}
+	AuditTarget(ctx, dat.Path+"@"+dat.Version)
+	if offset != dat.Offset {
+		return nil, errcode.ErrUploadOffset
+	}
+
+	file, err := os.OpenFile(gu.filename(id), os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	// 丢弃上次异常中断时写入但未记录的数据。
+	if err = file.Truncate(offset); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	remain := dat.Length - offset
+	n, err := io.Copy(file, io.LimitReader(r, remain+1))
+	if n > remain {
+		n = remain
+		_ = file.Truncate(dat.Length)
+		err = errcode.ErrUploadTooLarge
+	}
+	if exx := file.Close(); err == nil {
+		err = exx
+	}
+
+	now := time.Now()
+	dat.Offset += n
+	dat.ExpiredAt = now.Add(gu.ttl)
+	dat.UpdatedAt = now
+	tbl := gu.qry.GomodUpload
+	if _, exx := tbl.WithContext(context.WithoutCancel(ctx)).
+		Where(tbl.ID.Eq(id)).
+		UpdateColumnSimple(
+			tbl.Offset.Value(dat.Offset),
+			tbl.ExpiredAt.Value(dat.ExpiredAt),
+			tbl.UpdatedAt.Value(now),
+		); exx != nil && err == nil {
+		err = exx
+	}
+	AuditDetail(ctx, "offset", dat.Offset)
+
+	return dat, err
+}
+
+// Finalize 文件上传完毕后走与普通上传相同的校验和发布流程。
+//
+// 校验不通过的会话会被删除，重新上传需要创建新的会话。
+func (gu *GomodUpload) Finalize(ctx context.Context, pub *request.GomodPublisher, id int64) (*response.GomodUpload, error) {
+	unlock, ok := gu.lock(id)
+	if !ok {
+		return nil, errcode.ErrUploadLocked
+	}
+	defer unlock()
+
+	dat, err := gu.Get(ctx, pub.JobNumber, id)
+	if err != nil {
+		return nil, err
+	}
+	if dat.Offset != dat.Length {
+		return nil, errcode.ErrUploadIncomplete
+	}
+
+	file, err := os.Open(gu.filename(id))
+	if err != nil {
+		return nil, err
+	}
+	ret, err := gu.gmd.Publish(ctx, pub, file, dat.Length, dat.Path, dat.Version, dat.DryRun)
+	_ = file.Close()
+
+	var dt errcode.Detailer
+	if err == nil || errors.As(err, &dt) {
+		if exx := gu.remove(context.WithoutCancel(ctx), id); exx != nil {
+			gu.log.Warn("删除上传会话出错", "id", id, "error", exx)
+		}
+	}
+
+	return ret, err
+}
+
+// Delete 取消上传。
+func (gu *GomodUpload) Delete(ctx context.Context, jobNumber string, id int64) error {
+	unlock, ok := gu.lock(id)
+	if !ok {
+		return errcode.ErrUploadLocked
+	}
+	defer unlock()
+
+	dat, err := gu.Get(ctx, jobNumber, id)
+	if err != nil {
+		return err
+	}
+	AuditTarget(ctx, dat.Path+"@"+dat.Version)
+
+	return gu.remove(ctx, id)
+}
+
+// Cleanup 清理过期的上传会话，返回清理的个数。
+func (gu *GomodUpload) Cleanup(ctx context.Context) (int, error) {
+	tbl := gu.qry.GomodUpload
+	dats, err := tbl.WithContext(ctx).
+		Select(tbl.ID).
+		Where(tbl.ExpiredAt.Lte(time.Now())).
+		Find()
+	if err != nil {
+		return 0, err
+	}
+
+	var cnt int
+	for _, dat := range dats {
+		unlock, ok := gu.lock(dat.ID)
+		if !ok {
+			continue
+		}
+		err = gu.remove(ctx, dat.ID)
+		unlock()
+		if err != nil {
+			return cnt, err
+		}
+		cnt++
+	}
+
+	return cnt, nil
+}
+
+// Run 定期清理过期的上传会话，直到 ctx 结束。
+func (gu *GomodUpload) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if cnt, err := gu.Cleanup(ctx); err != nil {
+			gu.log.Warn("清理过期的上传会话出错", "error", err)
+		} else if cnt != 0 {
+			gu.log.Info("清理了过期的上传会话", "count", cnt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (gu *GomodUpload) remove(ctx context.Context, id int64) error {
+	tbl := gu.qry.GomodUpload
+	if _, err := tbl.WithContext(ctx).Where(tbl.ID.Eq(id)).Delete(); err != nil {
+		return err
+	}
+	if err := os.Remove(gu.filename(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (gu *GomodUpload) filename(id int64) string {
+	return filepath.Join(gu.dir, strconv.FormatInt(id, 10)+".part")
+}
+
+// lock 同一个上传会话同时只允许一个写入者。
+func (gu *GomodUpload) lock(id int64) (func(), bool) {
+	if _, loaded := gu.locks.LoadOrStore(id, struct{}{}); loaded {
+		return nil, false
+	}
+
+	return func() { gu.locks.Delete(id) }, true
+}
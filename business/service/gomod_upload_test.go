@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
+)
+
+// blockReader 第一次读取时通知 started，然后阻塞到 release 关闭。
+type blockReader struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (br *blockReader) Read([]byte) (int, error) {
+	close(br.started)
+	<-br.release
+	return 0, io.EOF
+}
+
+func TestGomodUpload(t *testing.T) {
+	ctx := context.Background()
+	gmd, qry, _ := newGomod(t)
+	dir := t.TempDir()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gu := service.NewGomodUpload(dir, gmd, qry, log)
+
+	dat, err := gu.Create(ctx, "1", &request.GomodUploadCreate{Path: "example.com/tus", Version: "v1.0.0", Length: 10})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	id := dat.ID
+	part := filepath.Join(dir, strconv.FormatInt(id, 10)+".part")
+	content := func() string {
+		raw, _ := os.ReadFile(part)
+		return string(raw)
+	}
+
+	// 偏移量必须与服务端记录的一致。
+	if _, err = gu.Append(ctx, "1", id, 3, strings.NewReader("abc")); !errors.Is(err, errcode.ErrUploadOffset) {
+		t.Errorf("Append(offset=3) = %v, want ErrUploadOffset", err)
+	}
+	if dat, err = gu.Append(ctx, "1", id, 0, strings.NewReader("abcd")); err != nil || dat.Offset != 4 {
+		t.Fatalf("Append(offset=0) = %+v, %v", dat, err)
+	}
+
+	// 其他人不能查看或续传别人的会话。
+	if _, err = gu.Get(ctx, "2", id); !errors.Is(err, errcode.ErrNotFound) {
+		t.Errorf("Get by others = %v, want ErrNotFound", err)
+	}
+
+	// 异常中断时写入但未记录的数据会在下次追加时被丢弃。
+	if err = os.WriteFile(part, []byte("abcdXXXXX"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if dat, err = gu.Append(ctx, "1", id, 4, strings.NewReader("ef")); err != nil || dat.Offset != 6 || content() != "abcdef" {
+		t.Fatalf("Append(offset=4) = %+v, %v, content %q", dat, err, content())
+	}
+
+	// 同一个会话同时只允许一个写入者。
+	br := &blockReader{started: make(chan struct{}), release: make(chan struct{})}
+	done := make(chan error)
+	go func() {
+		_, exx := gu.Append(ctx, "1", id, 6, br)
+		done <- exx
+	}()
+	<-br.started
+	if _, err = gu.Append(ctx, "1", id, 6, strings.NewReader("gh")); !errors.Is(err, errcode.ErrUploadLocked) {
+		t.Errorf("concurrent Append = %v, want ErrUploadLocked", err)
+	}
+	if _, err = gu.Finalize(ctx, &request.GomodPublisher{JobNumber: "1"}, id); !errors.Is(err, errcode.ErrUploadLocked) {
+		t.Errorf("concurrent Finalize = %v, want ErrUploadLocked", err)
+	}
+	close(br.release)
+	if err = <-done; err != nil {
+		t.Fatalf("blocked Append: %v", err)
+	}
+
+	// 超出声明大小的部分被截断。
+	if dat, err = gu.Append(ctx, "1", id, 6, strings.NewReader("ghijkl")); !errors.Is(err, errcode.ErrUploadTooLarge) || dat.Offset != 10 || content() != "abcdefghij" {
+		t.Fatalf("Append(too large) = %+v, %v, content %q", dat, err, content())
+	}
+
+	// 过期但尚未清理的会话返回 410。
+	tbl := qry.GomodUpload
+	if _, err = tbl.WithContext(ctx).Where(tbl.ID.Eq(id)).UpdateColumnSimple(tbl.ExpiredAt.Value(time.Now().Add(-time.Second))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = gu.Get(ctx, "1", id); !errors.Is(err, errcode.ErrUploadExpired) {
+		t.Errorf("Get(expired) = %v, want ErrUploadExpired", err)
+	}
+	if _, err = gu.Append(ctx, "1", id, 10, strings.NewReader("")); !errors.Is(err, errcode.ErrUploadExpired) {
+		t.Errorf("Append(expired) = %v, want ErrUploadExpired", err)
+	}
+	if cnt, err := gu.Cleanup(ctx); err != nil || cnt != 1 {
+		t.Fatalf("Cleanup = %d, %v, want 1", cnt, err)
+	}
+	if _, err = gu.Get(ctx, "1", id); !errors.Is(err, errcode.ErrNotFound) {
+		t.Errorf("Get(cleaned) = %v, want ErrNotFound", err)
+	}
+	if _, err = os.Stat(part); !os.IsNotExist(err) {
+		t.Errorf("part file still exists after cleanup: %v", err)
+	}
+}
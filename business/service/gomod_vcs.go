@@ -0,0 +1,230 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/library/gitx"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// GomodVCS 从服务器本地的 git 仓库（工作区、裸仓库镜像或 file:// 地址）发布模块。
+//
+// 只允许访问 roots 目录下的仓库，roots 为空时禁用该功能。
+type GomodVCS struct {
+	roots []string
+	gmd   *Gomod
+	log   *slog.Logger
+}
+
+func NewGomodVCS(roots []string, gmd *Gomod, log *slog.Logger) *GomodVCS {
+	return &GomodVCS{
+		roots: roots,
+		gmd:   gmd,
+		log:   log,
+	}
+}
+
+func (gv *GomodVCS) Publish(ctx context.Context, pub *request.GomodPublisher, req *request.GomodVCSPublish) (*response.GomodUpload, error) {
+	repo, err := gv.open(ctx, req.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	subdir := strings.Trim(path.Clean("/"+req.Subdir), "/")
+	commit, err := repo.Commit(ctx, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := repo.ReadFile(ctx, commit.Hash, path.Join(subdir, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("%s 中没有 go.mod 文件", path.Join(subdir, "/"))
+	}
+	modpath := modfile.ModulePath(raw)
+	if modpath == "" {
+		return nil, errors.New("go.mod 中缺少 module 声明")
+	}
+	if req.Path != "" && req.Path != modpath {
+		return nil, fmt.Errorf("模块名不匹配 (输入为 %s, 检测到 %s)", req.Path, modpath)
+	}
+
+	rev, err := repo.Resolve(ctx, req.Revision, subdir, modpath)
+	if err != nil {
+		return nil, err
+	}
+	version, ref := rev.Version, rev.Ref
+	if req.Version != "" && req.Version != version {
+		if ref, err = gv.checkVersion(ctx, repo, commit, subdir, modpath, req.Version); err != nil {
+			return nil, err
+		}
+		version = req.Version
+	}
+
+	vcsPub := *pub
+	vcsPub.Repository = req.Repository
+	vcsPub.CommitSHA = rev.Hash
+	vcsPub.Time = rev.Time
+	vcsPub.Ref = ref
+	AuditDetail(ctx, "revision", req.Revision)
+
+	temp, err := os.CreateTemp(os.TempDir(), "gomod_vcs_*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	mdv := module.Version{Path: modpath, Version: version}
+	if err = createFromRepo(ctx, temp, repo, mdv, rev.Hash, subdir); err == nil {
+		_, err = temp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return gv.gmd.Upload(ctx, &vcsPub, temp, modpath, version, req.DryRun)
+}
+
+// checkVersion 校验手动指定的版本号，返回对应的标签引用：版本号必须有指向该提交的标签，
+// 或者是与该提交一致的伪版本号（与镜像仓库校验伪版本号的规则相同）。
+func (gv *GomodVCS) checkVersion(ctx context.Context, repo *gitx.Repo, commit *gitx.Commit, subdir, modpath, version string) (string, error) {
+	if err := module.Check(modpath, version); err != nil {
+		return "", fmt.Errorf("版本号 %s 不合法：%w", version, err)
+	}
+	prefix := gitx.TagPrefix(subdir, modpath)
+	if module.IsPseudoVersion(version) {
+		if !repo.PseudoValid(ctx, version, commit, prefix, modpath) {
+			return "", fmt.Errorf("伪版本号 %s 与提交 %s 不一致", version, commit.Hash)
+		}
+		return "", nil
+	}
+	tags, err := repo.TagsAt(ctx, commit.Hash)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(tags, prefix+version) {
+		return "", fmt.Errorf("没有指向提交 %s 的标签 %s", commit.Hash, prefix+version)
+	}
+
+	return "refs/tags/" + prefix + version, nil
+}
+
+// createFromRepo 将仓库某个提交中 subdir 目录下的模块打包为模块 zip。
+func createFromRepo(ctx context.Context, w io.Writer, repo *gitx.Repo, mdv module.Version, hash, subdir string) error {
+	if repo.Bare() {
+		// 裸仓库没有 .git 目录，CreateFromVCS 无法识别，先用 git archive 导出再转换。
+		return createFromBare(ctx, w, repo, mdv, hash, subdir)
+	}
+
+	return modzip.CreateFromVCS(w, mdv, repo.Dir(), hash, subdir)
+}
+
+// createFromBare 与 CreateFromVCS 的处理方式一致：子目录中的模块没有 LICENSE 时使用仓库根目录的 LICENSE。
+func createFromBare(ctx context.Context, w io.Writer, repo *gitx.Repo, mdv module.Version, hash, subdir string) error {
+	archive, err := os.CreateTemp(os.TempDir(), "gomod_git_*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err = repo.Archive(ctx, archive, hash, subdir); err != nil {
+		return err
+	}
+	inf, err := archive.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(archive, inf.Size())
+	if err != nil {
+		return err
+	}
+
+	var license bool
+	var files []modzip.File
+	for _, zf := range zr.File {
+		name, ok := strings.CutPrefix(zf.Name, subdir)
+		name = strings.TrimPrefix(name, "/")
+		if !ok || name == "" || zf.FileInfo().IsDir() {
+			continue
+		}
+		files = append(files, &zipFile{f: zf, name: name})
+		license = license || name == "LICENSE"
+	}
+	if !license && subdir != "" {
+		if raw, exx := repo.ReadFile(ctx, hash, "LICENSE"); exx == nil {
+			files = append(files, &dataFile{name: "LICENSE", data: raw})
+		}
+	}
+
+	return modzip.Create(w, mdv, files)
+}
+
+// dataFile 内存中的文件。
+type dataFile struct {
+	name string
+	data []byte
+}
+
+func (f *dataFile) Path() string                 { return f.name }
+func (f *dataFile) Lstat() (os.FileInfo, error)  { return dataFileInfo{f}, nil }
+func (f *dataFile) Open() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(f.data)), nil }
+
+type dataFileInfo struct {
+	f *dataFile
+}
+
+func (fi dataFileInfo) Name() string       { return path.Base(fi.f.name) }
+func (fi dataFileInfo) Size() int64        { return int64(len(fi.f.data)) }
+func (fi dataFileInfo) Mode() os.FileMode  { return 0o644 }
+func (fi dataFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi dataFileInfo) IsDir() bool        { return false }
+func (fi dataFileInfo) Sys() any           { return nil }
+
+// open 打开仓库，仓库必须位于允许的目录下。
+func (gv *GomodVCS) open(ctx context.Context, repository string) (*gitx.Repo, error) {
+	if len(gv.roots) == 0 {
+		return nil, errors.New("未配置允许发布的 git 仓库目录")
+	}
+	dir := strings.TrimPrefix(repository, "file://")
+	if strings.Contains(dir, "://") {
+		return nil, errors.New("只支持服务器本地的 git 仓库")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if real, exx := filepath.EvalSymlinks(dir); exx == nil {
+		dir = real
+	}
+
+	for _, root := range gv.roots {
+		root, err = filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if real, exx := filepath.EvalSymlinks(root); exx == nil {
+			root = real
+		}
+		if rel, exx := filepath.Rel(root, dir); exx == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return gitx.Open(ctx, dir)
+		}
+	}
+
+	return nil, fmt.Errorf("%s 不在允许发布的 git 仓库目录中", repository)
+}
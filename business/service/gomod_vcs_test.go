@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"golang.org/x/mod/module"
+)
+
+func TestGomodVCSVersion(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	const modpath = "example.com/vcs"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+modpath+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+	git("tag", "v1.0.0-rc.1")
+	git("tag", "v1.0.0")
+	git("commit", "-q", "--allow-empty", "-m", "fix")
+	git("tag", "v1.0.1")
+	git("commit", "-q", "--allow-empty", "-m", "next")
+	head := git("rev-parse", "HEAD")
+	sec, err := strconv.ParseInt(git("log", "-n1", "--format=%ct", "HEAD"), 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Unix(sec, 0).UTC()
+
+	ctx := context.Background()
+	gmd, _, _ := newGomod(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	gv := service.NewGomodVCS([]string{dir}, gmd, log)
+	publish := func(rev, version string) error {
+		req := &request.GomodVCSPublish{Repository: dir, Revision: rev, Version: version, DryRun: true}
+		_, err := gv.Publish(ctx, &request.GomodPublisher{JobNumber: "1"}, req)
+		return err
+	}
+
+	cases := []struct {
+		rev, version string
+		ok           bool
+	}{
+		{"v1.0.1", "", true},
+		{"v1.0.0", "v1.0.0-rc.1", true}, // 指向同一个提交的其它标签
+		{"v1.0.1", "v1.5.0", false},
+		{"HEAD", "v1.0.1", false}, // 标签不指向该提交
+		{"HEAD", module.PseudoVersion("", "v1.0.1", ts, head[:12]), true},
+		{"HEAD", module.PseudoVersion("", "v1.0.1", ts.Add(time.Second), head[:12]), false},
+		{"HEAD", module.PseudoVersion("", "v1.3.0", ts, head[:12]), false},
+		{"HEAD", "v2.0.0", false},
+	}
+	for _, c := range cases {
+		if err := publish(c.rev, c.version); (err == nil) != c.ok {
+			t.Errorf("Publish(%s, %q) = %v, want ok %v", c.rev, c.version, err, c.ok)
+		}
+	}
+}
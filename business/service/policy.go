@@ -0,0 +1,101 @@
+package service
+
+import (
+	"archive/zip"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// UploadPolicy 上传策略，模块路径与版本号的一致性、主版本号后缀规则始终校验，其余规则按需开启。
+type UploadPolicy struct {
+	AllowedPrefixes []string // 允许的模块路径前缀，为空不限制
+	ForbidReplace   bool     // 禁止 replace 指令
+	ForbidExclude   bool     // 禁止 exclude 指令
+	RequireLicense  bool     // 必须包含 LICENSE 文件
+	MaxSize         int64    // zip 最大字节数，0 不限制
+}
+
+// Evaluate 校验待上传的模块 zip，返回全部违规项，没有违规返回 nil。
+func (up *UploadPolicy) Evaluate(mdv module.Version, zr *zip.Reader, size int64) errcode.Violations {
+	var vs errcode.Violations
+	add := func(rule, format string, args ...any) {
+		vs = append(vs, &errcode.Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if up == nil {
+		up = new(UploadPolicy)
+	}
+	if prefixes := up.AllowedPrefixes; len(prefixes) != 0 {
+		var allowed bool
+		for _, prefix := range prefixes {
+			// 按路径分隔符匹配，example.com/foo 不能匹配 example.com/foobar。
+			prefix = strings.TrimSuffix(prefix, "/")
+			if mdv.Path == prefix || strings.HasPrefix(mdv.Path, prefix+"/") {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			add("allowed_prefixes", "模块路径 %s 不在允许的前缀范围内：%s", mdv.Path, strings.Join(prefixes, ", "))
+		}
+	}
+	if up.MaxSize > 0 && size > up.MaxSize {
+		add("max_size", "模块大小 %d 字节超过了上限 %d 字节", size, up.MaxSize)
+	}
+	if err := module.Check(mdv.Path, mdv.Version); err != nil {
+		add("major_version", "模块路径与版本号不匹配：%s", err)
+	}
+
+	zdir := mdv.Path + "@" + mdv.Version + "/"
+	var gomod *zip.File
+	var license bool
+	for _, zf := range zr.File {
+		name := strings.TrimPrefix(zf.Name, zdir)
+		switch {
+		case name == "go.mod":
+			gomod = zf
+		case path.Dir(name) == "." && strings.HasPrefix(strings.ToUpper(name), "LICENSE"):
+			license = true
+		}
+	}
+	if up.RequireLicense && !license {
+		add("require_license", "模块根目录缺少 LICENSE 文件")
+	}
+
+	incompatible := strings.HasSuffix(mdv.Version, "+incompatible")
+	if gomod == nil {
+		if !incompatible && semver.Major(mdv.Version) != "v0" && semver.Major(mdv.Version) != "v1" {
+			add("major_version", "模块缺少 go.mod 文件，v2 及以上版本须以 +incompatible 结尾")
+		}
+		return vs
+	}
+	if incompatible {
+		add("major_version", "包含 go.mod 的模块版本号不能以 +incompatible 结尾")
+	}
+
+	// ParseLax 会忽略 replace 与 exclude 指令，这里需要完整解析。
+	mf, err := modfile.Parse(gomod.Name, dumpZip(gomod), nil)
+	if err != nil {
+		add("go_mod", "go.mod 解析错误：%s", err)
+		return vs
+	}
+	if mf.Module == nil {
+		add("module_path", "go.mod 缺少 module 声明")
+	} else if modpath := mf.Module.Mod.Path; modpath != mdv.Path {
+		add("module_path", "go.mod 声明的模块路径 %s 与上传的模块路径 %s 不一致", modpath, mdv.Path)
+	}
+	if up.ForbidReplace && len(mf.Replace) != 0 {
+		add("forbid_replace", "go.mod 中不允许出现 replace 指令（共 %d 处）", len(mf.Replace))
+	}
+	if up.ForbidExclude && len(mf.Exclude) != 0 {
+		add("forbid_exclude", "go.mod 中不允许出现 exclude 指令（共 %d 处）", len(mf.Exclude))
+	}
+
+	return vs
+}
@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"golang.org/x/mod/module"
+)
+
+func TestUploadPolicyEvaluate(t *testing.T) {
+	// newZip 生成模块 zip，files 的键为相对模块根目录的文件名。
+	newZip := func(mdv module.Version, files map[string]string) (*zip.Reader, int64) {
+		buf := new(bytes.Buffer)
+		zw := zip.NewWriter(buf)
+		for name, data := range files {
+			w, err := zw.Create(mdv.Path + "@" + mdv.Version + "/" + name)
+			if err == nil {
+				_, err = w.Write([]byte(data))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return zr, int64(buf.Len())
+	}
+	gomod := func(modpath string) map[string]string {
+		return map[string]string{"go.mod": "module " + modpath + "\n", "LICENSE": "MIT"}
+	}
+
+	cases := []struct {
+		name   string
+		policy *service.UploadPolicy
+		mdv    module.Version
+		files  map[string]string
+		want   []string // 违规的规则
+	}{
+		{"nil policy", nil, module.Version{Path: "example.com/foo", Version: "v1.0.0"}, gomod("example.com/foo"), nil},
+		{"prefix equal", &service.UploadPolicy{AllowedPrefixes: []string{"example.com/foo"}},
+			module.Version{Path: "example.com/foo", Version: "v1.0.0"}, gomod("example.com/foo"), nil},
+		{"prefix subpath", &service.UploadPolicy{AllowedPrefixes: []string{"example.com/foo/"}},
+			module.Version{Path: "example.com/foo/bar", Version: "v1.0.0"}, gomod("example.com/foo/bar"), nil},
+		{"prefix boundary", &service.UploadPolicy{AllowedPrefixes: []string{"example.com/foo"}},
+			module.Version{Path: "example.com/foobar", Version: "v1.0.0"}, gomod("example.com/foobar"), []string{"allowed_prefixes"}},
+		{"max size", &service.UploadPolicy{MaxSize: 1},
+			module.Version{Path: "example.com/foo", Version: "v1.0.0"}, gomod("example.com/foo"), []string{"max_size"}},
+		{"major suffix", nil, module.Version{Path: "example.com/foo", Version: "v2.0.0"}, gomod("example.com/foo"), []string{"major_version"}},
+		{"incompatible without go.mod", nil, module.Version{Path: "example.com/foo", Version: "v2.0.0+incompatible"}, map[string]string{"a.go": "package foo\n"}, nil},
+		{"v2 without go.mod", nil, module.Version{Path: "example.com/foo", Version: "v2.0.0"}, map[string]string{"a.go": "package foo\n"}, []string{"major_version", "major_version"}},
+		{"incompatible with go.mod", nil, module.Version{Path: "example.com/foo", Version: "v2.0.0+incompatible"}, gomod("example.com/foo"), []string{"major_version"}},
+		{"module path mismatch", nil, module.Version{Path: "example.com/foo", Version: "v1.0.0"}, gomod("example.com/bar"), []string{"module_path"}},
+		{"require license", &service.UploadPolicy{RequireLicense: true},
+			module.Version{Path: "example.com/foo", Version: "v1.0.0"}, map[string]string{"go.mod": "module example.com/foo\n"}, []string{"require_license"}},
+		{"forbid replace and exclude", &service.UploadPolicy{ForbidReplace: true, ForbidExclude: true},
+			module.Version{Path: "example.com/foo", Version: "v1.0.0"},
+			map[string]string{"go.mod": "module example.com/foo\n\nreplace example.com/a => ../a\n\nexclude example.com/b v1.0.0\n"},
+			[]string{"forbid_replace", "forbid_exclude"}},
+	}
+	for _, c := range cases {
+		zr, size := newZip(c.mdv, c.files)
+		var got []string
+		for _, v := range c.policy.Evaluate(c.mdv, zr, size) {
+			got = append(got, v.Rule)
+		}
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s: Evaluate = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,687 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/scim"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+	"gorm.io/gorm"
+)
+
+func NewSCIM(qry *query.Query, log *slog.Logger) *SCIM {
+	return &SCIM{
+		qry:      qry,
+		log:      log,
+		maxCount: 1000,
+	}
+}
+
+// SCIM 用户与用户组的 SCIM 2.0 同步。
+//
+// 用户以工号作为 id 与 userName，active=false 时会禁用用户并吊销其全部 PAT。
+// 删除用户时同样只是禁用并吊销 PAT，以保留该用户的历史数据。
+type SCIM struct {
+	qry      *query.Query
+	log      *slog.Logger
+	maxCount int
+}
+
+func (sc *SCIM) ListUsers(ctx context.Context, req *request.SCIMList) (*scim.ListResponse, error) {
+	filter, err := scim.ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := sc.qry.User.WithContext(ctx).Find()
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := sc.memberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*scim.User, 0, len(users))
+	for _, u := range users {
+		res := sc.userResource(u, memberships[u.JobNumber])
+		if filter.Match(func(name string) []string { return sc.userAttr(res, name) }) {
+			resources = append(resources, res)
+		}
+	}
+
+	return scimPaginate(resources, req, sc.maxCount), nil
+}
+
+func (sc *SCIM) GetUser(ctx context.Context, id string) (*scim.User, error) {
+	u, err := sc.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := sc.memberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.userResource(u, memberships[u.JobNumber]), nil
+}
+
+func (sc *SCIM) CreateUser(ctx context.Context, req *scim.User) (*scim.User, error) {
+	jobNumber := strings.TrimSpace(req.UserName)
+	if jobNumber == "" {
+		return nil, scim.NewError(http.StatusBadRequest, "invalidValue", "userName 不能为空")
+	}
+
+	tbl := sc.qry.User
+	dao := tbl.WithContext(ctx)
+	if cnt, _ := dao.Where(tbl.JobNumber.Eq(jobNumber)).Count(); cnt != 0 {
+		return nil, scim.NewError(http.StatusConflict, "uniqueness", "用户已存在："+jobNumber)
+	}
+
+	dat := &model.User{
+		JobNumber: jobNumber,
+		Name:      req.Fullname(),
+		Disabled:  req.Active != nil && !*req.Active,
+	}
+	if err := dao.Create(dat); err != nil {
+		return nil, err
+	}
+	sc.log.InfoContext(ctx, "SCIM 创建用户", slog.String("job_number", jobNumber))
+
+	return sc.userResource(dat, nil), nil
+}
+
+func (sc *SCIM) ReplaceUser(ctx context.Context, id string, req *scim.User) (*scim.User, error) {
+	u, err := sc.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Name = req.Fullname()
+	u.Disabled = req.Active != nil && !*req.Active
+	if err = sc.saveUser(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return sc.GetUser(ctx, id)
+}
+
+func (sc *SCIM) PatchUser(ctx context.Context, id string, req *scim.PatchOp) (*scim.User, error) {
+	u, err := sc.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range req.Operations {
+		if err = sc.patchUser(u, op); err != nil {
+			return nil, err
+		}
+	}
+	if err = sc.saveUser(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return sc.GetUser(ctx, id)
+}
+
+// DeleteUser 离职处理：禁用用户并吊销其全部 PAT。
+func (sc *SCIM) DeleteUser(ctx context.Context, id string) error {
+	u, err := sc.findUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.Disabled = true
+
+	return sc.saveUser(ctx, u)
+}
+
+func (sc *SCIM) ListGroups(ctx context.Context, req *request.SCIMList) (*scim.ListResponse, error) {
+	filter, err := scim.ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := sc.qry.Group.WithContext(ctx).Find()
+	if err != nil {
+		return nil, err
+	}
+	members, err := sc.members(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*scim.Group, 0, len(groups))
+	for _, g := range groups {
+		res := sc.groupResource(g, members[g.ID])
+		if filter.Match(func(name string) []string { return sc.groupAttr(res, name) }) {
+			resources = append(resources, res)
+		}
+	}
+
+	return scimPaginate(resources, req, sc.maxCount), nil
+}
+
+func (sc *SCIM) GetGroup(ctx context.Context, id string) (*scim.Group, error) {
+	g, err := sc.findGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := sc.members(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.groupResource(g, members[g.ID]), nil
+}
+
+func (sc *SCIM) CreateGroup(ctx context.Context, req *scim.Group) (*scim.Group, error) {
+	name := strings.TrimSpace(req.DisplayName)
+	if name == "" {
+		return nil, scim.NewError(http.StatusBadRequest, "invalidValue", "displayName 不能为空")
+	}
+
+	tbl := sc.qry.Group
+	if cnt, _ := tbl.WithContext(ctx).Where(tbl.Name.Eq(name)).Count(); cnt != 0 {
+		return nil, scim.NewError(http.StatusConflict, "uniqueness", "用户组已存在："+name)
+	}
+
+	dat := &model.Group{Name: name, ExternalID: req.ExternalID}
+	err := sc.qry.Transaction(func(tx *query.Query) error {
+		if err := tx.Group.WithContext(ctx).Create(dat); err != nil {
+			return err
+		}
+		return sc.replaceMembers(ctx, tx, dat.ID, req.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.GetGroup(ctx, strconv.FormatInt(dat.ID, 10))
+}
+
+func (sc *SCIM) ReplaceGroup(ctx context.Context, id string, req *scim.Group) (*scim.Group, error) {
+	g, err := sc.findGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.DisplayName); name != "" {
+		g.Name = name
+	}
+	g.ExternalID = req.ExternalID
+
+	err = sc.qry.Transaction(func(tx *query.Query) error {
+		if err := sc.saveGroup(ctx, tx, g); err != nil {
+			return err
+		}
+		return sc.replaceMembers(ctx, tx, g.ID, req.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.GetGroup(ctx, id)
+}
+
+func (sc *SCIM) PatchGroup(ctx context.Context, id string, req *scim.PatchOp) (*scim.Group, error) {
+	g, err := sc.findGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := sc.members(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := sc.groupResource(g, members[g.ID])
+
+	for _, op := range req.Operations {
+		if err = sc.patchGroup(res, op); err != nil {
+			return nil, err
+		}
+	}
+	g.Name, g.ExternalID = res.DisplayName, res.ExternalID
+
+	err = sc.qry.Transaction(func(tx *query.Query) error {
+		if err := sc.saveGroup(ctx, tx, g); err != nil {
+			return err
+		}
+		return sc.replaceMembers(ctx, tx, g.ID, res.Members)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.GetGroup(ctx, id)
+}
+
+func (sc *SCIM) DeleteGroup(ctx context.Context, id string) error {
+	g, err := sc.findGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return sc.qry.Transaction(func(tx *query.Query) error {
+		mem := tx.GroupMember
+		if _, err := mem.WithContext(ctx).Where(mem.GroupID.Eq(g.ID)).Delete(); err != nil {
+			return err
+		}
+		tbl := tx.Group
+		_, err := tbl.WithContext(ctx).Where(tbl.ID.Eq(g.ID)).Delete()
+		return err
+	})
+}
+
+func (sc *SCIM) findUser(ctx context.Context, id string) (*model.User, error) {
+	tbl := sc.qry.User
+	dao := tbl.WithContext(ctx)
+	u, err := dao.Where(tbl.JobNumber.Eq(id)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, scim.NewError(http.StatusNotFound, "", "用户不存在："+id)
+	}
+
+	return u, err
+}
+
+// saveUser 保存用户信息，如果用户被禁用，同时吊销其全部 PAT。
+func (sc *SCIM) saveUser(ctx context.Context, u *model.User) error {
+	return sc.qry.Transaction(func(tx *query.Query) error {
+		tbl := tx.User
+		if _, err := tbl.WithContext(ctx).
+			Where(tbl.JobNumber.Eq(u.JobNumber)).
+			UpdateColumnSimple(
+				tbl.Name.Value(u.Name),
+				tbl.Disabled.Value(u.Disabled),
+				tbl.UpdatedAt.Value(time.Now()),
+			); err != nil {
+			return err
+		}
+		if !u.Disabled {
+			return nil
+		}
+
+		tk := tx.AccessToken
+		ret, err := tk.WithContext(ctx).Where(tk.JobNumber.Eq(u.JobNumber)).Delete()
+		if err != nil {
+			return err
+		}
+		AuditDetail(ctx, "revoked_tokens", ret.RowsAffected)
+		sc.log.WarnContext(ctx, "SCIM 禁用用户并吊销 PAT",
+			slog.String("job_number", u.JobNumber), slog.Int64("revoked", ret.RowsAffected))
+
+		return nil
+	})
+}
+
+func (sc *SCIM) patchUser(u *model.User, op scim.Operation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+	case "remove":
+		// 只允许移除姓名，其它属性均为必需属性。
+		switch strings.ToLower(op.Path) {
+		case "displayname", "name", "name.formatted":
+			u.Name = ""
+			return nil
+		}
+		return scim.NewError(http.StatusBadRequest, "noTarget", "不支持移除属性："+op.Path)
+	default:
+		return scim.NewError(http.StatusBadRequest, "invalidSyntax", "不支持的操作："+op.Op)
+	}
+
+	// 没有 path 时 value 是一个包含若干属性的对象。
+	if op.Path == "" {
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return scim.NewError(http.StatusBadRequest, "invalidValue", "value 必须是对象")
+		}
+		for k, v := range attrs {
+			if err := sc.patchUser(u, scim.Operation{Op: op.Op, Path: k, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch strings.ToLower(op.Path) {
+	case "active":
+		active, err := patchBool(op.Value)
+		if err != nil {
+			return err
+		}
+		u.Disabled = !active
+	case "displayname", "name.formatted":
+		name, err := patchString(op.Value)
+		if err != nil {
+			return err
+		}
+		u.Name = name
+	case "name":
+		n := new(scim.Name)
+		if err := json.Unmarshal(op.Value, n); err != nil {
+			return scim.NewError(http.StatusBadRequest, "invalidValue", "name 格式错误")
+		}
+		u.Name = (&scim.User{Name: n}).Fullname()
+	case "username", "externalid", "name.givenname", "name.familyname":
+		// 工号不可修改，其余属性不保存，直接忽略。
+	default:
+		return scim.NewError(http.StatusBadRequest, "invalidPath", "不支持的属性："+op.Path)
+	}
+
+	return nil
+}
+
+func (sc *SCIM) userResource(u *model.User, groups []scim.Member) *scim.User {
+	active := !u.Disabled
+	return &scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          u.JobNumber,
+		UserName:    u.JobNumber,
+		Name:        &scim.Name{Formatted: u.Name},
+		DisplayName: u.Name,
+		Active:      &active,
+		Groups:      groups,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+		},
+	}
+}
+
+func (sc *SCIM) userAttr(u *scim.User, name string) []string {
+	switch name {
+	case "id", "username":
+		return []string{u.UserName}
+	case "displayname", "name.formatted":
+		return []string{u.DisplayName}
+	case "active":
+		return []string{strconv.FormatBool(*u.Active)}
+	case "groups.value", "groups":
+		ret := make([]string, 0, len(u.Groups))
+		for _, g := range u.Groups {
+			ret = append(ret, g.Value)
+		}
+		return ret
+	}
+
+	return nil
+}
+
+func (sc *SCIM) findGroup(ctx context.Context, id string) (*model.Group, error) {
+	gid, _ := strconv.ParseInt(id, 10, 64)
+	tbl := sc.qry.Group
+	g, err := tbl.WithContext(ctx).Where(tbl.ID.Eq(gid)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, scim.NewError(http.StatusNotFound, "", "用户组不存在："+id)
+	}
+
+	return g, err
+}
+
+func (sc *SCIM) saveGroup(ctx context.Context, tx *query.Query, g *model.Group) error {
+	tbl := tx.Group
+	_, err := tbl.WithContext(ctx).
+		Where(tbl.ID.Eq(g.ID)).
+		UpdateColumnSimple(
+			tbl.Name.Value(g.Name),
+			tbl.ExternalID.Value(g.ExternalID),
+			tbl.UpdatedAt.Value(time.Now()),
+		)
+
+	return err
+}
+
+// replaceMembers 全量替换用户组成员，系统中不存在的用户会被忽略。
+func (sc *SCIM) replaceMembers(ctx context.Context, tx *query.Query, groupID int64, members []scim.Member) error {
+	mem := tx.GroupMember
+	if _, err := mem.WithContext(ctx).Where(mem.GroupID.Eq(groupID)).Delete(); err != nil {
+		return err
+	}
+
+	jobNumbers := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.Value != "" && !slices.Contains(jobNumbers, m.Value) {
+			jobNumbers = append(jobNumbers, m.Value)
+		}
+	}
+	if len(jobNumbers) == 0 {
+		return nil
+	}
+
+	usr := tx.User
+	users, err := usr.WithContext(ctx).Where(usr.JobNumber.In(jobNumbers...)).Find()
+	if err != nil {
+		return err
+	}
+	dats := make([]*model.GroupMember, 0, len(users))
+	for _, u := range users {
+		dats = append(dats, &model.GroupMember{GroupID: groupID, JobNumber: u.JobNumber})
+	}
+	if len(dats) != len(jobNumbers) {
+		sc.log.WarnContext(ctx, "SCIM 用户组中包含不存在的用户", slog.Int64("group_id", groupID))
+	}
+	if len(dats) == 0 {
+		return nil
+	}
+
+	return mem.WithContext(ctx).Create(dats...)
+}
+
+func (sc *SCIM) patchGroup(g *scim.Group, op scim.Operation) error {
+	path := strings.ToLower(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		if path == "" {
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return scim.NewError(http.StatusBadRequest, "invalidValue", "value 必须是对象")
+			}
+			for k, v := range attrs {
+				if err := sc.patchGroup(g, scim.Operation{Op: op.Op, Path: k, Value: v}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		switch path {
+		case "displayname":
+			name, err := patchString(op.Value)
+			if err != nil {
+				return err
+			}
+			g.DisplayName = name
+		case "externalid":
+			id, err := patchString(op.Value)
+			if err != nil {
+				return err
+			}
+			g.ExternalID = id
+		case "members":
+			var members []scim.Member
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return scim.NewError(http.StatusBadRequest, "invalidValue", "members 格式错误")
+			}
+			if strings.EqualFold(op.Op, "replace") {
+				g.Members = nil
+			}
+			g.Members = append(g.Members, members...)
+		default:
+			return scim.NewError(http.StatusBadRequest, "invalidPath", "不支持的属性："+op.Path)
+		}
+	case "remove":
+		switch {
+		case path == "members":
+			// value 为空时移除全部成员，否则移除 value 中列出的成员。
+			var members []scim.Member
+			if len(op.Value) != 0 {
+				if err := json.Unmarshal(op.Value, &members); err != nil {
+					return scim.NewError(http.StatusBadRequest, "invalidValue", "members 格式错误")
+				}
+			}
+			if len(members) == 0 {
+				g.Members = nil
+				return nil
+			}
+			g.Members = slices.DeleteFunc(g.Members, func(m scim.Member) bool {
+				return slices.ContainsFunc(members, func(r scim.Member) bool { return r.Value == m.Value })
+			})
+		case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]"):
+			// 例如：members[value eq "200858"]
+			expr := op.Path[len("members[") : len(op.Path)-1]
+			filter, err := scim.ParseFilter(expr)
+			if err != nil {
+				return err
+			}
+			g.Members = slices.DeleteFunc(g.Members, func(m scim.Member) bool {
+				return filter.Match(func(name string) []string {
+					if name == "value" {
+						return []string{m.Value}
+					}
+					return nil
+				})
+			})
+		case path == "externalid":
+			g.ExternalID = ""
+		default:
+			return scim.NewError(http.StatusBadRequest, "noTarget", "不支持移除属性："+op.Path)
+		}
+	default:
+		return scim.NewError(http.StatusBadRequest, "invalidSyntax", "不支持的操作："+op.Op)
+	}
+
+	return nil
+}
+
+func (sc *SCIM) groupResource(g *model.Group, members []scim.Member) *scim.Group {
+	return &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.FormatInt(g.ID, 10),
+		ExternalID:  g.ExternalID,
+		DisplayName: g.Name,
+		Members:     members,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      g.CreatedAt,
+			LastModified: g.UpdatedAt,
+		},
+	}
+}
+
+func (sc *SCIM) groupAttr(g *scim.Group, name string) []string {
+	switch name {
+	case "id":
+		return []string{g.ID}
+	case "displayname":
+		return []string{g.DisplayName}
+	case "externalid":
+		if g.ExternalID == "" {
+			return nil
+		}
+		return []string{g.ExternalID}
+	case "members.value", "members":
+		ret := make([]string, 0, len(g.Members))
+		for _, m := range g.Members {
+			ret = append(ret, m.Value)
+		}
+		return ret
+	}
+
+	return nil
+}
+
+// memberships 按照工号索引每个用户所在的用户组。
+func (sc *SCIM) memberships(ctx context.Context) (map[string][]scim.Member, error) {
+	groups, err := sc.qry.Group.WithContext(ctx).Find()
+	if err != nil {
+		return nil, err
+	}
+	mems, err := sc.qry.GroupMember.WithContext(ctx).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(groups))
+	for _, g := range groups {
+		names[g.ID] = g.Name
+	}
+	ret := make(map[string][]scim.Member, len(mems))
+	for _, m := range mems {
+		gm := scim.Member{Value: strconv.FormatInt(m.GroupID, 10), Display: names[m.GroupID]}
+		ret[m.JobNumber] = append(ret[m.JobNumber], gm)
+	}
+
+	return ret, nil
+}
+
+// members 按照用户组 ID 索引组内成员。
+func (sc *SCIM) members(ctx context.Context) (map[int64][]scim.Member, error) {
+	mems, err := sc.qry.GroupMember.WithContext(ctx).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[int64][]scim.Member, 16)
+	for _, m := range mems {
+		ret[m.GroupID] = append(ret[m.GroupID], scim.Member{Value: m.JobNumber})
+	}
+
+	return ret, nil
+}
+
+// scimPaginate 分页，startIndex 从 1 开始。
+func scimPaginate[T any](resources []T, req *request.SCIMList, maxCount int) *scim.ListResponse {
+	total := len(resources)
+	start, count := req.StartIndex, req.Count
+	if start < 1 {
+		start = 1
+	}
+	if count <= 0 || count > maxCount {
+		count = maxCount
+	}
+
+	from := min(start-1, total)
+	to := min(from+count, total)
+	page := resources[from:to]
+
+	return &scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+func patchBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+
+	// 部分 IdP（例如 Azure AD）会将布尔值以字符串 "True"/"False" 发送。
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err = strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+
+	return false, scim.NewError(http.StatusBadRequest, "invalidValue", "active 必须是布尔值")
+}
+
+func patchString(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", scim.NewError(http.StatusBadRequest, "invalidValue", "属性值必须是字符串")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/response"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	modzip "golang.org/x/mod/zip"
+)
+
+// Sniff 探测压缩包中的模块信息，用于上传前预填模块路径与版本号。
+//
+// 模块 zip 根据 path@version/ 前缀识别，普通源码压缩包根据 go.mod 识别模块路径，
+// 并给出依赖、许可证、文件统计、嵌套模块以及根据仓库中已有版本建议的下一个版本号。
+func (gmd *Gomod) Sniff(mf multipart.File, size int64) (*response.GomodSniff, error) {
+	zr, cleanup, err := gmd.OpenArchive(mf, size)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	ret := &response.GomodSniff{Requires: []*response.GomodRequire{}, NestedModules: []string{}}
+	for _, zf := range zr.File {
+		name := zf.Name
+		if !strings.Contains(name, "@v") {
+			continue
+		}
+		before, after, _ := strings.Cut(name, "@")
+		after, _, _ = strings.Cut(after, "/")
+		if module.CheckPath(before) == nil &&
+			semver.IsValid(after) {
+			ret.Path = before
+			ret.Version = after
+			ret.Root = before + "@" + after + "/"
+			break
+		}
+	}
+	if ret.Path == "" {
+		if root, modpath, exx := gmd.moduleRoot(zr, ""); exx == nil {
+			ret.Root, ret.Path = root, modpath
+		}
+	}
+
+	var files []modzip.File
+	sizes := make(map[string]int64, len(zr.File))
+	for _, zf := range zr.File {
+		name, ok := strings.CutPrefix(zf.Name, ret.Root)
+		if !ok || zf.FileInfo().IsDir() {
+			continue
+		}
+		files = append(files, &zipFile{f: zf, name: name})
+		sizes[name] = int64(zf.UncompressedSize64)
+
+		dir, base := path.Split(name)
+		switch {
+		case name == "go.mod":
+			gmd.sniffGomod(ret, zf)
+		case base == "go.mod" && !strings.HasPrefix(dir, "vendor/") && !strings.Contains(dir, "/vendor/"):
+			nested := gmd.modulePath(zf)
+			if nested == "" {
+				nested = strings.TrimSuffix(dir, "/")
+			}
+			ret.NestedModules = append(ret.NestedModules, nested)
+		case dir == "" && ret.License == "" && isLicenseFile(base):
+			ret.License = detectLicense(dumpZip(zf))
+		}
+	}
+	cf, _ := modzip.CheckFiles(files)
+	for _, name := range cf.Valid {
+		ret.Files++
+		ret.Size += sizes[name]
+	}
+
+	if ret.Path != "" {
+		versions := gmd.storedVersions(ret.Path)
+		for _, ver := range versions {
+			if ver == ret.Version {
+				ret.Exists = true
+				break
+			}
+		}
+		ret.NextVersion = nextVersion(ret.Path, versions)
+	}
+
+	return ret, nil
+}
+
+func (gmd *Gomod) sniffGomod(ret *response.GomodSniff, zf *zip.File) {
+	mf, err := modfile.ParseLax(zf.Name, dumpZip(zf), nil)
+	if err != nil {
+		return
+	}
+	if mf.Module != nil && ret.Path == "" {
+		ret.Path = mf.Module.Mod.Path
+	}
+	if mf.Go != nil {
+		ret.GoVersion = mf.Go.Version
+	}
+	if mf.Toolchain != nil {
+		ret.Toolchain = mf.Toolchain.Name
+	}
+	for _, req := range mf.Require {
+		ret.Requires = append(ret.Requires, &response.GomodRequire{
+			Path:     req.Mod.Path,
+			Version:  req.Mod.Version,
+			Indirect: req.Indirect,
+		})
+	}
+}
+
+// storedVersions 读取仓库中该模块已有的版本号。
+func (gmd *Gomod) storedVersions(modpath string) []string {
+	escpath, err := module.EscapePath(modpath)
+	if err != nil {
+		return nil
+	}
+	file, err := os.Open(filepath.Join(gmd.dir, escpath, "@v", "list"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var versions []string
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		if ver, exx := module.UnescapeVersion(sc.Text()); exx == nil && semver.IsValid(ver) {
+			versions = append(versions, ver)
+		}
+	}
+
+	return versions
+}
+
+// nextVersion 建议下一个版本号：最新版本为预发布版本时建议对应的正式版本，否则递增修订号。
+//
+// 仓库中还没有任何版本时，根据模块路径的主版本号后缀建议 vN.0.0，没有后缀建议 v0.1.0。
+func nextVersion(modpath string, versions []string) string {
+	var latest string
+	for _, ver := range versions {
+		if module.IsPseudoVersion(ver) {
+			continue
+		}
+		if latest == "" || semver.Compare(ver, latest) > 0 {
+			latest = ver
+		}
+	}
+	if latest == "" {
+		_, major, _ := module.SplitPathVersion(modpath)
+		if major = strings.TrimLeft(major, "/."); major != "" && major != "v0" && major != "v1" {
+			return major + ".0.0"
+		}
+		return "v0.1.0"
+	}
+
+	build := semver.Build(latest)
+	canonical := strings.TrimSuffix(semver.Canonical(latest), build)
+	if pre := semver.Prerelease(canonical); pre != "" {
+		return strings.TrimSuffix(canonical, pre) + build
+	}
+	var major, minor, patch int
+	if _, err := fmt.Sscanf(canonical, "v%d.%d.%d", &major, &minor, &patch); err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("v%d.%d.%d%s", major, minor, patch+1, build)
+}
+
+func isLicenseFile(name string) bool {
+	upper := strings.ToUpper(name)
+	return strings.HasPrefix(upper, "LICENSE") || strings.HasPrefix(upper, "LICENCE") || strings.HasPrefix(upper, "COPYING")
+}
+
+// detectLicense 根据许可证文本中的特征语句识别常见的开源许可证，无法识别返回 unknown。
+func detectLicense(raw []byte) string {
+	text := strings.Join(strings.Fields(string(raw)), " ")
+	has := func(s string) bool { return strings.Contains(text, s) }
+	switch {
+	case has("Apache License") && has("Version 2.0"):
+		return "Apache-2.0"
+	case has("Mozilla Public License") && has("2.0"):
+		return "MPL-2.0"
+	case has("GNU AFFERO GENERAL PUBLIC LICENSE"):
+		return "AGPL-3.0"
+	case has("GNU LESSER GENERAL PUBLIC LICENSE"):
+		if has("Version 3") {
+			return "LGPL-3.0"
+		}
+		return "LGPL-2.1"
+	case has("GNU GENERAL PUBLIC LICENSE"):
+		if has("Version 3") {
+			return "GPL-3.0"
+		}
+		return "GPL-2.0"
+	case has("Redistribution and use in source and binary forms"):
+		if has("Neither the name") || has("names of its contributors") {
+			return "BSD-3-Clause"
+		}
+		return "BSD-2-Clause"
+	case has("Permission is hereby granted, free of charge"):
+		return "MIT"
+	case has("Permission to use, copy, modify, and/or distribute"):
+		return "ISC"
+	case has("This is free and unencumbered software released into the public domain"):
+		return "Unlicense"
+	}
+
+	return "unknown"
+}
@@ -9,18 +9,10 @@ import (
 	"github.com/dfcfw/goproxy/contract/request"
 	"github.com/dfcfw/goproxy/datalayer/model"
 	"github.com/dfcfw/goproxy/datalayer/query"
+	"gorm.io/gen/field"
 )
 
 func NewUser(qry *query.Query, log *slog.Logger) *User {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	tbl := qry.User
-	dao := tbl.WithContext(ctx)
-	if cnt, _ := dao.Count(); cnt == 0 {
-		_ = dao.Create(&model.User{JobNumber: "200858", Admin: true})
-	}
-
 	return &User{
 		qry: qry,
 		log: log,
@@ -39,7 +31,17 @@ func (usr *User) List(ctx context.Context) ([]*model.User, error) {
 	return dao.Find()
 }
 
+// Admins 查询所有管理员。
+func (usr *User) Admins(ctx context.Context) ([]*model.User, error) {
+	tbl := usr.qry.User
+	dao := tbl.WithContext(ctx)
+
+	return dao.Where(tbl.Admin.Is(true)).Find()
+}
+
 func (usr *User) Create(ctx context.Context, req *request.UserUpsert) error {
+	AuditTarget(ctx, req.JobNumber)
+	AuditDetail(ctx, "admin", req.Admin)
 	tbl := usr.qry.User
 	dao := tbl.WithContext(ctx)
 	dat := &model.User{
@@ -52,6 +54,8 @@ func (usr *User) Create(ctx context.Context, req *request.UserUpsert) error {
 }
 
 func (usr *User) Update(ctx context.Context, req *request.UserUpsert) error {
+	AuditTarget(ctx, req.JobNumber)
+	AuditDetail(ctx, "admin", req.Admin)
 	tbl := usr.qry.User
 	dao := tbl.WithContext(ctx)
 
@@ -59,6 +63,27 @@ func (usr *User) Update(ctx context.Context, req *request.UserUpsert) error {
 		UpdateColumnSimple(
 			tbl.Admin.Value(req.Admin),
 			tbl.Name.Value(req.Name),
+			tbl.UpdatedAt.Value(time.Now()),
+		)
+	if err != nil {
+		return err
+	} else if ret.RowsAffected == 0 {
+		return errcode.ErrDataNotExists
+	}
+
+	return nil
+}
+
+// Disable 禁用或启用用户，被禁用的用户无法登录，其 PAT 也会失效。
+func (usr *User) Disable(ctx context.Context, jobNumber string, disabled bool) error {
+	AuditTarget(ctx, jobNumber)
+	tbl := usr.qry.User
+	dao := tbl.WithContext(ctx)
+
+	ret, err := dao.Where(tbl.JobNumber.Eq(jobNumber)).
+		UpdateColumnSimple(
+			tbl.Disabled.Value(disabled),
+			tbl.UpdatedAt.Value(time.Now()),
 		)
 	if err != nil {
 		return err
@@ -70,6 +95,7 @@ func (usr *User) Update(ctx context.Context, req *request.UserUpsert) error {
 }
 
 func (usr *User) Delete(ctx context.Context, jobNumber string) error {
+	AuditTarget(ctx, jobNumber)
 	tbl := usr.qry.User
 	dao := tbl.WithContext(ctx)
 	ret, err := dao.Where(tbl.JobNumber.Eq(jobNumber)).Delete()
@@ -80,9 +106,67 @@ func (usr *User) Delete(ctx context.Context, jobNumber string) error {
 	}
 
 	tk := usr.qry.AccessToken
-	_, _ = tk.WithContext(ctx).
+	if ret, _ = tk.WithContext(ctx).
 		Where(tk.JobNumber.Eq(jobNumber)).
-		Delete()
+		Delete(); ret.Error == nil {
+		AuditDetail(ctx, "revoked_tokens", ret.RowsAffected)
+	}
+
+	return err
+}
+
+// Bootstrap 初始化管理员：只有当系统中没有任何管理员时，才会将 jobNumbers
+// 设置为管理员，已有管理员的情况下不做任何修改。
+func (usr *User) Bootstrap(ctx context.Context, jobNumbers []string) error {
+	if len(jobNumbers) == 0 {
+		return nil
+	}
+
+	return usr.qry.Transaction(func(tx *query.Query) error {
+		tbl := tx.User
+		dao := tbl.WithContext(ctx)
+		if cnt, err := dao.Where(tbl.Admin.Is(true)).Count(); err != nil || cnt != 0 {
+			return err
+		}
+
+		for _, jobNumber := range jobNumbers {
+			if jobNumber == "" {
+				continue
+			}
+			if err := usr.grant(ctx, tx, jobNumber, ""); err != nil {
+				return err
+			}
+			usr.log.Warn("初始化管理员", slog.String("job_number", jobNumber))
+		}
+
+		return nil
+	})
+}
+
+// Grant 将用户设置为管理员，用户不存在时会自动创建。
+func (usr *User) Grant(ctx context.Context, jobNumber, name string) error {
+	return usr.qry.Transaction(func(tx *query.Query) error {
+		return usr.grant(ctx, tx, jobNumber, name)
+	})
+}
+
+func (usr *User) grant(ctx context.Context, tx *query.Query, jobNumber, name string) error {
+	tbl := tx.User
+	dao := tbl.WithContext(ctx)
+	cnt, err := dao.Where(tbl.JobNumber.Eq(jobNumber)).Count()
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		dat := &model.User{JobNumber: jobNumber, Name: name, Admin: true}
+		return dao.Create(dat)
+	}
+
+	assigns := []field.AssignExpr{tbl.Admin.Value(true)}
+	if name != "" {
+		assigns = append(assigns, tbl.Name.Value(name))
+	}
+	_, err = dao.Where(tbl.JobNumber.Eq(jobNumber)).UpdateColumnSimple(assigns...)
 
 	return err
 }
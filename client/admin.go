@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+// Userinfo 当前认证的用户信息。
+type Userinfo struct {
+	JobNumber    string `json:"job_number"`
+	Admin        bool   `json:"admin,omitzero"`
+	Impersonator string `json:"impersonator,omitzero"`
+	TokenName    string `json:"token_name,omitzero"`
+}
+
+// Session 当前 PAT 对应的用户信息。
+func (c *Client) Session(ctx context.Context) (*Userinfo, error) {
+	ret := new(Userinfo)
+	if err := c.getJSON(ctx, "/api/session/info", nil, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Users 用户列表。
+func (c *Client) Users(ctx context.Context) ([]*model.User, error) {
+	var ret []*model.User
+	if err := c.getJSON(ctx, "/api/users", nil, &ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CreateUser 创建用户。
+func (c *Client) CreateUser(ctx context.Context, req *request.UserUpsert) error {
+	return c.sendJSON(ctx, http.MethodPost, "/api/user", nil, req, nil)
+}
+
+// UpdateUser 修改用户。
+func (c *Client) UpdateUser(ctx context.Context, req *request.UserUpsert) error {
+	return c.sendJSON(ctx, http.MethodPut, "/api/user", nil, req, nil)
+}
+
+// DeleteUser 删除用户。
+func (c *Client) DeleteUser(ctx context.Context, jobNumber string) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/api/user", url.Values{"job_number": {jobNumber}}, nil, nil)
+}
+
+// DisableUser 禁用用户。
+func (c *Client) DisableUser(ctx context.Context, jobNumber string) error {
+	return c.sendJSON(ctx, http.MethodPut, "/api/user/disable", url.Values{"job_number": {jobNumber}}, nil, nil)
+}
+
+// EnableUser 启用用户。
+func (c *Client) EnableUser(ctx context.Context, jobNumber string) error {
+	return c.sendJSON(ctx, http.MethodPut, "/api/user/enable", url.Values{"job_number": {jobNumber}}, nil, nil)
+}
+
+// AccessTokens 当前用户的 PAT 列表。
+func (c *Client) AccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	var ret []*model.AccessToken
+	if err := c.getJSON(ctx, "/api/access-tokens", nil, &ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// CreateAccessToken 创建 PAT，返回值中的 Token 只在创建时返回。
+func (c *Client) CreateAccessToken(ctx context.Context, req *request.AccessTokenCreate) (*model.AccessToken, error) {
+	ret := new(model.AccessToken)
+	if err := c.sendJSON(ctx, http.MethodPost, "/api/access-token", nil, req, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// DeleteAccessToken 删除 PAT。
+func (c *Client) DeleteAccessToken(ctx context.Context, name string) error {
+	return c.sendJSON(ctx, http.MethodDelete, "/api/access-token", url.Values{"name": {name}}, nil, nil)
+}
+
+// AccessTokenAvailable PAT 名字是否可用（未被占用）。
+func (c *Client) AccessTokenAvailable(ctx context.Context, name string) (bool, error) {
+	var ret struct {
+		Succeed bool `json:"succeed"`
+	}
+	if err := c.getJSON(ctx, "/api/access-token/valid", url.Values{"name": {name}}, &ret); err != nil {
+		return false, err
+	}
+
+	return ret.Succeed, nil
+}
+
+// AccessRequests 访问申请列表，status 为空时查询全部。
+func (c *Client) AccessRequests(ctx context.Context, status string) ([]*model.AccessRequest, error) {
+	query := make(url.Values, 1)
+	if status != "" {
+		query.Set("status", status)
+	}
+	var ret []*model.AccessRequest
+	if err := c.getJSON(ctx, "/api/access-requests", query, &ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ApproveAccessRequest 同意访问申请。
+func (c *Client) ApproveAccessRequest(ctx context.Context, req *request.AccessRequestReview) error {
+	return c.sendJSON(ctx, http.MethodPut, "/api/access-request/approve", nil, req, nil)
+}
+
+// DenyAccessRequest 拒绝访问申请。
+func (c *Client) DenyAccessRequest(ctx context.Context, req *request.AccessRequestReview) error {
+	return c.sendJSON(ctx, http.MethodPut, "/api/access-request/deny", nil, req, nil)
+}
+
+// AuditEvents 分页查询审计日志。
+func (c *Client) AuditEvents(ctx context.Context, req *request.AuditEventPage) (*response.Page[*model.AuditEvent], error) {
+	query := auditQuery(&req.AuditEventFilter)
+	if req.Page > 0 {
+		query.Set("page", strconv.FormatInt(req.Page, 10))
+	}
+	if req.Size > 0 {
+		query.Set("size", strconv.FormatInt(req.Size, 10))
+	}
+	ret := new(response.Page[*model.AuditEvent])
+	if err := c.getJSON(ctx, "/api/audit-events", query, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ExportAuditEvents 导出审计日志（JSON Lines）写入 w。
+func (c *Client) ExportAuditEvents(ctx context.Context, w io.Writer, req *request.AuditEventFilter) error {
+	res, err := c.do(ctx, http.MethodGet, "/api/audit-events/export", auditQuery(req), nil, nil)
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+
+	return err
+}
+
+func auditQuery(req *request.AuditEventFilter) url.Values {
+	query := make(url.Values, 8)
+	set := func(key, val string) {
+		if val != "" {
+			query.Set(key, val)
+		}
+	}
+	set("job_number", req.JobNumber)
+	set("action", req.Action)
+	set("outcome", req.Outcome)
+	set("target", req.Target)
+	if !req.From.IsZero() {
+		query.Set("from", req.From.Format(time.RFC3339))
+	}
+	if !req.To.IsZero() {
+		query.Set("to", req.To.Format(time.RFC3339))
+	}
+
+	return query
+}
@@ -0,0 +1,206 @@
+// Package client 管理接口（restapi）的 Go 客户端，便于运维脚本与命令行工具调用。
+//
+// 查询、上传与发布模块的接口接受 PAT，用户、访问申请与审计日志等管理员接口只接受管理员的 PAT。
+// PAT 管理接口不接受 PAT（避免 PAT 被用来签发新的 PAT），需要通过 SetBasicAuth 使用工号和密码认证。
+//
+// 需要浏览器会话的接口（模拟登录、CAS 认证的访问申请提交）、断点续传与 SCIM 接口不在此列。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/problem"
+)
+
+// NewClient 创建客户端，server 为服务地址，例如：https://goproxy.example.com，
+// cli 为空时使用 http.DefaultClient。
+func NewClient(server, token string, cli *http.Client) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(server, "/"))
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("服务地址无效: %s", server)
+	}
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+
+	return &Client{base: base, token: token, cli: cli, header: make(http.Header, 4)}, nil
+}
+
+type Client struct {
+	base   *url.URL
+	token  string
+	cli    *http.Client
+	header http.Header
+	user   string
+	passwd string
+}
+
+// SetBasicAuth 使用工号和密码认证，设置后不再携带 PAT。
+func (c *Client) SetBasicAuth(jobNumber, passwd string) {
+	c.user, c.passwd = jobNumber, passwd
+}
+
+// SetHeader 设置每次请求都携带的请求头，例如发布时的 CI 元数据 X-Ci-Commit-Sha。
+func (c *Client) SetHeader(key, value string) {
+	c.header.Set(key, value)
+}
+
+// Error 服务端返回的非 2xx 响应。
+type Error struct {
+	StatusCode int
+	Status     string
+	Problem    *problem.Details // 服务端返回的错误详情，响应不是 Problem Details 时为空
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	if pd := e.Problem; pd != nil && pd.Detail != "" {
+		return fmt.Sprintf("服务端响应 %s: %s", e.Status, pd.Detail)
+	}
+	if body := bytes.TrimSpace(e.Body); len(body) != 0 {
+		return fmt.Sprintf("服务端响应 %s: %s", e.Status, body)
+	}
+
+	return "服务端响应 " + e.Status
+}
+
+// StatusCode 错误对应的 HTTP 状态码，不是服务端响应的错误时返回 0。
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+
+	return 0
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应。
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, result any) error {
+	return c.sendJSON(ctx, http.MethodGet, path, query, nil, result)
+}
+
+// sendJSON 发送 JSON 请求体（body 为 nil 时不发送）并解析 JSON 响应，result 为 nil 时丢弃响应。
+func (c *Client) sendJSON(ctx context.Context, method, path string, query url.Values, body, result any) error {
+	var rd io.Reader
+	header := make(http.Header, 2)
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(raw)
+		header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	res, err := c.do(ctx, method, path, query, header, rd)
+	if err != nil {
+		return err
+	}
+
+	return decode(res, result)
+}
+
+// sendMultipart 以 multipart/form-data 流式上传文件与表单字段，并解析 JSON 响应。
+func (c *Client) sendMultipart(ctx context.Context, path string, fields map[string]string, file io.Reader, filename string, result any) error {
+	res, err := c.multipart(ctx, path, fields, file, filename)
+	if err != nil {
+		return err
+	}
+
+	return decode(res, result)
+}
+
+func (c *Client) multipart(ctx context.Context, path string, fields map[string]string, file io.Reader, filename string) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		var err error
+		for k, v := range fields {
+			if err = mw.WriteField(k, v); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			var fw io.Writer
+			if fw, err = mw.CreateFormFile("file", filename); err == nil {
+				_, err = io.Copy(fw, file)
+			}
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	header := make(http.Header, 2)
+	header.Set("Content-Type", mw.FormDataContentType())
+	res, err := c.do(ctx, http.MethodPut, path, nil, header, pr)
+	_ = pr.Close()
+
+	return res, err
+}
+
+// do 发送请求，非 2xx 响应转换为 *Error 返回。
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader) (*http.Response, error) {
+	u := c.base.JoinPath(path)
+	if len(query) != 0 {
+		u.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range c.header {
+		req.Header[k] = vs
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.passwd)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 {
+		return res, nil
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+
+	e := &Error{StatusCode: res.StatusCode, Status: res.Status}
+	e.Body, _ = io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	pd := new(problem.Details)
+	if json.Unmarshal(e.Body, pd) == nil && pd.Status != 0 {
+		e.Problem = pd
+	}
+
+	return nil, e
+}
+
+func decode(res *http.Response, result any) error {
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+	if result == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
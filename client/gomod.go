@@ -0,0 +1,274 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+// Walk 查看目录，path 为空时查看根目录。
+func (c *Client) Walk(ctx context.Context, path string) (*response.GomodWalk, error) {
+	query := make(url.Values, 1)
+	if path != "" {
+		query.Set("path", path)
+	}
+	ret := new(response.GomodWalk)
+	if err := c.getJSON(ctx, "/api/gomod/walk", query, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Stat 模块版本的文件列表与发布记录。
+func (c *Client) Stat(ctx context.Context, path, version string) (*response.GomodStat, error) {
+	query := url.Values{"path": {path}, "version": {version}}
+	ret := new(response.GomodStat)
+	if err := c.getJSON(ctx, "/api/gomod/stat", query, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// File 下载模块目录中的文件写入 w，name 为 @v 目录下的文件名，例如：v1.0.0.zip。
+func (c *Client) File(ctx context.Context, w io.Writer, path, name string) (int64, error) {
+	query := url.Values{"path": {path}, "name": {name}}
+	header := http.Header{"Accept": {"*/*"}}
+	res, err := c.do(ctx, http.MethodGet, "/api/gomod/file", query, header, nil)
+	if err != nil {
+		return 0, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+
+	return io.Copy(w, res.Body)
+}
+
+// Delete 删除模块版本，Version 为空时删除整个模块，此时 Confirm 必须为模块路径。仍有依赖方或下载记录时
+// 服务端返回 409，Error.Problem 中列出依赖方，Force 并填写 Reason 才能强制删除。
+func (c *Client) Delete(ctx context.Context, req *request.GomodDelete) error {
+	query := url.Values{"path": {req.Path}}
+	if req.Version != "" {
+		query.Set("version", req.Version)
+	}
+	if req.Confirm != "" {
+		query.Set("confirm", req.Confirm)
+	}
+	if req.Force {
+		query.Set("force", "true")
+		query.Set("reason", req.Reason)
+	}
+
+	return c.sendJSON(ctx, http.MethodDelete, "/api/gomod", query, nil, nil)
+}
+
+// Graph 仓库中模块版本的依赖图。
+func (c *Client) Graph(ctx context.Context, path, version string) (*response.GomodGraph, error) {
+	query := url.Values{"path": {path}, "version": {version}}
+	ret := new(response.GomodGraph)
+	if err := c.getJSON(ctx, "/api/gomod/graph", query, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Dependents 仓库中直接依赖了 path 的模块版本，version 不为空时只查找依赖此版本的。
+func (c *Client) Dependents(ctx context.Context, path, version string) (*response.GomodDependents, error) {
+	query := url.Values{"path": {path}}
+	if version != "" {
+		query.Set("version", version)
+	}
+	ret := new(response.GomodDependents)
+	if err := c.getJSON(ctx, "/api/gomod/dependents", query, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Trash 回收站中的记录，path 不为空时只查看该模块。
+func (c *Client) Trash(ctx context.Context, path string) ([]*model.GomodTrash, error) {
+	query := make(url.Values, 1)
+	if path != "" {
+		query.Set("path", path)
+	}
+	var ret []*model.GomodTrash
+	if err := c.getJSON(ctx, "/api/gomod/trash", query, &ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Restore 从回收站恢复，仓库中已经重新发布了同名版本时服务端返回 409。
+func (c *Client) Restore(ctx context.Context, id int64) (*model.GomodTrash, error) {
+	req := &request.GomodTrashRestore{ID: id}
+	ret := new(model.GomodTrash)
+	if err := c.sendJSON(ctx, http.MethodPut, "/api/gomod/trash/restore", nil, req, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Sniff 探测压缩包中的模块信息。
+func (c *Client) Sniff(ctx context.Context, file io.Reader, filename string) (*response.GomodSniff, error) {
+	ret := new(response.GomodSniff)
+	if err := c.sendMultipart(ctx, "/api/gomod/sniff", nil, file, filename, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Upload 上传模块 zip 发布版本，dryRun 时只校验并计算哈希。
+func (c *Client) Upload(ctx context.Context, file io.Reader, path, version string, dryRun bool) (*response.GomodUpload, error) {
+	fields := map[string]string{
+		"path":    path,
+		"version": version,
+		"dry_run": strconv.FormatBool(dryRun),
+	}
+	ret := new(response.GomodUpload)
+	if err := c.sendMultipart(ctx, "/api/gomod/upload", fields, file, "go.src.zip", ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// UploadRepo 上传多模块仓库压缩包，按标签发布其中的各个模块。
+func (c *Client) UploadRepo(ctx context.Context, file io.Reader, filename string, tags []string, dryRun bool) (*response.GomodRepo, error) {
+	fields := map[string]string{
+		"tags":    strings.Join(tags, ","),
+		"dry_run": strconv.FormatBool(dryRun),
+	}
+	ret := new(response.GomodRepo)
+	if err := c.sendMultipart(ctx, "/api/gomod/upload-repo", fields, file, filename, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// PublishVCS 从服务器本地的 git 仓库发布模块。
+func (c *Client) PublishVCS(ctx context.Context, req *request.GomodVCSPublish) (*response.GomodUpload, error) {
+	ret := new(response.GomodUpload)
+	if err := c.sendJSON(ctx, http.MethodPut, "/api/gomod/publish-vcs", nil, req, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Formatted 格式转换的结果，来自响应头。
+type Formatted struct {
+	Path    string `json:"path"`    // 模块路径
+	Root    string `json:"root"`    // 剥离的目录前缀
+	Omitted int    `json:"omitted"` // 被排除的文件个数
+}
+
+// Format 将任意压缩包转换为标准的模块 zip 写入 w，path 为空时根据 go.mod 自动检测。
+func (c *Client) Format(ctx context.Context, w io.Writer, file io.Reader, filename, path, version string) (*Formatted, error) {
+	fields := map[string]string{"version": version}
+	if path != "" {
+		fields["path"] = path
+	}
+	res, err := c.multipart(ctx, "/api/gomod/format", fields, file, filename)
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+
+	ret := &Formatted{
+		Path: res.Header.Get("X-Gomod-Path"),
+		Root: res.Header.Get("X-Gomod-Root"),
+	}
+	ret.Omitted, _ = strconv.Atoi(res.Header.Get("X-Gomod-Omitted"))
+	if _, err = io.Copy(w, res.Body); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// FormatPublish 格式转换后直接发布，dryRun 时只校验并计算哈希。
+func (c *Client) FormatPublish(ctx context.Context, file io.Reader, filename, path, version string, dryRun bool) (*response.GomodUpload, error) {
+	fields := map[string]string{
+		"version": version,
+		"publish": "true",
+		"dry_run": strconv.FormatBool(dryRun),
+	}
+	if path != "" {
+		fields["path"] = path
+	}
+	ret := new(response.GomodUpload)
+	if err := c.sendMultipart(ctx, "/api/gomod/format", fields, file, filename, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// BundlePlan 以仓库中的模块版本为根节点计算离线依赖包的依赖清单。
+func (c *Client) BundlePlan(ctx context.Context, path, version string) (*response.GomodBundle, error) {
+	query := url.Values{"path": {path}, "version": {version}, "plan": {"true"}}
+	ret := new(response.GomodBundle)
+	if err := c.getJSON(ctx, "/api/gomod/bundle", query, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Bundle 以仓库中的模块版本为根节点导出离线依赖包写入 w，返回仓库中缺失的依赖个数。
+func (c *Client) Bundle(ctx context.Context, w io.Writer, path, version string) (int, error) {
+	query := url.Values{"path": {path}, "version": {version}}
+	header := http.Header{"Accept": {"*/*"}}
+	res, err := c.do(ctx, http.MethodGet, "/api/gomod/bundle", query, header, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return copyBundle(w, res)
+}
+
+// BundleGomodPlan 以 go.mod 文件为根节点计算离线依赖包的依赖清单。
+func (c *Client) BundleGomodPlan(ctx context.Context, gomod io.Reader) (*response.GomodBundle, error) {
+	ret := new(response.GomodBundle)
+	fields := map[string]string{"plan": "true"}
+	if err := c.sendMultipart(ctx, "/api/gomod/bundle", fields, gomod, "go.mod", ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// BundleGomod 以 go.mod 文件为根节点导出离线依赖包写入 w，返回仓库中缺失的依赖个数。
+func (c *Client) BundleGomod(ctx context.Context, w io.Writer, gomod io.Reader) (int, error) {
+	res, err := c.multipart(ctx, "/api/gomod/bundle", nil, gomod, "go.mod")
+	if err != nil {
+		return 0, err
+	}
+
+	return copyBundle(w, res)
+}
+
+func copyBundle(w io.Writer, res *http.Response) (int, error) {
+	//goland:noinspection GoUnhandledErrorResult
+	defer res.Body.Close()
+
+	missing, _ := strconv.Atoi(res.Header.Get("X-Gomod-Missing"))
+	_, err := io.Copy(w, res.Body)
+
+	return missing, err
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dfcfw/goproxy/contract/request"
+)
+
+func whoami(c *command, set *flag.FlagSet, args []string) error {
+	if err := c.parse(set, args, 0, 0); err != nil {
+		return err
+	}
+	ret, err := c.cli.Session(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.print(ret, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintf(tw, "工号:\t%s\n管理员:\t%t\n", ret.JobNumber, ret.Admin)
+		if ret.TokenName != "" {
+			_, _ = fmt.Fprintf(tw, "PAT:\t%s\n", ret.TokenName)
+		}
+		if ret.Impersonator != "" {
+			_, _ = fmt.Fprintf(tw, "模拟登录:\t%s\n", ret.Impersonator)
+		}
+	})
+}
+
+var userCommands = map[string]*subcommand{
+	"list": {usage: "", brief: "查看用户列表", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 0, 0); err != nil {
+			return err
+		}
+		ret, err := c.cli.Users(c.ctx)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "工号\t名字\t管理员\t禁用\t最近登录")
+			for _, u := range ret {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\n", u.JobNumber, u.Name, u.Admin, u.Disabled, timeText(u.LastLoginAt))
+			}
+		})
+	}},
+	"create": {usage: "[-admin] 工号 名字", brief: "创建用户", run: func(c *command, set *flag.FlagSet, args []string) error {
+		admin := set.Bool("admin", false, "是否管理员")
+		if err := c.parse(set, args, 2, 2); err != nil {
+			return err
+		}
+		req := &request.UserUpsert{JobNumber: set.Arg(0), Name: set.Arg(1), Admin: *admin}
+		if err := c.cli.CreateUser(c.ctx, req); err != nil {
+			return err
+		}
+		return c.done("创建成功")
+	}},
+	"update": {usage: "[-admin] 工号 名字", brief: "修改用户", run: func(c *command, set *flag.FlagSet, args []string) error {
+		admin := set.Bool("admin", false, "是否管理员")
+		if err := c.parse(set, args, 2, 2); err != nil {
+			return err
+		}
+		req := &request.UserUpsert{JobNumber: set.Arg(0), Name: set.Arg(1), Admin: *admin}
+		if err := c.cli.UpdateUser(c.ctx, req); err != nil {
+			return err
+		}
+		return c.done("修改成功")
+	}},
+	"delete": {usage: "工号", brief: "删除用户", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		if err := c.cli.DeleteUser(c.ctx, set.Arg(0)); err != nil {
+			return err
+		}
+		return c.done("删除成功")
+	}},
+	"disable": {usage: "工号", brief: "禁用用户", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		if err := c.cli.DisableUser(c.ctx, set.Arg(0)); err != nil {
+			return err
+		}
+		return c.done("已禁用")
+	}},
+	"enable": {usage: "工号", brief: "启用用户", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		if err := c.cli.EnableUser(c.ctx, set.Arg(0)); err != nil {
+			return err
+		}
+		return c.done("已启用")
+	}},
+}
+
+var tokenCommands = map[string]*subcommand{
+	"list": {usage: "", brief: "查看自己的 PAT 列表", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 0, 0); err != nil {
+			return err
+		}
+		ret, err := c.cli.AccessTokens(c.ctx)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "名字\t过期时间")
+			for _, t := range ret {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\n", t.Name, timeText(t.ExpiredAt))
+			}
+		})
+	}},
+	"create": {usage: "[-expire 有效期] 名字", brief: "创建 PAT，只在创建时输出 Token", run: func(c *command, set *flag.FlagSet, args []string) error {
+		expire := set.Duration("expire", 0, "有效期，例如：720h，不填写表示永不过期")
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		req := &request.AccessTokenCreate{Name: set.Arg(0)}
+		if *expire > 0 {
+			req.ExpiredAt = time.Now().Add(*expire)
+		}
+		ret, err := c.cli.CreateAccessToken(c.ctx, req)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, ret.Token)
+		})
+	}},
+	"delete": {usage: "名字", brief: "删除 PAT", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		if err := c.cli.DeleteAccessToken(c.ctx, set.Arg(0)); err != nil {
+			return err
+		}
+		return c.done("删除成功")
+	}},
+	"check": {usage: "名字", brief: "检查 PAT 名字是否可用，已被占用时退出码为 3", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		ok, err := c.cli.AccessTokenAvailable(c.ctx, set.Arg(0))
+		if err != nil {
+			return err
+		}
+		if err = c.print(map[string]bool{"succeed": ok}, func(tw *tabwriter.Writer) {
+			if ok {
+				_, _ = fmt.Fprintln(tw, "名字可用")
+			} else {
+				_, _ = fmt.Fprintln(tw, "名字已被占用")
+			}
+		}); err != nil || ok {
+			return err
+		}
+		return exitStatus(exitConflict)
+	}},
+}
+
+var requestCommands = map[string]*subcommand{
+	"list": {usage: "[-status pending|approved|denied]", brief: "查看访问申请列表", run: func(c *command, set *flag.FlagSet, args []string) error {
+		status := set.String("status", "", "申请状态，不填写查询全部")
+		if err := c.parse(set, args, 0, 0); err != nil {
+			return err
+		}
+		ret, err := c.cli.AccessRequests(c.ctx, *status)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "ID\t工号\t名字\t状态\t理由\t申请时间\t审批人")
+			for _, r := range ret {
+				_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.JobNumber, r.Name, r.Status, r.Reason, timeText(r.CreatedAt), r.Reviewer)
+			}
+		})
+	}},
+	"approve": {usage: "[-remark 审批意见] ID", brief: "同意访问申请", run: func(c *command, set *flag.FlagSet, args []string) error {
+		return review(c, set, args, true)
+	}},
+	"deny": {usage: "[-remark 审批意见] ID", brief: "拒绝访问申请", run: func(c *command, set *flag.FlagSet, args []string) error {
+		return review(c, set, args, false)
+	}},
+}
+
+func review(c *command, set *flag.FlagSet, args []string, approve bool) error {
+	remark := set.String("remark", "", "审批意见")
+	if err := c.parse(set, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(set.Arg(0), 10, 64)
+	if err != nil {
+		set.Usage()
+		return errUsage
+	}
+
+	req := &request.AccessRequestReview{ID: id, Remark: *remark}
+	if !approve {
+		if err = c.cli.DenyAccessRequest(c.ctx, req); err != nil {
+			return err
+		}
+		return c.done("已拒绝")
+	}
+	if err = c.cli.ApproveAccessRequest(c.ctx, req); err != nil {
+		return err
+	}
+
+	return c.done("已同意")
+}
+
+var auditCommands = map[string]*subcommand{
+	"list": {usage: "[-page 页码] [-size 条数] [过滤条件]", brief: "分页查看审计日志", run: func(c *command, set *flag.FlagSet, args []string) error {
+		req := new(request.AuditEventPage)
+		set.Int64Var(&req.Page, "page", 1, "页码")
+		set.Int64Var(&req.Size, "size", 20, "每页条数")
+		from, to := auditFilter(set, &req.AuditEventFilter)
+		if err := c.parse(set, args, 0, 0); err != nil {
+			return err
+		}
+		if err := auditTime(req, *from, *to); err != nil {
+			set.Usage()
+			return errUsage
+		}
+		ret, err := c.cli.AuditEvents(c.ctx, req)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "时间\t工号\t操作\t对象\t结果\t状态码")
+			for _, e := range ret.Records {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", timeText(e.CreatedAt), e.JobNumber, e.Action, e.Target, e.Outcome, e.StatusCode)
+			}
+			_, _ = fmt.Fprintf(tw, "共 %d 条\n", ret.Total)
+		})
+	}},
+	"export": {usage: "[过滤条件]", brief: "导出审计日志（JSON Lines）到标准输出", run: func(c *command, set *flag.FlagSet, args []string) error {
+		req := new(request.AuditEventPage)
+		from, to := auditFilter(set, &req.AuditEventFilter)
+		if err := c.parse(set, args, 0, 0); err != nil {
+			return err
+		}
+		if err := auditTime(req, *from, *to); err != nil {
+			set.Usage()
+			return errUsage
+		}
+		return c.cli.ExportAuditEvents(c.ctx, os.Stdout, &req.AuditEventFilter)
+	}},
+}
+
+func auditFilter(set *flag.FlagSet, req *request.AuditEventFilter) (from, to *string) {
+	set.StringVar(&req.JobNumber, "job", "", "操作人工号")
+	set.StringVar(&req.Action, "action", "", "操作名称")
+	set.StringVar(&req.Outcome, "outcome", "", "操作结果：succeed 或 failed")
+	set.StringVar(&req.Target, "target", "", "操作对象（模糊匹配）")
+	from = set.String("from", "", "开始时间，例如：2006-01-02 或 2006-01-02T15:04:05+08:00")
+	to = set.String("to", "", "结束时间，格式同 -from")
+
+	return from, to
+}
+
+func auditTime(req *request.AuditEventPage, from, to string) error {
+	var err error
+	if req.From, err = parseTime(from); err != nil {
+		return err
+	}
+	req.To, err = parseTime(to)
+
+	return err
+}
+
+// parseTime 解析 RFC3339 或者本地时区的日期，为空时返回零值。
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "时间格式错误: %s\n", s)
+	}
+
+	return t, err
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dfcfw/goproxy/client"
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/library/netrc"
+)
+
+// 退出码，便于脚本根据失败原因分别处理。
+const (
+	exitOK       = 0
+	exitFailed   = 1 // 网络错误或服务端返回其它错误
+	exitUsage    = 2 // 参数错误
+	exitConflict = 3 // 服务端响应 409
+	exitNotFound = 4 // 服务端响应 404
+	exitAuth     = 5 // 服务端响应 401 或 403，PAT 无效或者权限不足
+)
+
+func main() {
+	os.Exit(run(filepath.Base(os.Args[0]), os.Args[1:]))
+}
+
+func run(name string, args []string) int {
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+	server := set.String("s", os.Getenv("GOPROXYCTL_SERVER"), "服务地址，默认读取 GOPROXYCTL_SERVER 环境变量")
+	token := set.String("t", "", "PAT，默认读取 GOPROXYCTL_TOKEN 环境变量或 .netrc")
+	user := set.String("u", os.Getenv("GOPROXYCTL_USER"), "使用工号和密码认证，密码读取 GOPROXYCTL_PASSWORD 环境变量，只有 token 命令需要")
+	output := set.String("o", "table", "输出格式：table 或 json")
+	timeout := set.Duration("timeout", 10*time.Minute, "请求超时时间")
+	set.Usage = func() { usage(set) }
+	if err := set.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *output != "table" && *output != "json" {
+		_, _ = fmt.Fprintf(os.Stderr, "不支持的输出格式: %s\n", *output)
+		return exitUsage
+	}
+	if set.NArg() == 0 {
+		usage(set)
+		return exitUsage
+	}
+
+	group, ok := groups[set.Arg(0)]
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "未知命令: %s\n", set.Arg(0))
+		usage(set)
+		return exitUsage
+	}
+	rest := set.Args()[1:]
+	cmd, ok := group[""]
+	if !ok {
+		if len(rest) != 0 {
+			cmd, ok = group[rest[0]]
+			rest = rest[1:]
+		}
+		if !ok {
+			_, _ = fmt.Fprintf(os.Stderr, "用法: %s %s <子命令>，可用的子命令:\n", name, set.Arg(0))
+			tw := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+			printGroup(tw, set.Arg(0), group)
+			_ = tw.Flush()
+			return exitUsage
+		}
+	}
+
+	cli, err := newClient(*server, *token, *user, *timeout)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	signals := []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT}
+	ctx, cancel := signal.NotifyContext(context.Background(), signals...)
+	defer cancel()
+
+	full := strings.TrimSpace(name + " " + strings.Join(set.Args()[:len(set.Args())-len(rest)], " "))
+	env := &command{ctx: ctx, cli: cli, json: *output == "json"}
+	sub := flag.NewFlagSet(full, flag.ContinueOnError)
+	sub.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: %s %s\n", full, cmd.usage)
+		sub.PrintDefaults()
+	}
+	if err = cmd.run(env, sub, rest); err != nil {
+		return exitCode(err)
+	}
+
+	return exitOK
+}
+
+func newClient(server, token, user string, timeout time.Duration) (*client.Client, error) {
+	if server == "" {
+		return nil, errors.New("缺少服务地址，请通过 -s 或 GOPROXYCTL_SERVER 提供")
+	}
+	if user != "" {
+		passwd := os.Getenv("GOPROXYCTL_PASSWORD")
+		if passwd == "" {
+			return nil, errors.New("缺少密码，请通过 GOPROXYCTL_PASSWORD 提供")
+		}
+		cli, err := client.NewClient(server, "", &http.Client{Timeout: timeout})
+		if err != nil {
+			return nil, err
+		}
+		cli.SetBasicAuth(user, passwd)
+
+		return cli, nil
+	}
+	if token == "" {
+		token = os.Getenv("GOPROXYCTL_TOKEN")
+	}
+	if token == "" {
+		if u, _ := url.Parse(server); u != nil {
+			token = netrc.Login(u.Hostname())
+		}
+	}
+	if token == "" {
+		return nil, errors.New("缺少 PAT，请通过 -t、GOPROXYCTL_TOKEN 或 .netrc 提供")
+	}
+
+	return client.NewClient(server, token, &http.Client{Timeout: timeout})
+}
+
+// errUsage 参数错误，用法已经输出。
+var errUsage = errors.New("参数错误")
+
+// exitStatus 以指定的退出码结束，提示信息已经输出。
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return "exit status " + strconv.Itoa(int(e))
+}
+
+// exitCode 输出错误信息并返回对应的退出码。
+func exitCode(err error) int {
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		return exitUsage
+	}
+	var status exitStatus
+	if errors.As(err, &status) {
+		return int(status)
+	}
+	_, _ = fmt.Fprintln(os.Stderr, err)
+
+	var e *client.Error
+	if !errors.As(err, &e) {
+		return exitFailed
+	}
+	if pd := e.Problem; pd != nil && pd.Errors != nil {
+		printErrors(pd.Errors)
+	}
+
+	switch e.StatusCode {
+	case http.StatusConflict:
+		return exitConflict
+	case http.StatusNotFound, http.StatusGone:
+		return exitNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return exitAuth
+	default:
+		return exitFailed
+	}
+}
+
+// printErrors 输出结构化的错误详情：违反的上传策略、阻止删除的依赖方逐条输出，其它详情按 JSON 输出。
+func printErrors(details any) {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return
+	}
+	var violations []struct {
+		Rule    string `json:"rule"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &violations) == nil && len(violations) != 0 && violations[0].Rule != "" {
+		for _, v := range violations {
+			_, _ = fmt.Fprintf(os.Stderr, "  [%s] %s\n", v.Rule, v.Message)
+		}
+		return
+	}
+	blocked := new(response.GomodDeleteBlocked)
+	if json.Unmarshal(raw, blocked) == nil && blocked.Target != "" {
+		tw := tabwriter.NewWriter(os.Stderr, 2, 4, 2, ' ', 0)
+		for _, d := range blocked.Dependents {
+			_, _ = fmt.Fprintf(tw, "  依赖方\t%s@%s\t%s@%s\n", d.Path, d.Version, d.Module, d.Requires)
+		}
+		for _, d := range blocked.Consumers {
+			_, _ = fmt.Fprintf(tw, "  下载\t%s@%s\t%s %s\t%d 次\t%s\n", d.Path, d.Version, d.JobNumber, d.TokenName, d.Times, timeText(d.UpdatedAt))
+		}
+		_ = tw.Flush()
+		return
+	}
+	raw, _ = json.MarshalIndent(details, "", "  ")
+	_, _ = fmt.Fprintf(os.Stderr, "%s\n", raw)
+}
+
+// command 子命令的执行环境。
+type command struct {
+	ctx  context.Context
+	cli  *client.Client
+	json bool
+}
+
+// parse 解析子命令参数，位置参数个数不在 [least, most] 范围内时输出用法，most 小于 0 表示不限制。
+func (c *command) parse(set *flag.FlagSet, args []string, least, most int) error {
+	if err := set.Parse(args); err != nil {
+		return errUsage
+	}
+	if n := set.NArg(); n < least || (most >= 0 && n > most) {
+		set.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// print 输出结果，JSON 格式直接输出 v，表格格式调用 table。
+func (c *command) print(v any, table func(tw *tabwriter.Writer)) error {
+	if c.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	if table == nil {
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	table(tw)
+
+	return tw.Flush()
+}
+
+// done 无返回数据的操作成功后输出提示。
+func (c *command) done(msg string) error {
+	return c.print(map[string]bool{"succeed": true}, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(tw, msg)
+	})
+}
+
+type subcommand struct {
+	usage string
+	brief string
+	run   func(c *command, set *flag.FlagSet, args []string) error
+}
+
+// groups 命令组及其子命令，子命令名为空表示该命令没有子命令。
+var groups = map[string]map[string]*subcommand{
+	"whoami":  {"": {usage: "", brief: "查看当前认证的用户", run: whoami}},
+	"user":    userCommands,
+	"token":   tokenCommands,
+	"request": requestCommands,
+	"audit":   auditCommands,
+	"mod":     modCommands,
+}
+
+func usage(set *flag.FlagSet) {
+	name := set.Name()
+	_, _ = fmt.Fprintf(os.Stderr, "用法: %s [-s 服务地址] [-t PAT | -u 工号] [-o table|json] <命令> [子命令] [参数]\n\n", name)
+	_, _ = fmt.Fprintln(os.Stderr, "命令:")
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tw := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	for _, key := range keys {
+		printGroup(tw, key, groups[key])
+	}
+	_ = tw.Flush()
+	_, _ = fmt.Fprintln(os.Stderr, "\n管理员命令需要管理员的 PAT；服务端不允许使用 PAT 管理 PAT，token 命令需要通过 -u 使用工号和密码认证。")
+	_, _ = fmt.Fprintf(os.Stderr, "\n退出码: 0 成功，1 失败，2 参数错误，3 冲突，4 不存在，5 认证失败或权限不足\n\n全局参数:\n")
+	set.PrintDefaults()
+}
+
+func printGroup(tw *tabwriter.Writer, key string, group map[string]*subcommand) {
+	names := make([]string, 0, len(group))
+	for name := range group {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sub := group[name]
+		line := strings.Join(strings.Fields(key+" "+name+" "+sub.usage), " ")
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", line, sub.brief)
+	}
+}
+
+// timeText 表格中的时间，零值显示为 -。
+func timeText(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Local().Format(time.DateTime)
+}
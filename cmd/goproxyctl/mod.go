@@ -0,0 +1,378 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+)
+
+var modCommands = map[string]*subcommand{
+	"walk": {usage: "[目录]", brief: "查看目录下的子目录与模块版本", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 0, 1); err != nil {
+			return err
+		}
+		ret, err := c.cli.Walk(c.ctx, set.Arg(0))
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			for _, p := range ret.Paths {
+				_, _ = fmt.Fprintf(tw, "%s/\n", p.Path)
+			}
+			for _, m := range ret.Modules {
+				_, _ = fmt.Fprintf(tw, "%s\n", m.Version)
+			}
+		})
+	}},
+	"stat": {usage: "模块 版本", brief: "查看模块版本的文件与发布记录", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 2, 2); err != nil {
+			return err
+		}
+		ret, err := c.cli.Stat(c.ctx, set.Arg(0), set.Arg(1))
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "文件\t大小\t修改时间")
+			for _, f := range ret.Files {
+				_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", f.Name, f.Size, timeText(f.ModifiedAt))
+			}
+			if pub := ret.Publisher; pub != nil {
+				_, _ = fmt.Fprintf(tw, "\n发布人:\t%s\n发布时间:\t%s\n哈希:\t%s\n", pub.JobNumber, timeText(pub.CreatedAt), pub.Hash)
+				if pub.Repository != "" {
+					_, _ = fmt.Fprintf(tw, "代码仓库:\t%s\n", pub.Repository)
+				}
+				if pub.CommitSHA != "" {
+					_, _ = fmt.Fprintf(tw, "提交:\t%s\n", pub.CommitSHA)
+				}
+			}
+		})
+	}},
+	"get": {usage: "[-f 输出文件] 模块 文件名", brief: "下载模块文件，例如 v1.0.0.zip，-f - 输出到标准输出", run: func(c *command, set *flag.FlagSet, args []string) error {
+		output := set.String("f", "", "输出文件，默认与文件名相同")
+		if err := c.parse(set, args, 2, 2); err != nil {
+			return err
+		}
+		name := set.Arg(1)
+		if *output == "-" {
+			_, err := c.cli.File(c.ctx, os.Stdout, set.Arg(0), name)
+			return err
+		}
+		if *output == "" {
+			*output = filepath.Base(name)
+		}
+		return download(*output, func(w io.Writer) error {
+			_, err := c.cli.File(c.ctx, w, set.Arg(0), name)
+			return err
+		})
+	}},
+	"delete": {usage: "[-all] [-force -reason 原因] 模块 [版本]", brief: "将模块版本移入回收站，删除整个模块需要 -all，仍有依赖方时退出码为 3", run: func(c *command, set *flag.FlagSet, args []string) error {
+		all := set.Bool("all", false, "确认删除整个模块的所有版本")
+		req := new(request.GomodDelete)
+		set.BoolVar(&req.Force, "force", false, "仍有依赖方或下载记录时强制删除")
+		set.StringVar(&req.Reason, "reason", "", "强制删除的原因")
+		if err := c.parse(set, args, 1, 2); err != nil {
+			return err
+		}
+		req.Path, req.Version = set.Arg(0), set.Arg(1)
+		if req.Version == "" {
+			if !*all {
+				_, _ = fmt.Fprintf(os.Stderr, "未指定版本将删除 %s 的所有版本，请使用 -all 确认\n", req.Path)
+				return errUsage
+			}
+			req.Confirm = req.Path
+		}
+		if req.Force && req.Reason == "" {
+			_, _ = fmt.Fprintln(os.Stderr, "强制删除必须通过 -reason 填写原因")
+			return errUsage
+		}
+		if err := c.cli.Delete(c.ctx, req); err != nil {
+			return err
+		}
+		return c.done("已移入回收站")
+	}},
+	"trash": {usage: "[模块]", brief: "查看回收站中被删除的模块版本", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 0, 1); err != nil {
+			return err
+		}
+		ret, err := c.cli.Trash(c.ctx, set.Arg(0))
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "ID\t模块\t版本\t删除人\t删除时间\t彻底删除时间\t原因")
+			for _, t := range ret {
+				version := t.Version
+				if version == "" {
+					version = "(整个模块)"
+				}
+				_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Path, version, t.JobNumber, timeText(t.CreatedAt), timeText(t.ExpiredAt), t.Reason)
+			}
+		})
+	}},
+	"restore": {usage: "ID", brief: "从回收站恢复，同名版本已重新发布时退出码为 3", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		id, err := strconv.ParseInt(set.Arg(0), 10, 64)
+		if err != nil {
+			set.Usage()
+			return errUsage
+		}
+		ret, err := c.cli.Restore(c.ctx, id)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintf(tw, "已恢复 %s\n", strings.TrimSuffix(ret.Path+"@"+ret.Version, "@"))
+		})
+	}},
+	"sniff": {usage: "压缩包", brief: "探测压缩包中的模块信息", run: func(c *command, set *flag.FlagSet, args []string) error {
+		if err := c.parse(set, args, 1, 1); err != nil {
+			return err
+		}
+		file, err := os.Open(set.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		ret, err := c.cli.Sniff(c.ctx, file, filepath.Base(file.Name()))
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintf(tw, "模块:\t%s\n版本:\t%s\n建议版本:\t%s\n已存在:\t%t\n", ret.Path, ret.Version, ret.NextVersion, ret.Exists)
+			_, _ = fmt.Fprintf(tw, "根目录:\t%s\ngo:\t%s\n许可证:\t%s\n文件:\t%d 个，%d 字节\n", ret.Root, ret.GoVersion, ret.License, ret.Files, ret.Size)
+			for _, req := range ret.Requires {
+				_, _ = fmt.Fprintf(tw, "依赖:\t%s %s\n", req.Path, req.Version)
+			}
+			for _, nested := range ret.NestedModules {
+				_, _ = fmt.Fprintf(tw, "嵌套模块:\t%s\n", nested)
+			}
+		})
+	}},
+	"upload": {usage: "[-n] 模块zip 模块 版本", brief: "上传模块 zip 发布版本，-n 只校验不发布", run: func(c *command, set *flag.FlagSet, args []string) error {
+		dryRun := set.Bool("n", false, "只校验不发布")
+		if err := c.parse(set, args, 3, 3); err != nil {
+			return err
+		}
+		file, err := os.Open(set.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		ret, err := c.cli.Upload(c.ctx, file, set.Arg(1), set.Arg(2), *dryRun)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) { printUpload(tw, ret) })
+	}},
+	"upload-repo": {usage: "[-n] 仓库压缩包 标签...", brief: "上传多模块仓库，按标签发布其中的模块", run: func(c *command, set *flag.FlagSet, args []string) error {
+		dryRun := set.Bool("n", false, "只校验不发布")
+		if err := c.parse(set, args, 2, -1); err != nil {
+			return err
+		}
+		file, err := os.Open(set.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		ret, err := c.cli.UploadRepo(c.ctx, file, filepath.Base(file.Name()), set.Args()[1:], *dryRun)
+		if err != nil {
+			return err
+		}
+		if err = c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "目录\t模块\t版本\t状态\t原因")
+			for _, m := range ret.Modules {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.Dir, m.Path, m.Version, m.Status, m.Reason)
+			}
+		}); err != nil {
+			return err
+		}
+		for _, m := range ret.Modules {
+			if m.Status == response.GomodRepoFailed {
+				return exitStatus(exitFailed)
+			}
+		}
+		return nil
+	}},
+	"publish-vcs": {usage: "[-subdir 子目录] [-m 模块] [-v 版本] [-n] 仓库 提交", brief: "从服务器本地的 git 仓库发布模块", run: func(c *command, set *flag.FlagSet, args []string) error {
+		req := new(request.GomodVCSPublish)
+		set.StringVar(&req.Subdir, "subdir", "", "模块在仓库中的子目录")
+		set.StringVar(&req.Path, "m", "", "模块路径，不填写读取 go.mod")
+		set.StringVar(&req.Version, "v", "", "版本号，不填写根据标签计算")
+		set.BoolVar(&req.DryRun, "n", false, "只校验不发布")
+		if err := c.parse(set, args, 2, 2); err != nil {
+			return err
+		}
+		req.Repository, req.Revision = set.Arg(0), set.Arg(1)
+		ret, err := c.cli.PublishVCS(c.ctx, req)
+		if err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) { printUpload(tw, ret) })
+	}},
+	"format": {usage: "[-m 模块] [-f 输出文件 | -publish [-n]] 压缩包 版本", brief: "将任意压缩包转换为模块 zip，或者转换后直接发布", run: func(c *command, set *flag.FlagSet, args []string) error {
+		modpath := set.String("m", "", "模块路径，不填写根据 go.mod 检测")
+		output := set.String("f", "go.src.zip", "转换后的输出文件")
+		publish := set.Bool("publish", false, "转换后直接发布")
+		dryRun := set.Bool("n", false, "直接发布时只校验不发布")
+		if err := c.parse(set, args, 2, 2); err != nil {
+			return err
+		}
+		file, err := os.Open(set.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		filename, version := filepath.Base(file.Name()), set.Arg(1)
+		if *publish {
+			ret, err := c.cli.FormatPublish(c.ctx, file, filename, *modpath, version, *dryRun)
+			if err != nil {
+				return err
+			}
+			return c.print(ret, func(tw *tabwriter.Writer) { printUpload(tw, ret) })
+		}
+
+		var ret any
+		if err = download(*output, func(w io.Writer) error {
+			formatted, err := c.cli.Format(c.ctx, w, file, filename, *modpath, version)
+			ret = formatted
+			return err
+		}); err != nil {
+			return err
+		}
+		return c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintf(tw, "已保存到 %s\n", *output)
+		})
+	}},
+	"bundle": {usage: "[-plan] [-f 输出文件] (模块 版本 | -gomod go.mod)", brief: "导出离线依赖包，缺少依赖时退出码为 4", run: bundle},
+}
+
+// bundle 导出离线依赖包，-plan 时只输出依赖清单。
+func bundle(c *command, set *flag.FlagSet, args []string) error {
+	gomod := set.String("gomod", "", "以 go.mod 文件为根节点")
+	plan := set.Bool("plan", false, "只输出依赖清单，不下载")
+	output := set.String("f", "gomod-bundle.zip", "离线依赖包的输出文件")
+	if err := c.parse(set, args, 0, 2); err != nil {
+		return err
+	}
+	if n := set.NArg(); (*gomod == "" && n != 2) || (*gomod != "" && n != 0) {
+		set.Usage()
+		return errUsage
+	}
+
+	var gmf *os.File
+	if *gomod != "" {
+		var err error
+		if gmf, err = os.Open(*gomod); err != nil {
+			return err
+		}
+		defer gmf.Close()
+	}
+
+	var missing int
+	if *plan {
+		var ret *response.GomodBundle
+		var err error
+		if gmf != nil {
+			ret, err = c.cli.BundleGomodPlan(c.ctx, gmf)
+		} else {
+			ret, err = c.cli.BundlePlan(c.ctx, set.Arg(0), set.Arg(1))
+		}
+		if err != nil {
+			return err
+		}
+		missing = len(ret.Missing)
+		err = c.print(ret, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintln(tw, "模块\t版本\t替换\t哈希")
+			for _, m := range ret.Modules {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.Path, m.Version, m.Replace, m.Hash)
+			}
+			for _, m := range ret.Missing {
+				_, _ = fmt.Fprintf(tw, "缺失\t%s@%s\t%s\n", m.Path, m.Version, m.Reason)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		err := download(*output, func(w io.Writer) error {
+			var err error
+			if gmf != nil {
+				missing, err = c.cli.BundleGomod(c.ctx, w, gmf)
+			} else {
+				missing, err = c.cli.Bundle(c.ctx, w, set.Arg(0), set.Arg(1))
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		err = c.print(map[string]any{"file": *output, "missing": missing}, func(tw *tabwriter.Writer) {
+			_, _ = fmt.Fprintf(tw, "已保存到 %s，缺失 %d 个依赖（见压缩包中的 missing.txt）\n", *output, missing)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if missing != 0 {
+		return exitStatus(exitNotFound)
+	}
+
+	return nil
+}
+
+// download 写入文件，出错时删除不完整的文件。
+func download(name string, write func(w io.Writer) error) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err = write(file); err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+	if err != nil {
+		_ = os.Remove(name)
+	}
+
+	return err
+}
+
+func printUpload(tw *tabwriter.Writer, ret *response.GomodUpload) {
+	_, _ = fmt.Fprintf(tw, "模块:\t%s@%s\n哈希:\t%s\n", ret.Path, ret.Version, ret.Hash)
+	if f := ret.Format; f != nil && f.Root != "" {
+		_, _ = fmt.Fprintf(tw, "剥离目录:\t%s\n", f.Root)
+	}
+	if chk := ret.Checked; chk != nil {
+		_, _ = fmt.Fprintf(tw, "校验结果:\t%d 个文件有效，%d 个被忽略，%d 个不合法\n", len(chk.Valid), len(chk.Omitted), len(chk.Invalid))
+		for _, f := range chk.Omitted {
+			_, _ = fmt.Fprintf(tw, "  忽略\t%s: %s\n", f.Path, f.Reason)
+		}
+		for _, f := range chk.Invalid {
+			_, _ = fmt.Fprintf(tw, "  不合法\t%s: %s\n", f.Path, f.Reason)
+		}
+		if chk.SizeError != "" {
+			_, _ = fmt.Fprintf(tw, "  大小超限\t%s\n", chk.SizeError)
+		}
+	}
+	if ret.DryRun {
+		_, _ = fmt.Fprintln(tw, "校验通过（未发布）")
+	} else {
+		_, _ = fmt.Fprintln(tw, "发布成功")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dfcfw/goproxy/launch"
+)
+
+// adminMain 管理员相关的命令行，直接操作数据库，不依赖服务是否在运行。
+//
+//	modsrv admin create -j 200858 -n 张三
+//	modsrv admin grant  -j 200858
+//	modsrv admin list
+func adminMain(name string, args []string) int {
+	if len(args) == 0 {
+		adminUsage(name)
+		return 2
+	}
+
+	action := args[0]
+	set := flag.NewFlagSet(name+" "+action, flag.ExitOnError)
+	cfg := set.String("c", "resources/config/application.jsonc", "配置文件")
+	jobNumber := set.String("j", "", "工号")
+	username := set.String("n", "", "名字")
+	_ = set.Parse(args[1:])
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var err error
+	switch action {
+	case "create", "grant":
+		if *jobNumber == "" {
+			set.PrintDefaults()
+			return 2
+		}
+		if action == "create" {
+			err = launch.CreateAdmin(ctx, *cfg, *jobNumber, *username)
+		} else {
+			err = launch.GrantAdmin(ctx, *cfg, *jobNumber, *username)
+		}
+		if err == nil {
+			fmt.Printf("已授予 %s 管理员权限\n", *jobNumber)
+		}
+	case "list":
+		admins, exx := launch.ListAdmins(ctx, *cfg)
+		for _, admin := range admins {
+			fmt.Printf("%s\t%s\n", admin.JobNumber, admin.Name)
+		}
+		err = exx
+	default:
+		adminUsage(name)
+		return 2
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "执行错误: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func adminUsage(name string) {
+	_, _ = fmt.Fprintf(os.Stderr, "用法: %s <create|grant|list> [-c 配置文件] [-j 工号] [-n 名字]\n", name)
+	_, _ = fmt.Fprintln(os.Stderr, "  create  创建一个新的管理员")
+	_, _ = fmt.Fprintln(os.Stderr, "  grant   紧急授权：将用户设置为管理员（不存在时自动创建）")
+	_, _ = fmt.Fprintln(os.Stderr, "  list    查看所有管理员")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/dfcfw/goproxy/contract/response"
+	"github.com/dfcfw/goproxy/launch"
+)
+
+// importMain 从其它代理的磁盘目录或者 GOMODCACHE 批量导入模块，直接操作模块存储与数据库。
+//
+//	modsrv import -d $(go env GOMODCACHE)/cache/download -p git.corp -n
+//	modsrv import -d /var/lib/athens -sum go.sum -j 200858
+func importMain(name string, args []string) int {
+	opt := new(launch.ImportOptions)
+	var sums, prefixes string
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	cfg := set.String("c", "resources/config/application.jsonc", "配置文件")
+	set.StringVar(&opt.Dir, "d", "", "导入目录，例如 $GOMODCACHE/cache/download 或 Athens 的存储目录")
+	set.StringVar(&sums, "sum", "", "用于校验的 go.sum 文件，多个以逗号分隔，没有 .ziphash 文件时使用")
+	set.StringVar(&prefixes, "p", "", "只导入这些前缀的模块，多个以逗号分隔")
+	set.StringVar(&opt.JobNumber, "j", "", "记录为发布人的工号")
+	set.BoolVar(&opt.Unverified, "unverified", false, "没有 .ziphash 与 go.sum 记录的版本也导入")
+	set.BoolVar(&opt.Force, "f", false, "覆盖仓库中内容不同的同名版本")
+	set.BoolVar(&opt.DryRun, "n", false, "只校验不导入")
+	quiet := set.Bool("q", false, "只输出失败、冲突的版本与汇总")
+	_ = set.Parse(args)
+
+	if opt.Dir == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: %s -d 导入目录 [-sum go.sum] [-p 前缀] [-j 工号] [-n]\n", name)
+		set.PrintDefaults()
+		return 2
+	}
+	opt.GoSums = splitList(sums)
+	opt.Prefixes = splitList(prefixes)
+
+	signals := []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT}
+	ctx, cancel := signal.NotifyContext(context.Background(), signals...)
+	defer cancel()
+
+	width := 0
+	rets, err := launch.Import(ctx, *cfg, opt, func(i, total int, ret *response.GomodImported) {
+		if width == 0 {
+			width = len(fmt.Sprint(total))
+		}
+		failed := ret.Status == response.GomodImportFailed || ret.Status == response.GomodImportConflict
+		if *quiet && !failed {
+			return
+		}
+		line := fmt.Sprintf("[%*d/%d] %-8s %s@%s", width, i, total, ret.Status, ret.Path, ret.Version)
+		if ret.Reason != "" {
+			line += ": " + ret.Reason
+		}
+		if failed {
+			_, _ = fmt.Fprintln(os.Stderr, line)
+		} else {
+			fmt.Println(line)
+		}
+	})
+
+	counts := make(map[string]int, 5)
+	var size int64
+	for _, ret := range rets {
+		counts[ret.Status]++
+		if ret.Status == response.GomodImportImported || ret.Status == response.GomodImportValid {
+			size += ret.Size
+		}
+	}
+	fmt.Printf("共 %d 个版本：导入 %d，校验通过 %d，已存在 %d，冲突 %d，失败 %d，大小 %.1f MiB\n", len(rets),
+		counts[response.GomodImportImported], counts[response.GomodImportValid], counts[response.GomodImportExists],
+		counts[response.GomodImportConflict], counts[response.GomodImportFailed], float64(size)/(1<<20))
+	if opt.DryRun {
+		fmt.Println("未导入任何版本（-n）")
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "执行错误: %v\n", err)
+		return 1
+	}
+	if counts[response.GomodImportFailed] != 0 || counts[response.GomodImportConflict] != 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func splitList(s string) []string {
+	var ret []string
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			ret = append(ret, elem)
+		}
+	}
+
+	return ret
+}
@@ -16,6 +16,15 @@ import (
 func main() {
 	args := os.Args
 	name := filepath.Base(args[0])
+	if len(args) > 1 {
+		switch args[1] {
+		case "admin":
+			os.Exit(adminMain(name+" admin", args[2:]))
+		case "import":
+			os.Exit(importMain(name+" import", args[2:]))
+		}
+	}
+
 	set := flag.NewFlagSet(name, flag.ExitOnError)
 	cfg := set.String("c", "resources/config/application.jsonc", "配置文件")
 	_ = set.Parse(args[1:])
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dfcfw/goproxy/library/netrc"
+	"golang.org/x/mod/module"
+)
+
+// 子命令的退出码。
+const (
+	exitOK       = 0
+	exitFailed   = 1 // 打包、网络或者服务端校验失败
+	exitUsage    = 2 // 参数错误
+	exitConflict = 3 // 服务端已存在该版本且内容不同
+)
+
+// remote 服务端连接参数。
+//
+// PAT 依次从 -t 参数、MODZIP_TOKEN 环境变量、.netrc 中服务端主机的 login 读取。
+type remote struct {
+	server  string
+	token   string
+	timeout time.Duration
+}
+
+func (r *remote) register(set *flag.FlagSet) {
+	set.StringVar(&r.server, "s", os.Getenv("MODZIP_SERVER"), "服务地址，默认读取 MODZIP_SERVER 环境变量")
+	set.StringVar(&r.token, "t", "", "PAT，默认读取 MODZIP_TOKEN 环境变量或 .netrc")
+	set.DurationVar(&r.timeout, "timeout", 10*time.Minute, "请求超时时间")
+}
+
+func (r *remote) client() (*client, error) {
+	base, err := url.Parse(strings.TrimSuffix(r.server, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("服务地址无效: %s", r.server)
+	}
+	pat := r.token
+	if pat == "" {
+		pat = os.Getenv("MODZIP_TOKEN")
+	}
+	if pat == "" {
+		pat = netrc.Login(base.Hostname())
+	}
+	if pat == "" {
+		return nil, errors.New("缺少 PAT，请通过 -t、MODZIP_TOKEN 或 .netrc 提供")
+	}
+
+	return &client{base: base, token: pat, http: &http.Client{Timeout: r.timeout}}, nil
+}
+
+type client struct {
+	base  *url.URL
+	token string
+	http  *http.Client
+}
+
+// fetch 通过 GOPROXY 协议读取服务端已发布版本的文件，例如 .ziphash、.mod，版本不存在时返回 nil。
+func (cli *client) fetch(mv module.Version, ext string) ([]byte, error) {
+	escpath, err := module.EscapePath(mv.Path)
+	if err != nil {
+		return nil, err
+	}
+	escver, err := module.EscapeVersion(mv.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, cli.base.JoinPath("private", escpath, "@v", escver+ext).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := cli.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusGone:
+		return nil, nil
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("服务端响应 %s", res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, 16<<20))
+}
+
+// publishedHash 服务端已发布版本的哈希，版本不存在时返回空。
+func (cli *client) publishedHash(mv module.Version) (string, error) {
+	raw, err := cli.fetch(mv, ".ziphash")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(raw)), nil
+}
+
+func (cli *client) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+cli.token)
+	req.Header.Set("Accept", "application/json")
+	res, err := cli.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("认证失败（%s），请检查 PAT 是否有效以及是否有发布权限", res.Status)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// inspectMain 查看模块 zip 的模块路径、版本号、文件列表、被忽略的文件以及哈希。
+//
+//	modzip inspect go.src.zip
+func inspectMain(name string, args []string) int {
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	_ = set.Parse(args)
+	if set.NArg() != 1 {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: %s 模块zip文件\n", name)
+		return exitUsage
+	}
+	file := set.Arg(0)
+
+	mv, err := zipVersion(file)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	cf, cerr := modzip.CheckZip(mv, file)
+	hash, modHash, err := zipHashes(file, mv)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+
+	fmt.Printf("模块: %s\n版本: %s\n哈希: %s\n", mv.Path, mv.Version, hash)
+	fmt.Printf("go.sum:\n  %s %s %s\n  %s %s/go.mod %s\n", mv.Path, mv.Version, hash, mv.Path, mv.Version, modHash)
+	fmt.Printf("文件 (%d):\n", len(cf.Valid))
+	root := mv.Path + "@" + mv.Version + "/"
+	for _, f := range cf.Valid {
+		fmt.Printf("  %s\n", strings.TrimPrefix(f, root))
+	}
+	printChecked(checkedOf(cf))
+	if cerr != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "校验不通过: %v\n", cerr)
+		return exitFailed
+	}
+
+	return exitOK
+}
+
+// zipVersion 根据模块 zip 中文件的 path@version/ 前缀识别模块路径与版本号。
+func zipVersion(file string) (module.Version, error) {
+	var mv module.Version
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return mv, fmt.Errorf("打开 zip 文件错误: %v", err)
+	}
+	defer zr.Close()
+
+	if len(zr.File) == 0 {
+		return mv, errors.New("zip 文件中没有任何文件")
+	}
+	prefix, rest, found := strings.Cut(zr.File[0].Name, "@")
+	version, _, ok := strings.Cut(rest, "/")
+	if prefix == "" || !found || !ok {
+		return mv, errors.New("不是模块 zip：文件需要位于 path@version/ 目录下")
+	}
+	mv = module.Version{Path: prefix, Version: version}
+	if err = module.Check(mv.Path, mv.Version); err != nil {
+		return mv, fmt.Errorf("不是模块 zip：%v", err)
+	}
+
+	return mv, nil
+}
+
+// zipHashes 计算模块 zip 的哈希以及其中 go.mod 的哈希，即 go.sum 中的两行记录。
+// 没有 go.mod 时与 go 命令一致，按照只有 module 声明的 go.mod 计算。
+func zipHashes(file string, mv module.Version) (hash, modHash string, err error) {
+	if hash, err = dirhash.HashZip(file, dirhash.DefaultHash); err != nil {
+		return "", "", fmt.Errorf("计算哈希错误: %v", err)
+	}
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return "", "", err
+	}
+	defer zr.Close()
+
+	data := []byte("module " + mv.Path + "\n")
+	for _, zf := range zr.File {
+		if zf.Name != mv.Path+"@"+mv.Version+"/go.mod" {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return "", "", err
+		}
+		data, err = io.ReadAll(io.LimitReader(rc, modzip.MaxGoMod))
+		_ = rc.Close()
+		if err != nil {
+			return "", "", err
+		}
+		break
+	}
+
+	return hash, gomodHash(data), nil
+}
+
+// gomodHash go.sum 中 /go.mod 一行的哈希。
+func gomodHash(data []byte) string {
+	open := func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	hash, _ := dirhash.Hash1([]string{"go.mod"}, open)
+
+	return hash
+}
+
+func checkedOf(cf modzip.CheckedFiles) *checked {
+	ret := &checked{Valid: cf.Valid}
+	for _, f := range cf.Omitted {
+		ret.Omitted = append(ret.Omitted, &checkedFile{Path: f.Path, Reason: f.Err.Error()})
+	}
+	for _, f := range cf.Invalid {
+		ret.Invalid = append(ret.Invalid, &checkedFile{Path: f.Path, Reason: f.Err.Error()})
+	}
+	if cf.SizeError != nil {
+		ret.SizeError = cf.SizeError.Error()
+	}
+
+	return ret
+}
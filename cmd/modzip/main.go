@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -11,81 +12,97 @@ import (
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
-	"golang.org/x/mod/zip"
 )
 
 func main() {
-	var output string
-	var modpath string
-	var version string
-	var directory string
+	args := os.Args
+	name := filepath.Base(args[0])
+	if len(args) > 1 {
+		switch args[1] {
+		case "push":
+			os.Exit(pushMain(name+" push", args[2:]))
+		case "inspect":
+			os.Exit(inspectMain(name+" inspect", args[2:]))
+		case "verify":
+			os.Exit(verifyMain(name+" verify", args[2:]))
+		}
+	}
 
-	fset := flag.NewFlagSet("modzip", flag.ExitOnError)
-	fset.StringVar(&directory, "d", "", "源代码目录")
+	var output string
+	src := new(source)
+	fset := flag.NewFlagSet(name, flag.ExitOnError)
+	src.register(fset)
 	fset.StringVar(&output, "o", "go.src.zip", "打包后的 zip 文件名")
-	fset.StringVar(&modpath, "m", "", "模块名（不填写自动检测），例如：github.com/gin-gonic/gin")
-	fset.StringVar(&version, "v", "", "版本号，例如：v1.2.3-beta")
-	_ = fset.Parse(os.Args[1:])
-
-	if directory == "" || version == "" {
+	fset.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: %s -d 源码目录 -v 版本号 [-m 模块名] [-o 输出文件]\n", name)
+		_, _ = fmt.Fprintf(os.Stderr, "      %s -d 源码目录 -vcs [-rev 提交] [-v 版本号] [-o 输出文件]\n", name)
+		_, _ = fmt.Fprintf(os.Stderr, "      %s push -d 源码目录 (-v 版本号 | -vcs) -s 服务地址 [-t PAT]\n", name)
+		_, _ = fmt.Fprintf(os.Stderr, "      %s inspect 模块zip文件\n", name)
+		_, _ = fmt.Fprintf(os.Stderr, "      %s verify (-z 模块zip | -d 源码目录) (-s 服务地址 | -sum go.sum)\n", name)
 		fset.PrintDefaults()
+	}
+	_ = fset.Parse(args[1:])
+
+	if !src.ready() {
+		fset.Usage()
 		return
 	}
+
+	ext := strings.ToLower(filepath.Ext(output))
+	if ext != ".zip" {
+		output += ".zip"
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "创建输出文件错误: %v\n", err)
+		os.Exit(1)
+	}
+	defer out.Close()
+
+	if _, err = src.pack(context.Background(), out); err != nil {
+		_ = out.Close()
+		_ = os.Remove(output)
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("执行成功")
+}
+
+// prepare 校验源码目录、模块名与版本号，模块名为空时从 go.mod 中检测，detected 表示模块名是检测到的。
+func prepare(directory, modpath, version string) (mv module.Version, detected bool, err error) {
 	if !strings.HasPrefix(version, "v") {
 		version = "v" + version
 	}
 
 	lstat, err := os.Lstat(directory)
 	if err != nil || !lstat.IsDir() {
-		_, _ = fmt.Fprintf(os.Stderr, "源码目录无效: %v\n", err)
-		os.Exit(1)
+		return mv, false, fmt.Errorf("源码目录无效: %v", err)
 	}
 
-	var detect bool
 	if modpath == "" {
 		modpath = detectGoModFile(directory)
-		detect = true
+		detected = true
 	}
 	if modpath == "" {
-		_, _ = fmt.Fprintln(os.Stderr, "请输入一个模块名")
-		os.Exit(1)
+		return mv, false, fmt.Errorf("请输入一个模块名")
 	}
 	if err = module.CheckPath(modpath); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "模块名不合法\n")
-		os.Exit(1)
-	}
-	if detect {
-		_, _ = fmt.Fprintf(os.Stdout, "检测到模块名: %s\n", modpath)
+		return mv, false, fmt.Errorf("模块名不合法")
 	}
 
 	if !semver.IsValid(version) ||
 		module.IsPseudoVersion(version) {
-		_, _ = fmt.Fprintf(os.Stderr, "版本号不合法\n")
-		os.Exit(1)
-	}
-
-	ext := strings.ToLower(filepath.Ext(output))
-	if ext != ".zip" {
-		output += ".zip"
+		return mv, false, fmt.Errorf("版本号不合法")
 	}
 
-	out, err := os.Create(output)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "创建输出文件错误: %v\n", err)
-		os.Exit(1)
-	}
-	defer out.Close()
-
-	mv := module.Version{
+	mv = module.Version{
 		Path:    modpath,
 		Version: version,
 	}
-	if err = zip.CreateFromDir(out, mv, directory); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "执行错误: %v\n", err)
-		os.Exit(1)
-	}
 
-	fmt.Println("执行成功")
+	return mv, detected, nil
 }
 
 func detectGoModFile(dir string) string {
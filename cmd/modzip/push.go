@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// pushMain 打包并发布到服务端，便于 CI 流水线一条命令完成发布。
+//
+//	modzip push -d . -v v1.2.3 -s https://goproxy.example.com -t pat_xxx
+func pushMain(name string, args []string) int {
+	src, rmt := new(source), new(remote)
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	src.register(set)
+	rmt.register(set)
+	dryRun := set.Bool("n", false, "只校验不发布")
+	_ = set.Parse(args)
+
+	if !src.ready() || rmt.server == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: %s -d 源码目录 (-v 版本号 | -vcs) -s 服务地址 [-t PAT] [-m 模块名] [-n]\n", name)
+		set.PrintDefaults()
+		return exitUsage
+	}
+	cli, err := rmt.client()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	temp, err := os.CreateTemp("", "modzip_*.zip")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "创建临时文件错误: %v\n", err)
+		return exitFailed
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	mv, err := src.pack(context.Background(), temp)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	hash, err := dirhash.HashZip(temp.Name(), dirhash.DefaultHash)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "计算哈希错误: %v\n", err)
+		return exitFailed
+	}
+	fmt.Printf("模块: %s@%s\n哈希: %s\n", mv.Path, mv.Version, hash)
+
+	// 提前查询只是为了避免重复上传，是否冲突以服务端上传接口的 409 为准。
+	published, err := cli.publishedHash(mv)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "查询已发布版本错误: %v\n", err)
+		return exitFailed
+	}
+	if published == hash {
+		fmt.Println("该版本已发布且内容一致，无需重复发布")
+		return exitOK
+	} else if published != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "版本冲突: 服务端已存在 %s@%s，哈希为 %s，请发布新的版本号\n", mv.Path, mv.Version, published)
+		return exitConflict
+	}
+
+	if _, err = temp.Seek(0, io.SeekStart); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "读取临时文件错误: %v\n", err)
+		return exitFailed
+	}
+
+	return cli.upload(temp, mv, *dryRun)
+}
+
+// upload 调用上传接口并输出服务端的校验报告，返回退出码。
+func (cli *client) upload(file *os.File, mv module.Version, dryRun bool) int {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		_ = mw.WriteField("path", mv.Path)
+		_ = mw.WriteField("version", mv.Version)
+		_ = mw.WriteField("dry_run", strconv.FormatBool(dryRun))
+		fw, err := mw.CreateFormFile("file", "go.src.zip")
+		if err == nil {
+			_, err = io.Copy(fw, file)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest(http.MethodPut, cli.base.JoinPath("api", "gomod", "upload").String(), pr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "创建请求错误: %v\n", err)
+		return exitFailed
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	for k, v := range ciHeaders() {
+		req.Header.Set(k, v)
+	}
+	res, err := cli.do(req)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "上传错误: %v\n", err)
+		return exitFailed
+	}
+	defer res.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 8<<20))
+	if res.StatusCode/100 != 2 {
+		printProblem(res, raw)
+		if res.StatusCode == http.StatusConflict {
+			return exitConflict
+		}
+		return exitFailed
+	}
+
+	ret := new(uploadResult)
+	if err = json.Unmarshal(raw, ret); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "解析响应错误: %v\n", err)
+		return exitFailed
+	}
+	printChecked(ret.Checked)
+	if ret.Hash != "" {
+		fmt.Printf("服务端哈希: %s\n", ret.Hash)
+	}
+	if ret.DryRun {
+		fmt.Println("校验通过（未发布）")
+	} else {
+		fmt.Println("发布成功")
+	}
+
+	return exitOK
+}
+
+// ciHeaders 从常见 CI 的环境变量中读取流水线元数据，随上传请求一起提交。
+func ciHeaders() map[string]string {
+	headers := make(map[string]string, 3)
+	set := func(key string, vals ...string) {
+		for _, val := range vals {
+			if val != "" {
+				headers[key] = val
+				return
+			}
+		}
+	}
+	var github, githubRun string
+	if repo := os.Getenv("GITHUB_REPOSITORY"); repo != "" {
+		github = os.Getenv("GITHUB_SERVER_URL") + "/" + repo
+		if id := os.Getenv("GITHUB_RUN_ID"); id != "" {
+			githubRun = github + "/actions/runs/" + id
+		}
+	}
+	set("X-Ci-Commit-Sha", os.Getenv("CI_COMMIT_SHA"), os.Getenv("GITHUB_SHA"))
+	set("X-Ci-Pipeline-Url", os.Getenv("CI_PIPELINE_URL"), githubRun)
+	set("X-Ci-Repository", os.Getenv("CI_PROJECT_URL"), github)
+
+	return headers
+}
+
+// uploadResult 上传接口的响应，只保留命令行需要展示的字段。
+type uploadResult struct {
+	Path    string   `json:"path"`
+	Version string   `json:"version"`
+	Hash    string   `json:"hash"`
+	DryRun  bool     `json:"dry_run"`
+	Checked *checked `json:"checked"`
+}
+
+type checked struct {
+	Valid     []string       `json:"valid"`
+	Omitted   []*checkedFile `json:"omitted"`
+	Invalid   []*checkedFile `json:"invalid"`
+	SizeError string         `json:"size_error"`
+}
+
+type checkedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type problem struct {
+	Status int             `json:"status"`
+	Detail string          `json:"detail"`
+	Errors json.RawMessage `json:"errors"`
+}
+
+type violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func printChecked(c *checked) {
+	if c == nil {
+		return
+	}
+	fmt.Printf("校验结果: %d 个文件有效，%d 个被忽略，%d 个不合法\n", len(c.Valid), len(c.Omitted), len(c.Invalid))
+	for _, f := range c.Omitted {
+		fmt.Printf("  忽略    %s: %s\n", f.Path, f.Reason)
+	}
+	for _, f := range c.Invalid {
+		fmt.Printf("  不合法  %s: %s\n", f.Path, f.Reason)
+	}
+	if c.SizeError != "" {
+		fmt.Printf("  大小超限: %s\n", c.SizeError)
+	}
+}
+
+// printProblem 输出服务端返回的错误以及结构化的错误详情（违反的上传策略或 zip 校验报告）。
+func printProblem(res *http.Response, raw []byte) {
+	pd := new(problem)
+	if err := json.Unmarshal(raw, pd); err != nil || pd.Detail == "" {
+		_, _ = fmt.Fprintf(os.Stderr, "服务端响应 %s: %s\n", res.Status, bytes.TrimSpace(raw))
+		return
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "发布失败（%s）: %s\n", res.Status, pd.Detail)
+	if len(pd.Errors) == 0 {
+		return
+	}
+
+	var vs []*violation
+	if json.Unmarshal(pd.Errors, &vs) == nil {
+		for _, v := range vs {
+			_, _ = fmt.Fprintf(os.Stderr, "  [%s] %s\n", v.Rule, v.Message)
+		}
+		return
+	}
+	c := new(checked)
+	if json.Unmarshal(pd.Errors, c) == nil {
+		printChecked(c)
+	}
+}
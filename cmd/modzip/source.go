@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfcfw/goproxy/library/gitx"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/zip"
+)
+
+// source 打包参数。默认打包目录中的所有文件，-vcs 时只打包 git 仓库中某个提交的文件，
+// 此时版本号可以不填写，根据最近的 vX.Y.Z（子目录模块为 subdir/vX.Y.Z）标签推导或计算伪版本号。
+type source struct {
+	directory string
+	modpath   string
+	version   string
+	vcs       bool
+	revision  string
+	dirty     bool
+}
+
+func (s *source) register(set *flag.FlagSet) {
+	set.StringVar(&s.directory, "d", "", "源代码目录")
+	set.StringVar(&s.modpath, "m", "", "模块名（不填写自动检测），例如：github.com/gin-gonic/gin")
+	set.StringVar(&s.version, "v", "", "版本号，例如：v1.2.3-beta，使用 -vcs 时不填写自动推导")
+	set.BoolVar(&s.vcs, "vcs", false, "只打包 git 中已提交的文件")
+	set.StringVar(&s.revision, "rev", "HEAD", "使用 -vcs 时打包的提交，可以是分支、标签或提交哈希")
+	set.BoolVar(&s.dirty, "dirty", false, "使用 -vcs 时允许工作区存在未提交的修改")
+}
+
+// ready 必填参数是否齐全。
+func (s *source) ready() bool {
+	return s.directory != "" && (s.version != "" || s.vcs)
+}
+
+// pack 打包模块 zip 写入 w。
+func (s *source) pack(ctx context.Context, w io.Writer) (module.Version, error) {
+	if s.vcs {
+		return s.packVCS(ctx, w)
+	}
+
+	mv, detected, err := prepare(s.directory, s.modpath, s.version)
+	if err != nil {
+		return mv, err
+	}
+	if detected {
+		fmt.Printf("检测到模块名: %s\n", mv.Path)
+	}
+	if err = zip.CreateFromDir(w, mv, s.directory); err != nil {
+		return mv, fmt.Errorf("执行错误: %v", err)
+	}
+
+	return mv, nil
+}
+
+func (s *source) packVCS(ctx context.Context, w io.Writer) (module.Version, error) {
+	var mv module.Version
+	dir, err := filepath.Abs(s.directory)
+	if err != nil {
+		return mv, fmt.Errorf("源码目录无效: %v", err)
+	}
+	repo, err := gitx.Open(ctx, dir)
+	if err != nil {
+		return mv, err
+	}
+	if repo.Bare() {
+		return mv, errors.New("不支持裸仓库，请在工作区中执行")
+	}
+	top, err := repo.Toplevel(ctx)
+	if err != nil {
+		return mv, err
+	}
+	if real, exx := filepath.EvalSymlinks(dir); exx == nil {
+		dir = real
+	}
+	rel, err := filepath.Rel(top, dir)
+	if err != nil {
+		return mv, err
+	}
+	subdir := filepath.ToSlash(rel)
+	if subdir == "." {
+		subdir = ""
+	}
+
+	if s.revision == "HEAD" && !s.dirty {
+		dirty, err := repo.Dirty(ctx, ".")
+		if err != nil {
+			return mv, err
+		}
+		if dirty {
+			return mv, errors.New("工作区存在未提交的修改，请先提交，或者使用 -dirty 仍然只打包已提交的文件")
+		}
+	}
+
+	commit, err := repo.Commit(ctx, s.revision)
+	if err != nil {
+		return mv, err
+	}
+	modpath := s.modpath
+	if modpath == "" {
+		raw, _ := repo.ReadFile(ctx, commit.Hash, path.Join(subdir, "go.mod"))
+		if modpath = modfile.ModulePath(raw); modpath == "" {
+			return mv, errors.New("请输入一个模块名")
+		}
+		fmt.Printf("检测到模块名: %s\n", modpath)
+	}
+	if err = module.CheckPath(modpath); err != nil {
+		return mv, errors.New("模块名不合法")
+	}
+
+	version := s.version
+	if version == "" {
+		rev, err := repo.Resolve(ctx, commit.Hash, subdir, modpath)
+		if err != nil {
+			return mv, err
+		}
+		version = rev.Version
+		if rev.Ref != "" {
+			fmt.Printf("检测到版本号: %s（%s）\n", version, rev.Ref)
+		} else {
+			fmt.Printf("没有对应的标签，使用伪版本号: %s\n", version)
+		}
+	} else {
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		if !semver.IsValid(version) || module.IsPseudoVersion(version) {
+			return mv, errors.New("版本号不合法")
+		}
+	}
+
+	mv = module.Version{Path: modpath, Version: version}
+	if err = zip.CreateFromVCS(w, mv, top, commit.Hash, subdir); err != nil {
+		return mv, fmt.Errorf("执行错误: %v", err)
+	}
+
+	return mv, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// verifyMain 重新计算本地目录或模块 zip 的哈希，与服务端已发布的版本或者 go.sum 中的记录比较。
+//
+//	modzip verify -z go.src.zip -s https://goproxy.example.com
+//	modzip verify -d . -vcs -sum ../app/go.sum
+func verifyMain(name string, args []string) int {
+	src, rmt := new(source), new(remote)
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	src.register(set)
+	rmt.register(set)
+	zipFile := set.String("z", "", "模块 zip 文件，与 -d 二选一")
+	sumFile := set.String("sum", "", "与 go.sum 文件中的记录比较，不填写时与服务端已发布的版本比较")
+	_ = set.Parse(args)
+
+	local := *zipFile != "" || src.ready()
+	if !local || (*zipFile != "" && src.directory != "") || (*sumFile == "" && rmt.server == "") {
+		_, _ = fmt.Fprintf(os.Stderr, "用法: %s (-z 模块zip | -d 源码目录 (-v 版本号 | -vcs)) (-s 服务地址 | -sum go.sum)\n", name)
+		set.PrintDefaults()
+		return exitUsage
+	}
+
+	file := *zipFile
+	if file == "" {
+		temp, err := os.CreateTemp("", "modzip_*.zip")
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "创建临时文件错误: %v\n", err)
+			return exitFailed
+		}
+		defer os.Remove(temp.Name())
+		defer temp.Close()
+		if _, err = src.pack(context.Background(), temp); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			return exitFailed
+		}
+		file = temp.Name()
+	}
+
+	mv, err := zipVersion(file)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	hash, modHash, err := zipHashes(file, mv)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	fmt.Printf("模块: %s@%s\n", mv.Path, mv.Version)
+
+	var want, wantMod, from string
+	if *sumFile != "" {
+		from = *sumFile
+		want, wantMod, err = sumHashes(*sumFile, mv)
+	} else {
+		from = "服务端"
+		want, wantMod, err = serverHashes(rmt, mv)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+
+	code := exitOK
+	compare := func(kind, got, want string) {
+		switch {
+		case want == "":
+			fmt.Printf("%-7s 跳过    %s 中没有记录\n", kind, from)
+		case got == want:
+			fmt.Printf("%-7s 一致    %s\n", kind, got)
+		default:
+			code = exitConflict
+			fmt.Printf("%-7s 不一致  本地 %s，%s %s\n", kind, got, from, want)
+		}
+	}
+	compare("zip", hash, want)
+	compare("go.mod", modHash, wantMod)
+
+	return code
+}
+
+// serverHashes 服务端已发布版本的 zip 哈希与 go.mod 哈希。
+func serverHashes(rmt *remote, mv module.Version) (hash, modHash string, err error) {
+	cli, err := rmt.client()
+	if err != nil {
+		return "", "", err
+	}
+	if hash, err = cli.publishedHash(mv); err != nil {
+		return "", "", fmt.Errorf("查询已发布版本错误: %v", err)
+	}
+	if hash == "" {
+		return "", "", fmt.Errorf("服务端不存在 %s@%s", mv.Path, mv.Version)
+	}
+	raw, err := cli.fetch(mv, ".mod")
+	if err != nil {
+		return "", "", fmt.Errorf("查询已发布版本错误: %v", err)
+	}
+	if raw != nil {
+		modHash = gomodHash(raw)
+	}
+
+	return hash, modHash, nil
+}
+
+// sumHashes 读取 go.sum 中该版本的 zip 哈希与 go.mod 哈希。
+func sumHashes(name string, mv module.Version) (hash, modHash string, err error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 || fields[0] != mv.Path {
+			continue
+		}
+		switch fields[1] {
+		case mv.Version:
+			hash = fields[2]
+		case mv.Version + "/go.mod":
+			modHash = fields[2]
+		}
+	}
+	if err = sc.Err(); err != nil {
+		return "", "", err
+	}
+	if hash == "" && modHash == "" {
+		return "", "", fmt.Errorf("%s 中没有 %s@%s 的记录", name, mv.Path, mv.Version)
+	}
+
+	return hash, modHash, nil
+}
@@ -3,6 +3,8 @@ package config
 type Config struct {
 	Server   Server   `json:"server"`
 	Database Database `json:"database"`
+	Admin    Admin    `json:"admin"`
+	Gomod    Gomod    `json:"gomod"`
 }
 
 type Database struct {
@@ -14,3 +16,48 @@ type Server struct {
 	Static map[string]string `json:"static"`
 	CAS    string            `json:"cas"`
 }
+
+type Admin struct {
+	// Bootstrap 初始管理员工号，仅在系统中没有任何管理员时生效。
+	Bootstrap []string `json:"bootstrap"`
+}
+
+type Gomod struct {
+	Policy Policy `json:"policy"`
+
+	// VCSRoots 允许从中发布模块的 git 仓库所在目录，为空表示不允许从 git 仓库发布。
+	VCSRoots []string `json:"vcs_roots"`
+
+	// Mirrors 代理直接从本地 git 镜像提供的模块，.mod 与 .zip 在首次请求时生成并缓存。
+	Mirrors []Mirror `json:"mirrors"`
+
+	// TrashDays 删除的模块在回收站中保留的天数，过期后彻底删除，0 表示默认的 30 天。
+	TrashDays int `json:"trash_days"`
+}
+
+// Mirror git 镜像，仓库需要由外部定时 git fetch 保持更新。
+type Mirror struct {
+	// Module 仓库根目录对应的模块路径，例如：git.example.com/group/repo
+	Module string `json:"module"`
+
+	// Dir 本地 git 仓库目录，通常是 git clone --mirror 得到的裸仓库。
+	Dir string `json:"dir"`
+}
+
+// Policy 上传策略，模块路径与版本号的一致性、主版本号后缀等规则始终校验，以下为可选规则。
+type Policy struct {
+	// AllowedPrefixes 允许上传的模块路径前缀，为空表示不限制。
+	AllowedPrefixes []string `json:"allowed_prefixes"`
+
+	// ForbidReplace 禁止 go.mod 中出现 replace 指令。
+	ForbidReplace bool `json:"forbid_replace"`
+
+	// ForbidExclude 禁止 go.mod 中出现 exclude 指令。
+	ForbidExclude bool `json:"forbid_exclude"`
+
+	// RequireLicense 模块根目录必须包含 LICENSE 文件。
+	RequireLicense bool `json:"require_license"`
+
+	// MaxSize zip 文件最大字节数，0 表示只受 go 模块本身 500MB 的限制。
+	MaxSize int64 `json:"max_size"`
+}
@@ -5,6 +5,13 @@ import "github.com/xgfone/ship/v5"
 var (
 	ErrDataNotExists = ship.ErrBadRequest.Newf("数据不存在")
 	ErrNotFound      = ship.ErrNotFound.Newf("资源不存在")
+	ErrUserDisabled  = ship.ErrForbidden.Newf("用户已被禁用")
+	ErrUserExists    = ship.ErrBadRequest.Newf("用户已存在")
+	ErrDisableSelf   = ship.ErrBadRequest.Newf("不能禁用自己")
+
+	ErrImpersonateSelf   = ship.ErrBadRequest.Newf("不能模拟自己登录")
+	ErrImpersonateDenied = ship.ErrForbidden.Newf("只有管理员才能模拟其他用户登录")
+	ErrNotImpersonated   = ship.ErrBadRequest.Newf("当前不是模拟登录状态")
 )
 
 var FmtPATLimited = stringError("token 不得超过 %d 个")
@@ -18,3 +25,35 @@ type stringError string
 func (s stringError) Fmt(v ...any) error {
 	return ship.ErrBadRequest.Newf(string(s), v...)
 }
+
+// FmtModuleNotFound 模块版本不存在，GOPROXY 协议要求返回 404 以便 go 命令继续尝试下一个代理。
+var FmtModuleNotFound = notFoundError("模块版本不存在：%s")
+
+type notFoundError string
+
+func (s notFoundError) Fmt(v ...any) error {
+	return ship.ErrNotFound.Newf(string(s), v...)
+}
+
+var (
+	ErrUploadOffset     = ship.ErrStatusConflict.Newf("上传偏移量与服务端记录的不一致")
+	ErrUploadLocked     = ship.ErrStatusConflict.Newf("该上传会话正在写入中")
+	ErrUploadTooLarge   = ship.ErrStatusRequestEntityTooLarge.Newf("上传的文件超出了声明的大小")
+	ErrUploadIncomplete = ship.ErrBadRequest.Newf("文件尚未上传完毕")
+	ErrUploadExpired    = ship.ErrStatusGone.Newf("上传会话已过期，请重新创建")
+	ErrPipelineURL      = ship.ErrBadRequest.Newf("流水线地址只能是 http 或 https 链接")
+)
+
+var (
+	FmtDeleteConfirm = stringError("删除整个模块的所有版本需要将 confirm 参数填写为模块路径：%s")
+	ErrDeleteReason  = ship.ErrBadRequest.Newf("强制删除必须填写原因")
+	FmtTrashConflict = conflictError("仓库中已存在同名文件，无法恢复：%s")
+
+	FmtVersionConflict = conflictError("版本 %s 已发布且内容不同（%s），已发布的版本不允许覆盖，需要先删除")
+)
+
+type conflictError string
+
+func (s conflictError) Fmt(v ...any) error {
+	return ship.ErrStatusConflict.Newf(string(s), v...)
+}
@@ -0,0 +1,37 @@
+package errcode
+
+import "strings"
+
+// Violation 违反的某一条规则。
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Violations 违反的规则列表，实现了 error 接口，响应报文会在 errors 字段中列出全部违规项。
+type Violations []*Violation
+
+func (vs Violations) Error() string {
+	msgs := make([]string, 0, len(vs))
+	for _, v := range vs {
+		msgs = append(msgs, v.Message)
+	}
+
+	return "不符合上传策略：" + strings.Join(msgs, "；")
+}
+
+func (vs Violations) Details() any {
+	return vs
+}
+
+// Detailer 携带结构化详细信息的错误。
+type Detailer interface {
+	error
+	Details() any
+}
+
+// StatusCoder 指定了 HTTP 状态码的错误。
+type StatusCoder interface {
+	error
+	StatusCode() int
+}
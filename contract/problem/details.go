@@ -31,6 +31,9 @@ type Details struct {
 
 	// Datetime 报错时间，扩充字段。
 	Datetime time.Time `json:"datetime" xml:"datetime"`
+
+	// Errors 结构化的错误详情，扩充字段。
+	Errors any `json:"errors,omitempty" xml:"errors,omitempty"`
 }
 
 func (d Details) String() string {
@@ -0,0 +1,14 @@
+package request
+
+type AccessRequestSubmit struct {
+	Reason string `json:"reason" validate:"lte=255"`
+}
+
+type AccessRequestList struct {
+	Status string `json:"status" query:"status" validate:"omitempty,oneof=pending approved denied"`
+}
+
+type AccessRequestReview struct {
+	ID     int64  `json:"id,string" validate:"required"`
+	Remark string `json:"remark"    validate:"lte=255"`
+}
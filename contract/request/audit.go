@@ -0,0 +1,17 @@
+package request
+
+import "time"
+
+type AuditEventFilter struct {
+	JobNumber string    `json:"job_number" query:"job_number"`
+	Action    string    `json:"action"     query:"action"`
+	Outcome   string    `json:"outcome"    query:"outcome" validate:"omitempty,oneof=succeed failed"`
+	Target    string    `json:"target"     query:"target"` // 模糊匹配
+	From      time.Time `json:"from"       query:"from"`
+	To        time.Time `json:"to"         query:"to"`
+}
+
+type AuditEventPage struct {
+	Pages
+	AuditEventFilter
+}
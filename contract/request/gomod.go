@@ -1,6 +1,9 @@
 package request
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"time"
+)
 
 type GomodWalk struct {
 	Path string `json:"path" query:"path" validate:"omitempty"`
@@ -19,6 +22,33 @@ type GomodUpload struct {
 	File    *multipart.FileHeader `json:"file"    form:"file"    validate:"required"`
 	Path    string                `json:"path"    form:"path"    validate:"required"`
 	Version string                `json:"version" form:"version" validate:"required"`
+	DryRun  bool                  `json:"dry_run" form:"dry_run"` // 只校验并计算哈希，不保存
+}
+
+type GomodFormat struct {
+	File    *multipart.FileHeader `json:"file"    form:"file"    validate:"required"`
+	Path    string                `json:"path"    form:"path"    validate:"omitempty"` // 为空时根据 go.mod 自动检测
+	Version string                `json:"version" form:"version" validate:"required"`
+	Publish bool                  `json:"publish" form:"publish"` // 转换后直接发布，不再返回 zip 文件
+	DryRun  bool                  `json:"dry_run" form:"dry_run"` // 直接发布时只校验并计算哈希，不保存
+}
+
+type GomodRepoUpload struct {
+	File   *multipart.FileHeader `json:"file"    form:"file"    validate:"required"`
+	Tags   string                `json:"tags"    form:"tags"    validate:"required"` // 多个标签以逗号或空格分隔，例如：v1.2.0,tools/v0.3.0
+	DryRun bool                  `json:"dry_run" form:"dry_run"`
+}
+
+// GomodPublisher 发布人信息，由接口层根据 session 与请求头填充。
+type GomodPublisher struct {
+	JobNumber   string    // 发布人工号
+	TokenName   string    // 使用 PAT 发布时的 PAT 名字
+	ClientIP    string    // 客户端 IP
+	CommitSHA   string    // CI 提交哈希
+	PipelineURL string    // CI 流水线地址
+	Repository  string    // 代码仓库地址
+	Ref         string    // 代码引用，例如：refs/tags/v1.0.0
+	Time        time.Time // 版本时间，为空时使用发布时间
 }
 
 type GomodFile struct {
@@ -29,4 +59,60 @@ type GomodFile struct {
 type GomodDelete struct {
 	Path    string `json:"path,omitzero"    query:"path"    validate:"required"`
 	Version string `json:"version,omitzero" query:"version"`
+	Confirm string `json:"confirm,omitzero" query:"confirm"` // 删除整个模块时必须填写模块路径以确认
+	Force   bool   `json:"force,omitzero"   query:"force"`   // 仍有依赖方或下载记录时强制删除
+	Reason  string `json:"reason,omitzero"  query:"reason"`  // 强制删除的原因，记录在审计日志中
+}
+
+// GomodUploadCreate 创建断点续传会话。
+type GomodUploadCreate struct {
+	Path    string `json:"path"    validate:"required"`
+	Version string `json:"version" validate:"required"`
+	DryRun  bool   `json:"dry_run"`
+	Length  int64  `json:"length"  validate:"gt=0"`
+}
+
+// GomodVCSPublish 从服务器本地的 git 仓库发布模块。
+type GomodVCSPublish struct {
+	Repository string `json:"repository" validate:"required"` // 仓库目录或 file:// 地址，必须位于配置允许的目录下
+	Revision   string `json:"revision"   validate:"required"` // 标签、分支或提交哈希
+	Subdir     string `json:"subdir"`                         // 模块在仓库中的子目录
+	Path       string `json:"path"`                           // 模块路径，为空时读取 go.mod
+	Version    string `json:"version"`                        // 版本号，为空时根据标签计算，没有标签时使用伪版本号；指定时必须有指向该提交的标签或者是该提交的伪版本号
+	DryRun     bool   `json:"dry_run"`
+}
+
+// GomodBundle 以仓库中的模块版本为根节点导出离线依赖包。
+type GomodBundle struct {
+	Path    string `json:"path"    query:"path"    validate:"required"`
+	Version string `json:"version" query:"version" validate:"required"`
+	Plan    bool   `json:"plan"    query:"plan"` // 只返回依赖清单，不打包
+}
+
+// GomodBundleFile 以上传的 go.mod 文件为根节点导出离线依赖包。
+type GomodBundleFile struct {
+	File *multipart.FileHeader `json:"file" form:"file" validate:"required"`
+	Plan bool                  `json:"plan" form:"plan"` // 只返回依赖清单，不打包
+}
+
+// GomodGraph 查看仓库中模块版本的依赖图。
+type GomodGraph struct {
+	Path    string `json:"path"    query:"path"    validate:"required"`
+	Version string `json:"version" query:"version" validate:"required"`
+}
+
+// GomodDependents 查看依赖了某个模块的版本。
+type GomodDependents struct {
+	Path    string `json:"path"    query:"path"    validate:"required"`
+	Version string `json:"version" query:"version"` // 只查看依赖此版本的，为空表示所有版本
+}
+
+// GomodTrashList 查看回收站。
+type GomodTrashList struct {
+	Path string `json:"path" query:"path"` // 只查看该模块（包括嵌套的子模块），为空表示全部
+}
+
+// GomodTrashRestore 从回收站恢复。
+type GomodTrashRestore struct {
+	ID int64 `json:"id,string" validate:"required"`
 }
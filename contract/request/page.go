@@ -0,0 +1,19 @@
+package request
+
+type Pages struct {
+	Page int64 `json:"page" query:"page"` // 页码，从 1 开始
+	Size int64 `json:"size" query:"size"` // 每页条数
+}
+
+// Limit 计算分页查询的 offset 和 limit，size 超过 maxSize 时取 maxSize。
+func (p Pages) Limit(maxSize int64) (offset, limit int) {
+	page, size := p.Page, p.Size
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > maxSize {
+		size = maxSize
+	}
+
+	return int((page - 1) * size), int(size)
+}
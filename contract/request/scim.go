@@ -0,0 +1,7 @@
+package request
+
+type SCIMList struct {
+	Filter     string `json:"filter"     query:"filter"`
+	StartIndex int    `json:"startIndex" query:"startIndex"`
+	Count      int    `json:"count"      query:"count"`
+}
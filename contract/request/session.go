@@ -0,0 +1,6 @@
+package request
+
+type SessionImpersonate struct {
+	JobNumber string `json:"job_number" validate:"required"`
+	Minutes   int    `json:"minutes"    validate:"gte=0"` // 模拟登录时长（分钟），不填则使用默认值
+}
@@ -1,6 +1,11 @@
 package response
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
 
 type GomodWalk struct {
 	Paths   GomodPaths   `json:"paths,omitzero"`
@@ -29,7 +34,204 @@ type GomodFile struct {
 
 type GomodFiles []*GomodFile
 
+type GomodStat struct {
+	Files     GomodFiles          `json:"files"`
+	Publisher *model.GomodVersion `json:"publisher,omitzero"`
+}
+
 type GomodSniff struct {
-	Path    string `json:"path,omitzero"`
-	Version string `json:"version,omitzero"`
+	Path          string          `json:"path,omitzero"`
+	Version       string          `json:"version,omitzero"`
+	Root          string          `json:"root,omitzero"`         // 模块在压缩包中的根目录
+	GoVersion     string          `json:"go_version,omitzero"`   // go.mod 中的 go 指令
+	Toolchain     string          `json:"toolchain,omitzero"`    // go.mod 中的 toolchain 指令
+	Requires      []*GomodRequire `json:"requires"`              // 依赖
+	License       string          `json:"license,omitzero"`      // 许可证类型，无法识别时为 unknown
+	Files         int             `json:"files"`                 // 模块 zip 中包含的文件个数
+	Size          int64           `json:"size"`                  // 模块 zip 中文件的总字节数（未压缩）
+	NestedModules []string        `json:"nested_modules"`        // 嵌套模块，打包时会被排除
+	Exists        bool            `json:"exists,omitzero"`       // 该版本在仓库中已经存在
+	NextVersion   string          `json:"next_version,omitzero"` // 根据仓库中已有版本建议的下一个版本号
+}
+
+type GomodRequire struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitzero"`
+}
+
+// GomodInfo 版本信息，即 GOPROXY 协议中的 .info 文件。
+// https://go.dev/ref/mod#goproxy-protocol
+type GomodInfo struct {
+	Version string       `json:",omitempty"`
+	Time    time.Time    `json:",omitempty"`
+	Origin  *GomodOrigin `json:",omitempty"`
+}
+
+// GomodOrigin 版本来源，字段与 go 命令的 Origin 保持一致。
+type GomodOrigin struct {
+	VCS  string `json:",omitempty"`
+	URL  string `json:",omitempty"`
+	Hash string `json:",omitempty"`
+	Ref  string `json:",omitempty"`
+}
+
+type GomodUpload struct {
+	Path    string        `json:"path"`
+	Version string        `json:"version"`
+	Hash    string        `json:"hash"`
+	Size    int64         `json:"size"`
+	DryRun  bool          `json:"dry_run"`
+	Checked *GomodChecked `json:"checked"`
+	Format  *GomodFormat  `json:"format,omitzero"` // 经过格式转换时的转换报告
+}
+
+// GomodChecked 模块 zip 的校验报告。
+type GomodChecked struct {
+	Valid     []string            `json:"valid"`                // 合法的文件
+	Omitted   []*GomodCheckedFile `json:"omitted"`              // 被忽略的文件，例如 vendor 目录以及嵌套模块
+	Invalid   []*GomodCheckedFile `json:"invalid"`              // 不合法的文件
+	SizeError string              `json:"size_error,omitempty"` // 超出大小限制
+}
+
+type GomodCheckedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// GomodFormat 格式转换报告。
+type GomodFormat struct {
+	Path    string              `json:"path"`    // 模块路径，未输入时为自动检测的结果
+	Version string              `json:"version"` // 版本号
+	Root    string              `json:"root"`    // 剥离的目录前缀，即模块在压缩包中的根目录
+	Omitted []*GomodCheckedFile `json:"omitted"` // 被排除的文件，例如模块目录以外、嵌套模块以及 vendor 目录下的文件
+}
+
+// 多模块仓库中各个模块的处理状态。
+const (
+	GomodRepoValid     = "valid"     // 校验通过（dry_run）
+	GomodRepoPublished = "published" // 已发布
+	GomodRepoSkipped   = "skipped"   // 已跳过
+	GomodRepoFailed    = "failed"    // 校验或发布失败
+)
+
+// GomodRepo 多模块仓库的发布报告。
+type GomodRepo struct {
+	DryRun  bool               `json:"dry_run"`
+	Root    string             `json:"root"` // 剥离的顶层目录
+	Modules []*GomodRepoModule `json:"modules"`
+}
+
+// Target 发布的所有模块版本，用于审计日志。
+func (r *GomodRepo) Target() string {
+	var targets []string
+	for _, mod := range r.Modules {
+		if mod.Version != "" {
+			targets = append(targets, mod.Path+"@"+mod.Version)
+		}
+	}
+
+	return strings.Join(targets, ", ")
+}
+
+type GomodRepoModule struct {
+	Dir     string       `json:"dir"` // 模块在仓库中的目录，根目录为空
+	Path    string       `json:"path"`
+	Version string       `json:"version,omitzero"`
+	Tag     string       `json:"tag,omitzero"` // 匹配的标签
+	Status  string       `json:"status"`
+	Reason  string       `json:"reason,omitzero"`
+	Result  *GomodUpload `json:"result,omitzero"`
+}
+
+// 批量导入时各个模块版本的处理状态。
+const (
+	GomodImportImported = "imported" // 已导入
+	GomodImportValid    = "valid"    // 校验通过（dry_run）
+	GomodImportExists   = "exists"   // 仓库中已存在且内容一致
+	GomodImportConflict = "conflict" // 仓库中已存在内容不同的同名版本
+	GomodImportFailed   = "failed"   // 校验或导入失败
+)
+
+// GomodImported 批量导入时一个模块版本的处理结果。
+type GomodImported struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Hash    string `json:"hash,omitzero"`
+	Size    int64  `json:"size"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitzero"`
+}
+
+// GomodBundle 离线依赖包的依赖清单。
+type GomodBundle struct {
+	Root    string                `json:"root"`    // 根节点，仓库中的模块版本或者上传的 go.mod 的模块路径
+	GoMods  int                   `json:"go_mods"` // 包含的 .mod 文件个数
+	Modules []*GomodBundleModule  `json:"modules"` // MVS 选择的构建列表
+	Missing []*GomodBundleMissing `json:"missing"` // 仓库中缺失的依赖
+}
+
+type GomodBundleModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Replace string `json:"replace,omitzero"` // go.mod 中的 replace 指令替换后的模块
+	Hash    string `json:"hash,omitzero"`    // 为空说明仓库中缺少 .zip 文件
+}
+
+type GomodBundleMissing struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// GomodGraph 仓库中模块版本的依赖图，根据仓库中的 .mod 文件按 MVS 计算。
+type GomodGraph struct {
+	Root     string                `json:"root"`     // 根节点的模块版本
+	Requires []*GomodGraphRequire  `json:"requires"` // 根节点 go.mod 中的直接依赖
+	Modules  []*GomodGraphModule   `json:"modules"`  // MVS 选择的构建列表，不含根节点
+	Edges    []*GomodGraphEdge     `json:"edges"`    // 所有遍历到的依赖关系
+	Missing  []*GomodBundleMissing `json:"missing"`  // 仓库中缺少 .mod 文件的依赖
+}
+
+type GomodGraphRequire struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`  // go.mod 中要求的版本
+	Selected string `json:"selected"` // MVS 选择的版本
+	Indirect bool   `json:"indirect"` // go.mod 中标记为 // indirect
+}
+
+type GomodGraphModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Direct  bool   `json:"direct"` // 是否为根节点的直接依赖
+}
+
+// GomodGraphEdge 依赖关系，格式为 path@version。
+type GomodGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GomodDependents 依赖了某个模块的版本。
+type GomodDependents struct {
+	Path       string            `json:"path"`       // 被依赖的模块
+	Scanned    int               `json:"scanned"`    // 扫描的 .mod 文件个数
+	Dependents []*GomodDependent `json:"dependents"` // 按模块路径与版本排序
+}
+
+type GomodDependent struct {
+	Path     string `json:"path"`     // 依赖方模块
+	Version  string `json:"version"`  // 依赖方版本
+	Module   string `json:"module"`   // 被依赖的模块，删除整个模块时可能是嵌套的子模块
+	Requires string `json:"requires"` // 依赖方 go.mod 中要求的版本
+	Indirect bool   `json:"indirect"` // go.mod 中标记为 // indirect
+	Latest   bool   `json:"latest"`   // 是否为依赖方在仓库中的最新版本
+}
+
+// GomodDeleteBlocked 待删除的模块版本仍有依赖方或下载记录。
+type GomodDeleteBlocked struct {
+	Target     string                 `json:"target"`     // 待删除的模块或模块版本
+	Dependents []*GomodDependent      `json:"dependents"` // 仓库中直接依赖了待删除版本的模块版本
+	Downloads  int64                  `json:"downloads"`  // 下载记录条数
+	Consumers  []*model.GomodDownload `json:"consumers"`  // 最近的下载记录，最多列出 100 条
 }
@@ -0,0 +1,6 @@
+package response
+
+type Page[T any] struct {
+	Total   int64 `json:"total"`
+	Records []T   `json:"records"`
+}
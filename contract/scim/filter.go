@@ -0,0 +1,164 @@
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Filter 解析后的 SCIM 过滤条件，仅支持由 and 连接的简单比较表达式，例如：
+//
+//	userName eq "200858" and active eq true
+type Filter []Comparison
+
+type Comparison struct {
+	Attr  string // 属性名，不区分大小写
+	Op    string // eq ne co sw ew pr
+	Value string // 比较值，pr 时为空
+}
+
+// ParseFilter 解析过滤表达式，空字符串表示不过滤。
+func ParseFilter(s string) (Filter, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var ret Filter
+	for _, expr := range splitAnd(s) {
+		cmp, err := parseComparison(expr)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, cmp)
+	}
+
+	return ret, nil
+}
+
+// Match 判断资源是否满足过滤条件，attr 用于根据属性名（小写）获取资源的属性值，
+// 多值属性（例如 members.value）只要有一个值满足条件即可。
+func (f Filter) Match(attr func(name string) []string) bool {
+	for _, cmp := range f {
+		vals := attr(strings.ToLower(cmp.Attr))
+		if cmp.Op == "ne" {
+			if cmp.matchAny(vals, "eq") {
+				return false
+			}
+		} else if !cmp.matchAny(vals, cmp.Op) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c Comparison) matchAny(vals []string, op string) bool {
+	for _, val := range vals {
+		if c.match(val, op) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Comparison) match(val, op string) bool {
+	if op == "pr" {
+		return val != ""
+	}
+
+	// 属性值比较默认不区分大小写（RFC7643 caseExact=false）。
+	val, want := strings.ToLower(val), strings.ToLower(c.Value)
+	switch op {
+	case "eq":
+		return val == want
+	case "co":
+		return strings.Contains(val, want)
+	case "sw":
+		return strings.HasPrefix(val, want)
+	case "ew":
+		return strings.HasSuffix(val, want)
+	}
+
+	return false
+}
+
+func parseComparison(expr string) (Comparison, error) {
+	expr = strings.TrimSpace(expr)
+	attr, rest, _ := strings.Cut(expr, " ")
+	rest = strings.TrimSpace(rest)
+	op, value, _ := strings.Cut(rest, " ")
+	op = strings.ToLower(op)
+	value = strings.TrimSpace(value)
+
+	cmp := Comparison{Attr: attr, Op: op}
+	switch op {
+	case "pr":
+		if !validAttr(attr) || value != "" {
+			return cmp, invalidFilter(expr)
+		}
+		return cmp, nil
+	case "eq", "ne", "co", "sw", "ew":
+	default:
+		return cmp, invalidFilter(expr)
+	}
+	if !validAttr(attr) || value == "" {
+		return cmp, invalidFilter(expr)
+	}
+
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return cmp, invalidFilter(expr)
+		}
+		value = unquoted
+	} else if strings.ContainsAny(value, " \t()[]\"") {
+		// 不带引号的值只能是 true、false、数字等单个字面量，避免将 or、not 等不支持的表达式当作值。
+		return cmp, invalidFilter(expr)
+	}
+	cmp.Value = value
+
+	return cmp, nil
+}
+
+// validAttr 属性名只能包含字母、数字与 . _ - :（例如 members.value 或者带 schema URN 前缀的属性），
+// 括号、not 等不支持的语法在这里被拒绝。
+func validAttr(attr string) bool {
+	if attr == "" || strings.EqualFold(attr, "not") {
+		return false
+	}
+	for _, r := range attr {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("._-:", r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitAnd 按照 and 拆分表达式，忽略引号内的内容。
+func splitAnd(s string) []string {
+	var ret []string
+	var quoted bool
+	start := 0
+	lower := strings.ToLower(s)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(lower[i:], " and "):
+			ret = append(ret, s[start:i])
+			start = i + len(" and ")
+			i = start - 1
+		}
+	}
+
+	return append(ret, s[start:])
+}
+
+func invalidFilter(expr string) *Error {
+	return NewError(http.StatusBadRequest, "invalidFilter", "不支持的过滤条件："+expr)
+}
@@ -0,0 +1,88 @@
+package scim_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dfcfw/goproxy/contract/scim"
+)
+
+func TestParseFilter(t *testing.T) {
+	valid := []struct {
+		filter string
+		want   scim.Filter
+	}{
+		{"", nil},
+		{`userName eq "200858"`, scim.Filter{{Attr: "userName", Op: "eq", Value: "200858"}}},
+		{`userName EQ "a b"`, scim.Filter{{Attr: "userName", Op: "eq", Value: "a b"}}},
+		{`displayName co "x and y"`, scim.Filter{{Attr: "displayName", Op: "co", Value: "x and y"}}},
+		{`title pr`, scim.Filter{{Attr: "title", Op: "pr"}}},
+		{`members.value eq "1" and active eq true`, scim.Filter{
+			{Attr: "members.value", Op: "eq", Value: "1"},
+			{Attr: "active", Op: "eq", Value: "true"},
+		}},
+		{`userName sw "a\"b" AND externalId ne "x"`, scim.Filter{
+			{Attr: "userName", Op: "sw", Value: `a"b`},
+			{Attr: "externalId", Op: "ne", Value: "x"},
+		}},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName ew "8"`, scim.Filter{
+			{Attr: "urn:ietf:params:scim:schemas:core:2.0:User:userName", Op: "ew", Value: "8"},
+		}},
+	}
+	for _, c := range valid {
+		got, err := scim.ParseFilter(c.filter)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseFilter(%q) = %+v, %v, want %+v", c.filter, got, err, c.want)
+		}
+	}
+
+	invalid := []string{
+		`userName`,
+		`userName eq`,
+		`userName gt "1"`,
+		`title pr "x"`,
+		`userName eq "a`,
+		`userName eq "a" or active eq true`,
+		`userName eq a or active eq true`,
+		`not (userName eq "a")`,
+		`not userName eq "a"`,
+		`(userName eq "a")`,
+		`userName eq "a" and (active eq true)`,
+		`emails[type eq "work"] pr`,
+		`userName eq "a" and`,
+	}
+	for _, filter := range invalid {
+		if got, err := scim.ParseFilter(filter); err == nil {
+			t.Errorf("ParseFilter(%q) = %+v, want error", filter, got)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	attrs := map[string][]string{
+		"username":      {"Alice"},
+		"active":        {"true"},
+		"members.value": {"1", "2"},
+	}
+	attr := func(name string) []string { return attrs[name] }
+	cases := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "alice"`, true},
+		{`userName ne "alice"`, false},
+		{`userName sw "al" and active eq true`, true},
+		{`members.value eq "2"`, true},
+		{`members.value ne "2"`, false},
+		{`title pr`, false},
+	}
+	for _, c := range cases {
+		f, err := scim.ParseFilter(c.filter)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", c.filter, err)
+		}
+		if got := f.Match(attr); got != c.want {
+			t.Errorf("%q Match = %v, want %v", c.filter, got, c.want)
+		}
+	}
+}
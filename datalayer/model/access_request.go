@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	AccessRequestPending  = "pending"  // 待审批
+	AccessRequestApproved = "approved" // 已同意
+	AccessRequestDenied   = "denied"   // 已拒绝
+)
+
+// AccessRequest 未注册用户通过 CAS 认证后提交的访问申请。
+type AccessRequest struct {
+	ID         int64     `json:"id,string,omitzero"   gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	JobNumber  string    `json:"job_number"           gorm:"column:job_number;size:10;not null;index;comment:工号"`
+	Name       string    `json:"name"                 gorm:"column:name;size:20;comment:名字"`
+	Reason     string    `json:"reason"               gorm:"column:reason;size:255;comment:申请理由"`
+	Status     string    `json:"status"               gorm:"column:status;size:10;not null;index;comment:状态"`
+	Reviewer   string    `json:"reviewer,omitzero"    gorm:"column:reviewer;size:10;comment:审批人工号"`
+	Remark     string    `json:"remark,omitzero"      gorm:"column:remark;size:255;comment:审批意见"`
+	ReviewedAt time.Time `json:"reviewed_at,omitzero" gorm:"column:reviewed_at;comment:审批时间"`
+	CreatedAt  time.Time `json:"created_at,omitzero"  gorm:"column:created_at;autoCreateTime;comment:申请时间"`
+}
+
+func (AccessRequest) TableName() string {
+	return "access_request"
+}
@@ -2,7 +2,15 @@ package model
 
 func All() []any {
 	return []any{
+		AccessRequest{},
 		AccessToken{},
+		AuditEvent{},
+		GomodDownload{},
+		GomodTrash{},
+		GomodUpload{},
+		GomodVersion{},
+		Group{},
+		GroupMember{},
 		User{},
 	}
 }
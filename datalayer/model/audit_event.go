@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	AuditSucceed = "succeed" // 操作成功
+	AuditFailed  = "failed"  // 操作失败
+)
+
+// AuditEvent 审计日志，记录每一次修改类操作。
+type AuditEvent struct {
+	ID           int64     `json:"id,string,omitzero"     gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	JobNumber    string    `json:"job_number"             gorm:"column:job_number;size:10;index;comment:操作人工号"`
+	Impersonator string    `json:"impersonator,omitzero"  gorm:"column:impersonator;size:10;comment:模拟登录的管理员工号"`
+	Action       string    `json:"action"                 gorm:"column:action;size:50;index;comment:操作名称"`
+	Method       string    `json:"method"                 gorm:"column:method;size:10;comment:请求方法"`
+	Path         string    `json:"path"                   gorm:"column:path;size:255;comment:请求路径"`
+	Target       string    `json:"target,omitzero"        gorm:"column:target;size:255;index;comment:操作对象"`
+	ClientIP     string    `json:"client_ip"              gorm:"column:client_ip;size:50;comment:客户端IP"`
+	Outcome      string    `json:"outcome"                gorm:"column:outcome;size:10;index;comment:操作结果"`
+	StatusCode   int       `json:"status_code,omitzero"   gorm:"column:status_code;comment:响应状态码"`
+	Details      string    `json:"details,omitzero"       gorm:"column:details;type:text;comment:详细信息"`
+	CreatedAt    time.Time `json:"created_at"             gorm:"column:created_at;autoCreateTime;index;comment:操作时间"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_event"
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// GomodDownload 模块版本的下载记录，同一个用户使用同一个 PAT 下载同一版本只记录一条并累计次数，
+// 删除版本前据此判断是否还有使用方。
+type GomodDownload struct {
+	ID        int64     `json:"id,string,omitzero"   gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	Path      string    `json:"path"                 gorm:"column:path;size:255;not null;uniqueIndex:uk_download;comment:模块路径"`
+	Version   string    `json:"version"              gorm:"column:version;size:100;not null;uniqueIndex:uk_download;comment:版本号"`
+	JobNumber string    `json:"job_number"           gorm:"column:job_number;size:10;not null;uniqueIndex:uk_download;comment:下载人工号"`
+	TokenName string    `json:"token_name,omitzero"  gorm:"column:token_name;size:20;not null;uniqueIndex:uk_download;comment:下载使用的 PAT 名字"`
+	ClientIP  string    `json:"client_ip,omitzero"   gorm:"column:client_ip;size:50;comment:最近一次下载的客户端IP"`
+	Times     int64     `json:"times"                gorm:"column:times;comment:下载次数"`
+	CreatedAt time.Time `json:"created_at"           gorm:"column:created_at;autoCreateTime;comment:首次下载时间"`
+	UpdatedAt time.Time `json:"updated_at"           gorm:"column:updated_at;autoUpdateTime;comment:最近下载时间"`
+}
+
+func (GomodDownload) TableName() string {
+	return "gomod_download"
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// GomodTrash 回收站中被删除的模块或模块版本，文件移动到回收站目录下以 ID 命名的子目录中，过期后彻底删除。
+type GomodTrash struct {
+	ID        int64           `json:"id,string,omitzero" gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	Path      string          `json:"path"               gorm:"column:path;size:255;not null;index;comment:模块路径"`
+	Version   string          `json:"version,omitzero"   gorm:"column:version;size:100;comment:版本号，为空表示删除了整个模块"`
+	JobNumber string          `json:"job_number"         gorm:"column:job_number;size:10;comment:删除人工号"`
+	Reason    string          `json:"reason,omitzero"    gorm:"column:reason;size:255;comment:强制删除的原因"`
+	Versions  []*GomodVersion `json:"versions"           gorm:"column:versions;serializer:json;comment:被删除的发布记录，恢复时重建"`
+	ExpiredAt time.Time       `json:"expired_at"         gorm:"column:expired_at;index;comment:彻底删除的时间"`
+	CreatedAt time.Time       `json:"created_at"         gorm:"column:created_at;autoCreateTime;comment:删除时间"`
+}
+
+func (GomodTrash) TableName() string {
+	return "gomod_trash"
+}
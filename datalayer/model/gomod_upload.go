@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// GomodUpload 断点续传的上传会话，文件内容全部上传完毕后再统一校验发布。
+type GomodUpload struct {
+	ID        int64     `json:"id,string,omitzero" gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	JobNumber string    `json:"job_number"         gorm:"column:job_number;size:10;index;comment:上传人工号"`
+	Path      string    `json:"path"               gorm:"column:path;size:255;not null;comment:模块路径"`
+	Version   string    `json:"version"            gorm:"column:version;size:100;not null;comment:版本号"`
+	DryRun    bool      `json:"dry_run"            gorm:"column:dry_run;comment:是否只校验不发布"`
+	Length    int64     `json:"length"             gorm:"column:length;comment:文件总字节数"`
+	Offset    int64     `json:"offset"             gorm:"column:offset;comment:已上传字节数"`
+	ExpiredAt time.Time `json:"expired_at"         gorm:"column:expired_at;index;comment:过期时间"`
+	CreatedAt time.Time `json:"created_at"         gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+	UpdatedAt time.Time `json:"updated_at"         gorm:"column:updated_at;autoUpdateTime;comment:更新时间"`
+}
+
+func (GomodUpload) TableName() string {
+	return "gomod_upload"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// GomodVersion 模块版本的发布记录，用于追溯是谁、通过什么途径发布了该版本。
+type GomodVersion struct {
+	ID          int64     `json:"id,string,omitzero"     gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	Path        string    `json:"path"                   gorm:"column:path;size:255;not null;uniqueIndex:uk_path_version;comment:模块路径"`
+	Version     string    `json:"version"                gorm:"column:version;size:100;not null;uniqueIndex:uk_path_version;comment:版本号"`
+	Hash        string    `json:"hash,omitzero"          gorm:"column:hash;size:100;comment:h1 哈希"`
+	JobNumber   string    `json:"job_number"             gorm:"column:job_number;size:10;index;comment:发布人工号"`
+	TokenName   string    `json:"token_name,omitzero"    gorm:"column:token_name;size:20;comment:发布使用的 PAT 名字"`
+	ClientIP    string    `json:"client_ip,omitzero"     gorm:"column:client_ip;size:50;comment:发布时的客户端IP"`
+	CommitSHA   string    `json:"commit_sha,omitzero"    gorm:"column:commit_sha;size:64;comment:CI 提交哈希"`
+	PipelineURL string    `json:"pipeline_url,omitzero"  gorm:"column:pipeline_url;size:255;comment:CI 流水线地址"`
+	Repository  string    `json:"repository,omitzero"    gorm:"column:repository;size:255;comment:代码仓库地址"`
+	CreatedAt   time.Time `json:"created_at"             gorm:"column:created_at;autoCreateTime;comment:发布时间"`
+}
+
+func (GomodVersion) TableName() string {
+	return "gomod_version"
+}
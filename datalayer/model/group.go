@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Group 用户组，目前仅由 SCIM 同步维护。
+type Group struct {
+	ID         int64     `json:"id,string,omitzero"  gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	Name       string    `json:"name"                gorm:"column:name;size:100;not null;unique;comment:名字"`
+	ExternalID string    `json:"external_id"         gorm:"column:external_id;size:100;comment:外部系统ID"`
+	CreatedAt  time.Time `json:"created_at,omitzero" gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+	UpdatedAt  time.Time `json:"updated_at,omitzero" gorm:"column:updated_at;autoUpdateTime;comment:更新时间"`
+}
+
+func (Group) TableName() string {
+	return "group"
+}
+
+type GroupMember struct {
+	ID        int64  `json:"id,string,omitzero"  gorm:"column:id;primaryKey;autoIncrement;comment:ID"`
+	GroupID   int64  `json:"group_id,string"     gorm:"column:group_id;not null;uniqueIndex:uk_group_id_job_number;comment:用户组ID"`
+	JobNumber string `json:"job_number"          gorm:"column:job_number;size:10;not null;uniqueIndex:uk_group_id_job_number;index;comment:工号"`
+}
+
+func (GroupMember) TableName() string {
+	return "group_member"
+}
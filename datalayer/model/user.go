@@ -1,9 +1,15 @@
 package model
 
+import "time"
+
 type User struct {
-	JobNumber string `json:"job_number" gorm:"column:job_number;primaryKey;comment:工号"`
-	Name      string `json:"name"       gorm:"column:name;size:20;comment:名字"`
-	Admin     bool   `json:"admin"      gorm:"column:admin;comment:是否管理员"`
+	JobNumber   string    `json:"job_number"              gorm:"column:job_number;primaryKey;comment:工号"`
+	Name        string    `json:"name"                    gorm:"column:name;size:20;comment:名字"`
+	Admin       bool      `json:"admin"                   gorm:"column:admin;comment:是否管理员"`
+	Disabled    bool      `json:"disabled"                gorm:"column:disabled;comment:是否禁用"`
+	LastLoginAt time.Time `json:"last_login_at,omitzero"  gorm:"column:last_login_at;comment:最近登录时间"`
+	CreatedAt   time.Time `json:"created_at,omitzero"     gorm:"column:created_at;autoCreateTime;comment:创建时间"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"     gorm:"column:updated_at;autoUpdateTime;comment:更新时间"`
 }
 
 func (User) TableName() string {
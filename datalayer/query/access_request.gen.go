@@ -0,0 +1,363 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+func newAccessRequest(db *gorm.DB, opts ...gen.DOOption) accessRequest {
+	_accessRequest := accessRequest{}
+
+	_accessRequest.accessRequestDo.UseDB(db, opts...)
+	_accessRequest.accessRequestDo.UseModel(&model.AccessRequest{})
+
+	tableName := _accessRequest.accessRequestDo.TableName()
+	_accessRequest.ALL = field.NewAsterisk(tableName)
+	_accessRequest.ID = field.NewInt64(tableName, "id")
+	_accessRequest.JobNumber = field.NewString(tableName, "job_number")
+	_accessRequest.Name = field.NewString(tableName, "name")
+	_accessRequest.Reason = field.NewString(tableName, "reason")
+	_accessRequest.Status = field.NewString(tableName, "status")
+	_accessRequest.Reviewer = field.NewString(tableName, "reviewer")
+	_accessRequest.Remark = field.NewString(tableName, "remark")
+	_accessRequest.ReviewedAt = field.NewTime(tableName, "reviewed_at")
+	_accessRequest.CreatedAt = field.NewTime(tableName, "created_at")
+
+	_accessRequest.fillFieldMap()
+
+	return _accessRequest
+}
+
+type accessRequest struct {
+	accessRequestDo accessRequestDo
+
+	ALL        field.Asterisk
+	ID         field.Int64  // ID
+	JobNumber  field.String // 工号
+	Name       field.String // 名字
+	Reason     field.String // 申请理由
+	Status     field.String // 状态
+	Reviewer   field.String // 审批人工号
+	Remark     field.String // 审批意见
+	ReviewedAt field.Time   // 审批时间
+	CreatedAt  field.Time   // 申请时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (a accessRequest) Table(newTableName string) *accessRequest {
+	a.accessRequestDo.UseTable(newTableName)
+	return a.updateTableName(newTableName)
+}
+
+func (a accessRequest) As(alias string) *accessRequest {
+	a.accessRequestDo.DO = *(a.accessRequestDo.As(alias).(*gen.DO))
+	return a.updateTableName(alias)
+}
+
+func (a *accessRequest) updateTableName(table string) *accessRequest {
+	a.ALL = field.NewAsterisk(table)
+	a.ID = field.NewInt64(table, "id")
+	a.JobNumber = field.NewString(table, "job_number")
+	a.Name = field.NewString(table, "name")
+	a.Reason = field.NewString(table, "reason")
+	a.Status = field.NewString(table, "status")
+	a.Reviewer = field.NewString(table, "reviewer")
+	a.Remark = field.NewString(table, "remark")
+	a.ReviewedAt = field.NewTime(table, "reviewed_at")
+	a.CreatedAt = field.NewTime(table, "created_at")
+
+	a.fillFieldMap()
+
+	return a
+}
+
+func (a *accessRequest) WithContext(ctx context.Context) *accessRequestDo {
+	return a.accessRequestDo.WithContext(ctx)
+}
+
+func (a accessRequest) TableName() string { return a.accessRequestDo.TableName() }
+
+func (a accessRequest) Alias() string { return a.accessRequestDo.Alias() }
+
+func (a accessRequest) Columns(cols ...field.Expr) gen.Columns {
+	return a.accessRequestDo.Columns(cols...)
+}
+
+func (a *accessRequest) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := a.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (a *accessRequest) fillFieldMap() {
+	a.fieldMap = make(map[string]field.Expr, 9)
+	a.fieldMap["id"] = a.ID
+	a.fieldMap["job_number"] = a.JobNumber
+	a.fieldMap["name"] = a.Name
+	a.fieldMap["reason"] = a.Reason
+	a.fieldMap["status"] = a.Status
+	a.fieldMap["reviewer"] = a.Reviewer
+	a.fieldMap["remark"] = a.Remark
+	a.fieldMap["reviewed_at"] = a.ReviewedAt
+	a.fieldMap["created_at"] = a.CreatedAt
+}
+
+func (a accessRequest) clone(db *gorm.DB) accessRequest {
+	a.accessRequestDo.ReplaceConnPool(db.Statement.ConnPool)
+	return a
+}
+
+func (a accessRequest) replaceDB(db *gorm.DB) accessRequest {
+	a.accessRequestDo.ReplaceDB(db)
+	return a
+}
+
+type accessRequestDo struct{ gen.DO }
+
+func (a accessRequestDo) Debug() *accessRequestDo {
+	return a.withDO(a.DO.Debug())
+}
+
+func (a accessRequestDo) WithContext(ctx context.Context) *accessRequestDo {
+	return a.withDO(a.DO.WithContext(ctx))
+}
+
+func (a accessRequestDo) ReadDB() *accessRequestDo {
+	return a.Clauses(dbresolver.Read)
+}
+
+func (a accessRequestDo) WriteDB() *accessRequestDo {
+	return a.Clauses(dbresolver.Write)
+}
+
+func (a accessRequestDo) Session(config *gorm.Session) *accessRequestDo {
+	return a.withDO(a.DO.Session(config))
+}
+
+func (a accessRequestDo) Clauses(conds ...clause.Expression) *accessRequestDo {
+	return a.withDO(a.DO.Clauses(conds...))
+}
+
+func (a accessRequestDo) Returning(value interface{}, columns ...string) *accessRequestDo {
+	return a.withDO(a.DO.Returning(value, columns...))
+}
+
+func (a accessRequestDo) Not(conds ...gen.Condition) *accessRequestDo {
+	return a.withDO(a.DO.Not(conds...))
+}
+
+func (a accessRequestDo) Or(conds ...gen.Condition) *accessRequestDo {
+	return a.withDO(a.DO.Or(conds...))
+}
+
+func (a accessRequestDo) Select(conds ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.Select(conds...))
+}
+
+func (a accessRequestDo) Where(conds ...gen.Condition) *accessRequestDo {
+	return a.withDO(a.DO.Where(conds...))
+}
+
+func (a accessRequestDo) Order(conds ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.Order(conds...))
+}
+
+func (a accessRequestDo) Distinct(cols ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.Distinct(cols...))
+}
+
+func (a accessRequestDo) Omit(cols ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.Omit(cols...))
+}
+
+func (a accessRequestDo) Join(table schema.Tabler, on ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.Join(table, on...))
+}
+
+func (a accessRequestDo) LeftJoin(table schema.Tabler, on ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.LeftJoin(table, on...))
+}
+
+func (a accessRequestDo) RightJoin(table schema.Tabler, on ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.RightJoin(table, on...))
+}
+
+func (a accessRequestDo) Group(cols ...field.Expr) *accessRequestDo {
+	return a.withDO(a.DO.Group(cols...))
+}
+
+func (a accessRequestDo) Having(conds ...gen.Condition) *accessRequestDo {
+	return a.withDO(a.DO.Having(conds...))
+}
+
+func (a accessRequestDo) Limit(limit int) *accessRequestDo {
+	return a.withDO(a.DO.Limit(limit))
+}
+
+func (a accessRequestDo) Offset(offset int) *accessRequestDo {
+	return a.withDO(a.DO.Offset(offset))
+}
+
+func (a accessRequestDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *accessRequestDo {
+	return a.withDO(a.DO.Scopes(funcs...))
+}
+
+func (a accessRequestDo) Unscoped() *accessRequestDo {
+	return a.withDO(a.DO.Unscoped())
+}
+
+func (a accessRequestDo) Create(values ...*model.AccessRequest) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return a.DO.Create(values)
+}
+
+func (a accessRequestDo) CreateInBatches(values []*model.AccessRequest, batchSize int) error {
+	return a.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (a accessRequestDo) Save(values ...*model.AccessRequest) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return a.DO.Save(values)
+}
+
+func (a accessRequestDo) First() (*model.AccessRequest, error) {
+	if result, err := a.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.AccessRequest), nil
+	}
+}
+
+func (a accessRequestDo) Take() (*model.AccessRequest, error) {
+	if result, err := a.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.AccessRequest), nil
+	}
+}
+
+func (a accessRequestDo) Last() (*model.AccessRequest, error) {
+	if result, err := a.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.AccessRequest), nil
+	}
+}
+
+func (a accessRequestDo) Find() ([]*model.AccessRequest, error) {
+	result, err := a.DO.Find()
+	return result.([]*model.AccessRequest), err
+}
+
+func (a accessRequestDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.AccessRequest, err error) {
+	buf := make([]*model.AccessRequest, 0, batchSize)
+	err = a.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (a accessRequestDo) FindInBatches(result *[]*model.AccessRequest, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return a.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (a accessRequestDo) Attrs(attrs ...field.AssignExpr) *accessRequestDo {
+	return a.withDO(a.DO.Attrs(attrs...))
+}
+
+func (a accessRequestDo) Assign(attrs ...field.AssignExpr) *accessRequestDo {
+	return a.withDO(a.DO.Assign(attrs...))
+}
+
+func (a accessRequestDo) Joins(fields ...field.RelationField) *accessRequestDo {
+	for _, _f := range fields {
+		a = *a.withDO(a.DO.Joins(_f))
+	}
+	return &a
+}
+
+func (a accessRequestDo) Preload(fields ...field.RelationField) *accessRequestDo {
+	for _, _f := range fields {
+		a = *a.withDO(a.DO.Preload(_f))
+	}
+	return &a
+}
+
+func (a accessRequestDo) FirstOrInit() (*model.AccessRequest, error) {
+	if result, err := a.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.AccessRequest), nil
+	}
+}
+
+func (a accessRequestDo) FirstOrCreate() (*model.AccessRequest, error) {
+	if result, err := a.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.AccessRequest), nil
+	}
+}
+
+func (a accessRequestDo) FindByPage(offset int, limit int) (result []*model.AccessRequest, count int64, err error) {
+	result, err = a.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = a.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (a accessRequestDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = a.Count()
+	if err != nil {
+		return
+	}
+
+	err = a.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (a accessRequestDo) Scan(result interface{}) (err error) {
+	return a.DO.Scan(result)
+}
+
+func (a accessRequestDo) Delete(models ...*model.AccessRequest) (result gen.ResultInfo, err error) {
+	return a.DO.Delete(models)
+}
+
+func (a *accessRequestDo) withDO(do gen.Dao) *accessRequestDo {
+	a.DO = *do.(*gen.DO)
+	return a
+}
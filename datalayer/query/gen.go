@@ -17,26 +17,50 @@ import (
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:          db,
-		AccessToken: newAccessToken(db, opts...),
-		User:        newUser(db, opts...),
+		db:            db,
+		AccessRequest: newAccessRequest(db, opts...),
+		AccessToken:   newAccessToken(db, opts...),
+		AuditEvent:    newAuditEvent(db, opts...),
+		GomodDownload: newGomodDownload(db, opts...),
+		GomodTrash:    newGomodTrash(db, opts...),
+		GomodUpload:   newGomodUpload(db, opts...),
+		GomodVersion:  newGomodVersion(db, opts...),
+		Group:         newGroup(db, opts...),
+		GroupMember:   newGroupMember(db, opts...),
+		User:          newUser(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	AccessToken accessToken
-	User        user
+	AccessRequest accessRequest
+	AccessToken   accessToken
+	AuditEvent    auditEvent
+	GomodDownload gomodDownload
+	GomodTrash    gomodTrash
+	GomodUpload   gomodUpload
+	GomodVersion  gomodVersion
+	Group         group
+	GroupMember   groupMember
+	User          user
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:          db,
-		AccessToken: q.AccessToken.clone(db),
-		User:        q.User.clone(db),
+		db:            db,
+		AccessRequest: q.AccessRequest.clone(db),
+		AccessToken:   q.AccessToken.clone(db),
+		AuditEvent:    q.AuditEvent.clone(db),
+		GomodDownload: q.GomodDownload.clone(db),
+		GomodTrash:    q.GomodTrash.clone(db),
+		GomodUpload:   q.GomodUpload.clone(db),
+		GomodVersion:  q.GomodVersion.clone(db),
+		Group:         q.Group.clone(db),
+		GroupMember:   q.GroupMember.clone(db),
+		User:          q.User.clone(db),
 	}
 }
 
@@ -50,21 +74,45 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:          db,
-		AccessToken: q.AccessToken.replaceDB(db),
-		User:        q.User.replaceDB(db),
+		db:            db,
+		AccessRequest: q.AccessRequest.replaceDB(db),
+		AccessToken:   q.AccessToken.replaceDB(db),
+		AuditEvent:    q.AuditEvent.replaceDB(db),
+		GomodDownload: q.GomodDownload.replaceDB(db),
+		GomodTrash:    q.GomodTrash.replaceDB(db),
+		GomodUpload:   q.GomodUpload.replaceDB(db),
+		GomodVersion:  q.GomodVersion.replaceDB(db),
+		Group:         q.Group.replaceDB(db),
+		GroupMember:   q.GroupMember.replaceDB(db),
+		User:          q.User.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	AccessToken *accessTokenDo
-	User        *userDo
+	AccessRequest *accessRequestDo
+	AccessToken   *accessTokenDo
+	AuditEvent    *auditEventDo
+	GomodDownload *gomodDownloadDo
+	GomodTrash    *gomodTrashDo
+	GomodUpload   *gomodUploadDo
+	GomodVersion  *gomodVersionDo
+	Group         *groupDo
+	GroupMember   *groupMemberDo
+	User          *userDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		AccessToken: q.AccessToken.WithContext(ctx),
-		User:        q.User.WithContext(ctx),
+		AccessRequest: q.AccessRequest.WithContext(ctx),
+		AccessToken:   q.AccessToken.WithContext(ctx),
+		AuditEvent:    q.AuditEvent.WithContext(ctx),
+		GomodDownload: q.GomodDownload.WithContext(ctx),
+		GomodTrash:    q.GomodTrash.WithContext(ctx),
+		GomodUpload:   q.GomodUpload.WithContext(ctx),
+		GomodVersion:  q.GomodVersion.WithContext(ctx),
+		Group:         q.Group.WithContext(ctx),
+		GroupMember:   q.GroupMember.WithContext(ctx),
+		User:          q.User.WithContext(ctx),
 	}
 }
 
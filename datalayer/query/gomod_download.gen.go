@@ -0,0 +1,363 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+func newGomodDownload(db *gorm.DB, opts ...gen.DOOption) gomodDownload {
+	_gomodDownload := gomodDownload{}
+
+	_gomodDownload.gomodDownloadDo.UseDB(db, opts...)
+	_gomodDownload.gomodDownloadDo.UseModel(&model.GomodDownload{})
+
+	tableName := _gomodDownload.gomodDownloadDo.TableName()
+	_gomodDownload.ALL = field.NewAsterisk(tableName)
+	_gomodDownload.ID = field.NewInt64(tableName, "id")
+	_gomodDownload.Path = field.NewString(tableName, "path")
+	_gomodDownload.Version = field.NewString(tableName, "version")
+	_gomodDownload.JobNumber = field.NewString(tableName, "job_number")
+	_gomodDownload.TokenName = field.NewString(tableName, "token_name")
+	_gomodDownload.ClientIP = field.NewString(tableName, "client_ip")
+	_gomodDownload.Times = field.NewInt64(tableName, "times")
+	_gomodDownload.CreatedAt = field.NewTime(tableName, "created_at")
+	_gomodDownload.UpdatedAt = field.NewTime(tableName, "updated_at")
+
+	_gomodDownload.fillFieldMap()
+
+	return _gomodDownload
+}
+
+type gomodDownload struct {
+	gomodDownloadDo gomodDownloadDo
+
+	ALL       field.Asterisk
+	ID        field.Int64  // ID
+	Path      field.String // 模块路径
+	Version   field.String // 版本号
+	JobNumber field.String // 下载人工号
+	TokenName field.String // 下载使用的 PAT 名字
+	ClientIP  field.String // 最近一次下载的客户端IP
+	Times     field.Int64  // 下载次数
+	CreatedAt field.Time   // 首次下载时间
+	UpdatedAt field.Time   // 最近下载时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (g gomodDownload) Table(newTableName string) *gomodDownload {
+	g.gomodDownloadDo.UseTable(newTableName)
+	return g.updateTableName(newTableName)
+}
+
+func (g gomodDownload) As(alias string) *gomodDownload {
+	g.gomodDownloadDo.DO = *(g.gomodDownloadDo.As(alias).(*gen.DO))
+	return g.updateTableName(alias)
+}
+
+func (g *gomodDownload) updateTableName(table string) *gomodDownload {
+	g.ALL = field.NewAsterisk(table)
+	g.ID = field.NewInt64(table, "id")
+	g.Path = field.NewString(table, "path")
+	g.Version = field.NewString(table, "version")
+	g.JobNumber = field.NewString(table, "job_number")
+	g.TokenName = field.NewString(table, "token_name")
+	g.ClientIP = field.NewString(table, "client_ip")
+	g.Times = field.NewInt64(table, "times")
+	g.CreatedAt = field.NewTime(table, "created_at")
+	g.UpdatedAt = field.NewTime(table, "updated_at")
+
+	g.fillFieldMap()
+
+	return g
+}
+
+func (g *gomodDownload) WithContext(ctx context.Context) *gomodDownloadDo {
+	return g.gomodDownloadDo.WithContext(ctx)
+}
+
+func (g gomodDownload) TableName() string { return g.gomodDownloadDo.TableName() }
+
+func (g gomodDownload) Alias() string { return g.gomodDownloadDo.Alias() }
+
+func (g gomodDownload) Columns(cols ...field.Expr) gen.Columns {
+	return g.gomodDownloadDo.Columns(cols...)
+}
+
+func (g *gomodDownload) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := g.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (g *gomodDownload) fillFieldMap() {
+	g.fieldMap = make(map[string]field.Expr, 9)
+	g.fieldMap["id"] = g.ID
+	g.fieldMap["path"] = g.Path
+	g.fieldMap["version"] = g.Version
+	g.fieldMap["job_number"] = g.JobNumber
+	g.fieldMap["token_name"] = g.TokenName
+	g.fieldMap["client_ip"] = g.ClientIP
+	g.fieldMap["times"] = g.Times
+	g.fieldMap["created_at"] = g.CreatedAt
+	g.fieldMap["updated_at"] = g.UpdatedAt
+}
+
+func (g gomodDownload) clone(db *gorm.DB) gomodDownload {
+	g.gomodDownloadDo.ReplaceConnPool(db.Statement.ConnPool)
+	return g
+}
+
+func (g gomodDownload) replaceDB(db *gorm.DB) gomodDownload {
+	g.gomodDownloadDo.ReplaceDB(db)
+	return g
+}
+
+type gomodDownloadDo struct{ gen.DO }
+
+func (g gomodDownloadDo) Debug() *gomodDownloadDo {
+	return g.withDO(g.DO.Debug())
+}
+
+func (g gomodDownloadDo) WithContext(ctx context.Context) *gomodDownloadDo {
+	return g.withDO(g.DO.WithContext(ctx))
+}
+
+func (g gomodDownloadDo) ReadDB() *gomodDownloadDo {
+	return g.Clauses(dbresolver.Read)
+}
+
+func (g gomodDownloadDo) WriteDB() *gomodDownloadDo {
+	return g.Clauses(dbresolver.Write)
+}
+
+func (g gomodDownloadDo) Session(config *gorm.Session) *gomodDownloadDo {
+	return g.withDO(g.DO.Session(config))
+}
+
+func (g gomodDownloadDo) Clauses(conds ...clause.Expression) *gomodDownloadDo {
+	return g.withDO(g.DO.Clauses(conds...))
+}
+
+func (g gomodDownloadDo) Returning(value interface{}, columns ...string) *gomodDownloadDo {
+	return g.withDO(g.DO.Returning(value, columns...))
+}
+
+func (g gomodDownloadDo) Not(conds ...gen.Condition) *gomodDownloadDo {
+	return g.withDO(g.DO.Not(conds...))
+}
+
+func (g gomodDownloadDo) Or(conds ...gen.Condition) *gomodDownloadDo {
+	return g.withDO(g.DO.Or(conds...))
+}
+
+func (g gomodDownloadDo) Select(conds ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.Select(conds...))
+}
+
+func (g gomodDownloadDo) Where(conds ...gen.Condition) *gomodDownloadDo {
+	return g.withDO(g.DO.Where(conds...))
+}
+
+func (g gomodDownloadDo) Order(conds ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.Order(conds...))
+}
+
+func (g gomodDownloadDo) Distinct(cols ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.Distinct(cols...))
+}
+
+func (g gomodDownloadDo) Omit(cols ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.Omit(cols...))
+}
+
+func (g gomodDownloadDo) Join(table schema.Tabler, on ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.Join(table, on...))
+}
+
+func (g gomodDownloadDo) LeftJoin(table schema.Tabler, on ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.LeftJoin(table, on...))
+}
+
+func (g gomodDownloadDo) RightJoin(table schema.Tabler, on ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.RightJoin(table, on...))
+}
+
+func (g gomodDownloadDo) Group(cols ...field.Expr) *gomodDownloadDo {
+	return g.withDO(g.DO.Group(cols...))
+}
+
+func (g gomodDownloadDo) Having(conds ...gen.Condition) *gomodDownloadDo {
+	return g.withDO(g.DO.Having(conds...))
+}
+
+func (g gomodDownloadDo) Limit(limit int) *gomodDownloadDo {
+	return g.withDO(g.DO.Limit(limit))
+}
+
+func (g gomodDownloadDo) Offset(offset int) *gomodDownloadDo {
+	return g.withDO(g.DO.Offset(offset))
+}
+
+func (g gomodDownloadDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *gomodDownloadDo {
+	return g.withDO(g.DO.Scopes(funcs...))
+}
+
+func (g gomodDownloadDo) Unscoped() *gomodDownloadDo {
+	return g.withDO(g.DO.Unscoped())
+}
+
+func (g gomodDownloadDo) Create(values ...*model.GomodDownload) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Create(values)
+}
+
+func (g gomodDownloadDo) CreateInBatches(values []*model.GomodDownload, batchSize int) error {
+	return g.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (g gomodDownloadDo) Save(values ...*model.GomodDownload) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Save(values)
+}
+
+func (g gomodDownloadDo) First() (*model.GomodDownload, error) {
+	if result, err := g.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodDownload), nil
+	}
+}
+
+func (g gomodDownloadDo) Take() (*model.GomodDownload, error) {
+	if result, err := g.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodDownload), nil
+	}
+}
+
+func (g gomodDownloadDo) Last() (*model.GomodDownload, error) {
+	if result, err := g.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodDownload), nil
+	}
+}
+
+func (g gomodDownloadDo) Find() ([]*model.GomodDownload, error) {
+	result, err := g.DO.Find()
+	return result.([]*model.GomodDownload), err
+}
+
+func (g gomodDownloadDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.GomodDownload, err error) {
+	buf := make([]*model.GomodDownload, 0, batchSize)
+	err = g.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (g gomodDownloadDo) FindInBatches(result *[]*model.GomodDownload, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return g.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (g gomodDownloadDo) Attrs(attrs ...field.AssignExpr) *gomodDownloadDo {
+	return g.withDO(g.DO.Attrs(attrs...))
+}
+
+func (g gomodDownloadDo) Assign(attrs ...field.AssignExpr) *gomodDownloadDo {
+	return g.withDO(g.DO.Assign(attrs...))
+}
+
+func (g gomodDownloadDo) Joins(fields ...field.RelationField) *gomodDownloadDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Joins(_f))
+	}
+	return &g
+}
+
+func (g gomodDownloadDo) Preload(fields ...field.RelationField) *gomodDownloadDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Preload(_f))
+	}
+	return &g
+}
+
+func (g gomodDownloadDo) FirstOrInit() (*model.GomodDownload, error) {
+	if result, err := g.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodDownload), nil
+	}
+}
+
+func (g gomodDownloadDo) FirstOrCreate() (*model.GomodDownload, error) {
+	if result, err := g.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodDownload), nil
+	}
+}
+
+func (g gomodDownloadDo) FindByPage(offset int, limit int) (result []*model.GomodDownload, count int64, err error) {
+	result, err = g.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = g.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (g gomodDownloadDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = g.Count()
+	if err != nil {
+		return
+	}
+
+	err = g.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (g gomodDownloadDo) Scan(result interface{}) (err error) {
+	return g.DO.Scan(result)
+}
+
+func (g gomodDownloadDo) Delete(models ...*model.GomodDownload) (result gen.ResultInfo, err error) {
+	return g.DO.Delete(models)
+}
+
+func (g *gomodDownloadDo) withDO(do gen.Dao) *gomodDownloadDo {
+	g.DO = *do.(*gen.DO)
+	return g
+}
@@ -0,0 +1,357 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+func newGomodTrash(db *gorm.DB, opts ...gen.DOOption) gomodTrash {
+	_gomodTrash := gomodTrash{}
+
+	_gomodTrash.gomodTrashDo.UseDB(db, opts...)
+	_gomodTrash.gomodTrashDo.UseModel(&model.GomodTrash{})
+
+	tableName := _gomodTrash.gomodTrashDo.TableName()
+	_gomodTrash.ALL = field.NewAsterisk(tableName)
+	_gomodTrash.ID = field.NewInt64(tableName, "id")
+	_gomodTrash.Path = field.NewString(tableName, "path")
+	_gomodTrash.Version = field.NewString(tableName, "version")
+	_gomodTrash.JobNumber = field.NewString(tableName, "job_number")
+	_gomodTrash.Reason = field.NewString(tableName, "reason")
+	_gomodTrash.Versions = field.NewField(tableName, "versions")
+	_gomodTrash.ExpiredAt = field.NewTime(tableName, "expired_at")
+	_gomodTrash.CreatedAt = field.NewTime(tableName, "created_at")
+
+	_gomodTrash.fillFieldMap()
+
+	return _gomodTrash
+}
+
+type gomodTrash struct {
+	gomodTrashDo gomodTrashDo
+
+	ALL       field.Asterisk
+	ID        field.Int64  // ID
+	Path      field.String // 模块路径
+	Version   field.String // 版本号，为空表示删除了整个模块
+	JobNumber field.String // 删除人工号
+	Reason    field.String // 强制删除的原因
+	Versions  field.Field  // 被删除的发布记录，恢复时重建
+	ExpiredAt field.Time   // 彻底删除的时间
+	CreatedAt field.Time   // 删除时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (g gomodTrash) Table(newTableName string) *gomodTrash {
+	g.gomodTrashDo.UseTable(newTableName)
+	return g.updateTableName(newTableName)
+}
+
+func (g gomodTrash) As(alias string) *gomodTrash {
+	g.gomodTrashDo.DO = *(g.gomodTrashDo.As(alias).(*gen.DO))
+	return g.updateTableName(alias)
+}
+
+func (g *gomodTrash) updateTableName(table string) *gomodTrash {
+	g.ALL = field.NewAsterisk(table)
+	g.ID = field.NewInt64(table, "id")
+	g.Path = field.NewString(table, "path")
+	g.Version = field.NewString(table, "version")
+	g.JobNumber = field.NewString(table, "job_number")
+	g.Reason = field.NewString(table, "reason")
+	g.Versions = field.NewField(table, "versions")
+	g.ExpiredAt = field.NewTime(table, "expired_at")
+	g.CreatedAt = field.NewTime(table, "created_at")
+
+	g.fillFieldMap()
+
+	return g
+}
+
+func (g *gomodTrash) WithContext(ctx context.Context) *gomodTrashDo {
+	return g.gomodTrashDo.WithContext(ctx)
+}
+
+func (g gomodTrash) TableName() string { return g.gomodTrashDo.TableName() }
+
+func (g gomodTrash) Alias() string { return g.gomodTrashDo.Alias() }
+
+func (g gomodTrash) Columns(cols ...field.Expr) gen.Columns { return g.gomodTrashDo.Columns(cols...) }
+
+func (g *gomodTrash) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := g.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (g *gomodTrash) fillFieldMap() {
+	g.fieldMap = make(map[string]field.Expr, 8)
+	g.fieldMap["id"] = g.ID
+	g.fieldMap["path"] = g.Path
+	g.fieldMap["version"] = g.Version
+	g.fieldMap["job_number"] = g.JobNumber
+	g.fieldMap["reason"] = g.Reason
+	g.fieldMap["versions"] = g.Versions
+	g.fieldMap["expired_at"] = g.ExpiredAt
+	g.fieldMap["created_at"] = g.CreatedAt
+}
+
+func (g gomodTrash) clone(db *gorm.DB) gomodTrash {
+	g.gomodTrashDo.ReplaceConnPool(db.Statement.ConnPool)
+	return g
+}
+
+func (g gomodTrash) replaceDB(db *gorm.DB) gomodTrash {
+	g.gomodTrashDo.ReplaceDB(db)
+	return g
+}
+
+type gomodTrashDo struct{ gen.DO }
+
+func (g gomodTrashDo) Debug() *gomodTrashDo {
+	return g.withDO(g.DO.Debug())
+}
+
+func (g gomodTrashDo) WithContext(ctx context.Context) *gomodTrashDo {
+	return g.withDO(g.DO.WithContext(ctx))
+}
+
+func (g gomodTrashDo) ReadDB() *gomodTrashDo {
+	return g.Clauses(dbresolver.Read)
+}
+
+func (g gomodTrashDo) WriteDB() *gomodTrashDo {
+	return g.Clauses(dbresolver.Write)
+}
+
+func (g gomodTrashDo) Session(config *gorm.Session) *gomodTrashDo {
+	return g.withDO(g.DO.Session(config))
+}
+
+func (g gomodTrashDo) Clauses(conds ...clause.Expression) *gomodTrashDo {
+	return g.withDO(g.DO.Clauses(conds...))
+}
+
+func (g gomodTrashDo) Returning(value interface{}, columns ...string) *gomodTrashDo {
+	return g.withDO(g.DO.Returning(value, columns...))
+}
+
+func (g gomodTrashDo) Not(conds ...gen.Condition) *gomodTrashDo {
+	return g.withDO(g.DO.Not(conds...))
+}
+
+func (g gomodTrashDo) Or(conds ...gen.Condition) *gomodTrashDo {
+	return g.withDO(g.DO.Or(conds...))
+}
+
+func (g gomodTrashDo) Select(conds ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.Select(conds...))
+}
+
+func (g gomodTrashDo) Where(conds ...gen.Condition) *gomodTrashDo {
+	return g.withDO(g.DO.Where(conds...))
+}
+
+func (g gomodTrashDo) Order(conds ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.Order(conds...))
+}
+
+func (g gomodTrashDo) Distinct(cols ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.Distinct(cols...))
+}
+
+func (g gomodTrashDo) Omit(cols ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.Omit(cols...))
+}
+
+func (g gomodTrashDo) Join(table schema.Tabler, on ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.Join(table, on...))
+}
+
+func (g gomodTrashDo) LeftJoin(table schema.Tabler, on ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.LeftJoin(table, on...))
+}
+
+func (g gomodTrashDo) RightJoin(table schema.Tabler, on ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.RightJoin(table, on...))
+}
+
+func (g gomodTrashDo) Group(cols ...field.Expr) *gomodTrashDo {
+	return g.withDO(g.DO.Group(cols...))
+}
+
+func (g gomodTrashDo) Having(conds ...gen.Condition) *gomodTrashDo {
+	return g.withDO(g.DO.Having(conds...))
+}
+
+func (g gomodTrashDo) Limit(limit int) *gomodTrashDo {
+	return g.withDO(g.DO.Limit(limit))
+}
+
+func (g gomodTrashDo) Offset(offset int) *gomodTrashDo {
+	return g.withDO(g.DO.Offset(offset))
+}
+
+func (g gomodTrashDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *gomodTrashDo {
+	return g.withDO(g.DO.Scopes(funcs...))
+}
+
+func (g gomodTrashDo) Unscoped() *gomodTrashDo {
+	return g.withDO(g.DO.Unscoped())
+}
+
+func (g gomodTrashDo) Create(values ...*model.GomodTrash) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Create(values)
+}
+
+func (g gomodTrashDo) CreateInBatches(values []*model.GomodTrash, batchSize int) error {
+	return g.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (g gomodTrashDo) Save(values ...*model.GomodTrash) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Save(values)
+}
+
+func (g gomodTrashDo) First() (*model.GomodTrash, error) {
+	if result, err := g.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodTrash), nil
+	}
+}
+
+func (g gomodTrashDo) Take() (*model.GomodTrash, error) {
+	if result, err := g.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodTrash), nil
+	}
+}
+
+func (g gomodTrashDo) Last() (*model.GomodTrash, error) {
+	if result, err := g.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodTrash), nil
+	}
+}
+
+func (g gomodTrashDo) Find() ([]*model.GomodTrash, error) {
+	result, err := g.DO.Find()
+	return result.([]*model.GomodTrash), err
+}
+
+func (g gomodTrashDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.GomodTrash, err error) {
+	buf := make([]*model.GomodTrash, 0, batchSize)
+	err = g.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (g gomodTrashDo) FindInBatches(result *[]*model.GomodTrash, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return g.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (g gomodTrashDo) Attrs(attrs ...field.AssignExpr) *gomodTrashDo {
+	return g.withDO(g.DO.Attrs(attrs...))
+}
+
+func (g gomodTrashDo) Assign(attrs ...field.AssignExpr) *gomodTrashDo {
+	return g.withDO(g.DO.Assign(attrs...))
+}
+
+func (g gomodTrashDo) Joins(fields ...field.RelationField) *gomodTrashDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Joins(_f))
+	}
+	return &g
+}
+
+func (g gomodTrashDo) Preload(fields ...field.RelationField) *gomodTrashDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Preload(_f))
+	}
+	return &g
+}
+
+func (g gomodTrashDo) FirstOrInit() (*model.GomodTrash, error) {
+	if result, err := g.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodTrash), nil
+	}
+}
+
+func (g gomodTrashDo) FirstOrCreate() (*model.GomodTrash, error) {
+	if result, err := g.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodTrash), nil
+	}
+}
+
+func (g gomodTrashDo) FindByPage(offset int, limit int) (result []*model.GomodTrash, count int64, err error) {
+	result, err = g.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = g.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (g gomodTrashDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = g.Count()
+	if err != nil {
+		return
+	}
+
+	err = g.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (g gomodTrashDo) Scan(result interface{}) (err error) {
+	return g.DO.Scan(result)
+}
+
+func (g gomodTrashDo) Delete(models ...*model.GomodTrash) (result gen.ResultInfo, err error) {
+	return g.DO.Delete(models)
+}
+
+func (g *gomodTrashDo) withDO(do gen.Dao) *gomodTrashDo {
+	g.DO = *do.(*gen.DO)
+	return g
+}
@@ -0,0 +1,365 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+func newGomodUpload(db *gorm.DB, opts ...gen.DOOption) gomodUpload {
+	_gomodUpload := gomodUpload{}
+
+	_gomodUpload.gomodUploadDo.UseDB(db, opts...)
+	_gomodUpload.gomodUploadDo.UseModel(&model.GomodUpload{})
+
+	tableName := _gomodUpload.gomodUploadDo.TableName()
+	_gomodUpload.ALL = field.NewAsterisk(tableName)
+	_gomodUpload.ID = field.NewInt64(tableName, "id")
+	_gomodUpload.JobNumber = field.NewString(tableName, "job_number")
+	_gomodUpload.Path = field.NewString(tableName, "path")
+	_gomodUpload.Version = field.NewString(tableName, "version")
+	_gomodUpload.DryRun = field.NewBool(tableName, "dry_run")
+	_gomodUpload.Length = field.NewInt64(tableName, "length")
+	_gomodUpload.Offset = field.NewInt64(tableName, "offset")
+	_gomodUpload.ExpiredAt = field.NewTime(tableName, "expired_at")
+	_gomodUpload.CreatedAt = field.NewTime(tableName, "created_at")
+	_gomodUpload.UpdatedAt = field.NewTime(tableName, "updated_at")
+
+	_gomodUpload.fillFieldMap()
+
+	return _gomodUpload
+}
+
+type gomodUpload struct {
+	gomodUploadDo gomodUploadDo
+
+	ALL       field.Asterisk
+	ID        field.Int64  // ID
+	JobNumber field.String // 上传人工号
+	Path      field.String // 模块路径
+	Version   field.String // 版本号
+	DryRun    field.Bool   // 是否只校验不发布
+	Length    field.Int64  // 文件总字节数
+	Offset    field.Int64  // 已上传字节数
+	ExpiredAt field.Time   // 过期时间
+	CreatedAt field.Time   // 创建时间
+	UpdatedAt field.Time   // 更新时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (g gomodUpload) Table(newTableName string) *gomodUpload {
+	g.gomodUploadDo.UseTable(newTableName)
+	return g.updateTableName(newTableName)
+}
+
+func (g gomodUpload) As(alias string) *gomodUpload {
+	g.gomodUploadDo.DO = *(g.gomodUploadDo.As(alias).(*gen.DO))
+	return g.updateTableName(alias)
+}
+
+func (g *gomodUpload) updateTableName(table string) *gomodUpload {
+	g.ALL = field.NewAsterisk(table)
+	g.ID = field.NewInt64(table, "id")
+	g.JobNumber = field.NewString(table, "job_number")
+	g.Path = field.NewString(table, "path")
+	g.Version = field.NewString(table, "version")
+	g.DryRun = field.NewBool(table, "dry_run")
+	g.Length = field.NewInt64(table, "length")
+	g.Offset = field.NewInt64(table, "offset")
+	g.ExpiredAt = field.NewTime(table, "expired_at")
+	g.CreatedAt = field.NewTime(table, "created_at")
+	g.UpdatedAt = field.NewTime(table, "updated_at")
+
+	g.fillFieldMap()
+
+	return g
+}
+
+func (g *gomodUpload) WithContext(ctx context.Context) *gomodUploadDo {
+	return g.gomodUploadDo.WithContext(ctx)
+}
+
+func (g gomodUpload) TableName() string { return g.gomodUploadDo.TableName() }
+
+func (g gomodUpload) Alias() string { return g.gomodUploadDo.Alias() }
+
+func (g gomodUpload) Columns(cols ...field.Expr) gen.Columns { return g.gomodUploadDo.Columns(cols...) }
+
+func (g *gomodUpload) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := g.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (g *gomodUpload) fillFieldMap() {
+	g.fieldMap = make(map[string]field.Expr, 10)
+	g.fieldMap["id"] = g.ID
+	g.fieldMap["job_number"] = g.JobNumber
+	g.fieldMap["path"] = g.Path
+	g.fieldMap["version"] = g.Version
+	g.fieldMap["dry_run"] = g.DryRun
+	g.fieldMap["length"] = g.Length
+	g.fieldMap["offset"] = g.Offset
+	g.fieldMap["expired_at"] = g.ExpiredAt
+	g.fieldMap["created_at"] = g.CreatedAt
+	g.fieldMap["updated_at"] = g.UpdatedAt
+}
+
+func (g gomodUpload) clone(db *gorm.DB) gomodUpload {
+	g.gomodUploadDo.ReplaceConnPool(db.Statement.ConnPool)
+	return g
+}
+
+func (g gomodUpload) replaceDB(db *gorm.DB) gomodUpload {
+	g.gomodUploadDo.ReplaceDB(db)
+	return g
+}
+
+type gomodUploadDo struct{ gen.DO }
+
+func (g gomodUploadDo) Debug() *gomodUploadDo {
+	return g.withDO(g.DO.Debug())
+}
+
+func (g gomodUploadDo) WithContext(ctx context.Context) *gomodUploadDo {
+	return g.withDO(g.DO.WithContext(ctx))
+}
+
+func (g gomodUploadDo) ReadDB() *gomodUploadDo {
+	return g.Clauses(dbresolver.Read)
+}
+
+func (g gomodUploadDo) WriteDB() *gomodUploadDo {
+	return g.Clauses(dbresolver.Write)
+}
+
+func (g gomodUploadDo) Session(config *gorm.Session) *gomodUploadDo {
+	return g.withDO(g.DO.Session(config))
+}
+
+func (g gomodUploadDo) Clauses(conds ...clause.Expression) *gomodUploadDo {
+	return g.withDO(g.DO.Clauses(conds...))
+}
+
+func (g gomodUploadDo) Returning(value interface{}, columns ...string) *gomodUploadDo {
+	return g.withDO(g.DO.Returning(value, columns...))
+}
+
+func (g gomodUploadDo) Not(conds ...gen.Condition) *gomodUploadDo {
+	return g.withDO(g.DO.Not(conds...))
+}
+
+func (g gomodUploadDo) Or(conds ...gen.Condition) *gomodUploadDo {
+	return g.withDO(g.DO.Or(conds...))
+}
+
+func (g gomodUploadDo) Select(conds ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.Select(conds...))
+}
+
+func (g gomodUploadDo) Where(conds ...gen.Condition) *gomodUploadDo {
+	return g.withDO(g.DO.Where(conds...))
+}
+
+func (g gomodUploadDo) Order(conds ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.Order(conds...))
+}
+
+func (g gomodUploadDo) Distinct(cols ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.Distinct(cols...))
+}
+
+func (g gomodUploadDo) Omit(cols ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.Omit(cols...))
+}
+
+func (g gomodUploadDo) Join(table schema.Tabler, on ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.Join(table, on...))
+}
+
+func (g gomodUploadDo) LeftJoin(table schema.Tabler, on ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.LeftJoin(table, on...))
+}
+
+func (g gomodUploadDo) RightJoin(table schema.Tabler, on ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.RightJoin(table, on...))
+}
+
+func (g gomodUploadDo) Group(cols ...field.Expr) *gomodUploadDo {
+	return g.withDO(g.DO.Group(cols...))
+}
+
+func (g gomodUploadDo) Having(conds ...gen.Condition) *gomodUploadDo {
+	return g.withDO(g.DO.Having(conds...))
+}
+
+func (g gomodUploadDo) Limit(limit int) *gomodUploadDo {
+	return g.withDO(g.DO.Limit(limit))
+}
+
+func (g gomodUploadDo) Offset(offset int) *gomodUploadDo {
+	return g.withDO(g.DO.Offset(offset))
+}
+
+func (g gomodUploadDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *gomodUploadDo {
+	return g.withDO(g.DO.Scopes(funcs...))
+}
+
+func (g gomodUploadDo) Unscoped() *gomodUploadDo {
+	return g.withDO(g.DO.Unscoped())
+}
+
+func (g gomodUploadDo) Create(values ...*model.GomodUpload) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Create(values)
+}
+
+func (g gomodUploadDo) CreateInBatches(values []*model.GomodUpload, batchSize int) error {
+	return g.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (g gomodUploadDo) Save(values ...*model.GomodUpload) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Save(values)
+}
+
+func (g gomodUploadDo) First() (*model.GomodUpload, error) {
+	if result, err := g.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodUpload), nil
+	}
+}
+
+func (g gomodUploadDo) Take() (*model.GomodUpload, error) {
+	if result, err := g.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodUpload), nil
+	}
+}
+
+func (g gomodUploadDo) Last() (*model.GomodUpload, error) {
+	if result, err := g.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodUpload), nil
+	}
+}
+
+func (g gomodUploadDo) Find() ([]*model.GomodUpload, error) {
+	result, err := g.DO.Find()
+	return result.([]*model.GomodUpload), err
+}
+
+func (g gomodUploadDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.GomodUpload, err error) {
+	buf := make([]*model.GomodUpload, 0, batchSize)
+	err = g.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (g gomodUploadDo) FindInBatches(result *[]*model.GomodUpload, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return g.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (g gomodUploadDo) Attrs(attrs ...field.AssignExpr) *gomodUploadDo {
+	return g.withDO(g.DO.Attrs(attrs...))
+}
+
+func (g gomodUploadDo) Assign(attrs ...field.AssignExpr) *gomodUploadDo {
+	return g.withDO(g.DO.Assign(attrs...))
+}
+
+func (g gomodUploadDo) Joins(fields ...field.RelationField) *gomodUploadDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Joins(_f))
+	}
+	return &g
+}
+
+func (g gomodUploadDo) Preload(fields ...field.RelationField) *gomodUploadDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Preload(_f))
+	}
+	return &g
+}
+
+func (g gomodUploadDo) FirstOrInit() (*model.GomodUpload, error) {
+	if result, err := g.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodUpload), nil
+	}
+}
+
+func (g gomodUploadDo) FirstOrCreate() (*model.GomodUpload, error) {
+	if result, err := g.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodUpload), nil
+	}
+}
+
+func (g gomodUploadDo) FindByPage(offset int, limit int) (result []*model.GomodUpload, count int64, err error) {
+	result, err = g.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = g.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (g gomodUploadDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = g.Count()
+	if err != nil {
+		return
+	}
+
+	err = g.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (g gomodUploadDo) Scan(result interface{}) (err error) {
+	return g.DO.Scan(result)
+}
+
+func (g gomodUploadDo) Delete(models ...*model.GomodUpload) (result gen.ResultInfo, err error) {
+	return g.DO.Delete(models)
+}
+
+func (g *gomodUploadDo) withDO(do gen.Dao) *gomodUploadDo {
+	g.DO = *do.(*gen.DO)
+	return g
+}
@@ -0,0 +1,371 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package query
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/dfcfw/goproxy/datalayer/model"
+)
+
+func newGomodVersion(db *gorm.DB, opts ...gen.DOOption) gomodVersion {
+	_gomodVersion := gomodVersion{}
+
+	_gomodVersion.gomodVersionDo.UseDB(db, opts...)
+	_gomodVersion.gomodVersionDo.UseModel(&model.GomodVersion{})
+
+	tableName := _gomodVersion.gomodVersionDo.TableName()
+	_gomodVersion.ALL = field.NewAsterisk(tableName)
+	_gomodVersion.ID = field.NewInt64(tableName, "id")
+	_gomodVersion.Path = field.NewString(tableName, "path")
+	_gomodVersion.Version = field.NewString(tableName, "version")
+	_gomodVersion.Hash = field.NewString(tableName, "hash")
+	_gomodVersion.JobNumber = field.NewString(tableName, "job_number")
+	_gomodVersion.TokenName = field.NewString(tableName, "token_name")
+	_gomodVersion.ClientIP = field.NewString(tableName, "client_ip")
+	_gomodVersion.CommitSHA = field.NewString(tableName, "commit_sha")
+	_gomodVersion.PipelineURL = field.NewString(tableName, "pipeline_url")
+	_gomodVersion.Repository = field.NewString(tableName, "repository")
+	_gomodVersion.CreatedAt = field.NewTime(tableName, "created_at")
+
+	_gomodVersion.fillFieldMap()
+
+	return _gomodVersion
+}
+
+type gomodVersion struct {
+	gomodVersionDo gomodVersionDo
+
+	ALL         field.Asterisk
+	ID          field.Int64  // ID
+	Path        field.String // 模块路径
+	Version     field.String // 版本号
+	Hash        field.String // h1 哈希
+	JobNumber   field.String // 发布人工号
+	TokenName   field.String // 发布使用的 PAT 名字
+	ClientIP    field.String // 发布时的客户端IP
+	CommitSHA   field.String // CI 提交哈希
+	PipelineURL field.String // CI 流水线地址
+	Repository  field.String // 代码仓库地址
+	CreatedAt   field.Time   // 发布时间
+
+	fieldMap map[string]field.Expr
+}
+
+func (g gomodVersion) Table(newTableName string) *gomodVersion {
+	g.gomodVersionDo.UseTable(newTableName)
+	return g.updateTableName(newTableName)
+}
+
+func (g gomodVersion) As(alias string) *gomodVersion {
+	g.gomodVersionDo.DO = *(g.gomodVersionDo.As(alias).(*gen.DO))
+	return g.updateTableName(alias)
+}
+
+func (g *gomodVersion) updateTableName(table string) *gomodVersion {
+	g.ALL = field.NewAsterisk(table)
+	g.ID = field.NewInt64(table, "id")
+	g.Path = field.NewString(table, "path")
+	g.Version = field.NewString(table, "version")
+	g.Hash = field.NewString(table, "hash")
+	g.JobNumber = field.NewString(table, "job_number")
+	g.TokenName = field.NewString(table, "token_name")
+	g.ClientIP = field.NewString(table, "client_ip")
+	g.CommitSHA = field.NewString(table, "commit_sha")
+	g.PipelineURL = field.NewString(table, "pipeline_url")
+	g.Repository = field.NewString(table, "repository")
+	g.CreatedAt = field.NewTime(table, "created_at")
+
+	g.fillFieldMap()
+
+	return g
+}
+
+func (g *gomodVersion) WithContext(ctx context.Context) *gomodVersionDo {
+	return g.gomodVersionDo.WithContext(ctx)
+}
+
+func (g gomodVersion) TableName() string { return g.gomodVersionDo.TableName() }
+
+func (g gomodVersion) Alias() string { return g.gomodVersionDo.Alias() }
+
+func (g gomodVersion) Columns(cols ...field.Expr) gen.Columns {
+	return g.gomodVersionDo.Columns(cols...)
+}
+
+func (g *gomodVersion) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := g.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (g *gomodVersion) fillFieldMap() {
+	g.fieldMap = make(map[string]field.Expr, 11)
+	g.fieldMap["id"] = g.ID
+	g.fieldMap["path"] = g.Path
+	g.fieldMap["version"] = g.Version
+	g.fieldMap["hash"] = g.Hash
+	g.fieldMap["job_number"] = g.JobNumber
+	g.fieldMap["token_name"] = g.TokenName
+	g.fieldMap["client_ip"] = g.ClientIP
+	g.fieldMap["commit_sha"] = g.CommitSHA
+	g.fieldMap["pipeline_url"] = g.PipelineURL
+	g.fieldMap["repository"] = g.Repository
+	g.fieldMap["created_at"] = g.CreatedAt
+}
+
+func (g gomodVersion) clone(db *gorm.DB) gomodVersion {
+	g.gomodVersionDo.ReplaceConnPool(db.Statement.ConnPool)
+	return g
+}
+
+func (g gomodVersion) replaceDB(db *gorm.DB) gomodVersion {
+	g.gomodVersionDo.ReplaceDB(db)
+	return g
+}
+
+type gomodVersionDo struct{ gen.DO }
+
+func (g gomodVersionDo) Debug() *gomodVersionDo {
+	return g.withDO(g.DO.Debug())
+}
+
+func (g gomodVersionDo) WithContext(ctx context.Context) *gomodVersionDo {
+	return g.withDO(g.DO.WithContext(ctx))
+}
+
+func (g gomodVersionDo) ReadDB() *gomodVersionDo {
+	return g.Clauses(dbresolver.Read)
+}
+
+func (g gomodVersionDo) WriteDB() *gomodVersionDo {
+	return g.Clauses(dbresolver.Write)
+}
+
+func (g gomodVersionDo) Session(config *gorm.Session) *gomodVersionDo {
+	return g.withDO(g.DO.Session(config))
+}
+
+func (g gomodVersionDo) Clauses(conds ...clause.Expression) *gomodVersionDo {
+	return g.withDO(g.DO.Clauses(conds...))
+}
+
+func (g gomodVersionDo) Returning(value interface{}, columns ...string) *gomodVersionDo {
+	return g.withDO(g.DO.Returning(value, columns...))
+}
+
+func (g gomodVersionDo) Not(conds ...gen.Condition) *gomodVersionDo {
+	return g.withDO(g.DO.Not(conds...))
+}
+
+func (g gomodVersionDo) Or(conds ...gen.Condition) *gomodVersionDo {
+	return g.withDO(g.DO.Or(conds...))
+}
+
+func (g gomodVersionDo) Select(conds ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.Select(conds...))
+}
+
+func (g gomodVersionDo) Where(conds ...gen.Condition) *gomodVersionDo {
+	return g.withDO(g.DO.Where(conds...))
+}
+
+func (g gomodVersionDo) Order(conds ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.Order(conds...))
+}
+
+func (g gomodVersionDo) Distinct(cols ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.Distinct(cols...))
+}
+
+func (g gomodVersionDo) Omit(cols ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.Omit(cols...))
+}
+
+func (g gomodVersionDo) Join(table schema.Tabler, on ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.Join(table, on...))
+}
+
+func (g gomodVersionDo) LeftJoin(table schema.Tabler, on ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.LeftJoin(table, on...))
+}
+
+func (g gomodVersionDo) RightJoin(table schema.Tabler, on ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.RightJoin(table, on...))
+}
+
+func (g gomodVersionDo) Group(cols ...field.Expr) *gomodVersionDo {
+	return g.withDO(g.DO.Group(cols...))
+}
+
+func (g gomodVersionDo) Having(conds ...gen.Condition) *gomodVersionDo {
+	return g.withDO(g.DO.Having(conds...))
+}
+
+func (g gomodVersionDo) Limit(limit int) *gomodVersionDo {
+	return g.withDO(g.DO.Limit(limit))
+}
+
+func (g gomodVersionDo) Offset(offset int) *gomodVersionDo {
+	return g.withDO(g.DO.Offset(offset))
+}
+
+func (g gomodVersionDo) Scopes(funcs ...func(gen.Dao) gen.Dao) *gomodVersionDo {
+	return g.withDO(g.DO.Scopes(funcs...))
+}
+
+func (g gomodVersionDo) Unscoped() *gomodVersionDo {
+	return g.withDO(g.DO.Unscoped())
+}
+
+func (g gomodVersionDo) Create(values ...*model.GomodVersion) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Create(values)
+}
+
+func (g gomodVersionDo) CreateInBatches(values []*model.GomodVersion, batchSize int) error {
+	return g.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (g gomodVersionDo) Save(values ...*model.GomodVersion) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return g.DO.Save(values)
+}
+
+func (g gomodVersionDo) First() (*model.GomodVersion, error) {
+	if result, err := g.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodVersion), nil
+	}
+}
+
+func (g gomodVersionDo) Take() (*model.GomodVersion, error) {
+	if result, err := g.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodVersion), nil
+	}
+}
+
+func (g gomodVersionDo) Last() (*model.GomodVersion, error) {
+	if result, err := g.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodVersion), nil
+	}
+}
+
+func (g gomodVersionDo) Find() ([]*model.GomodVersion, error) {
+	result, err := g.DO.Find()
+	return result.([]*model.GomodVersion), err
+}
+
+func (g gomodVersionDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.GomodVersion, err error) {
+	buf := make([]*model.GomodVersion, 0, batchSize)
+	err = g.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (g gomodVersionDo) FindInBatches(result *[]*model.GomodVersion, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return g.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (g gomodVersionDo) Attrs(attrs ...field.AssignExpr) *gomodVersionDo {
+	return g.withDO(g.DO.Attrs(attrs...))
+}
+
+func (g gomodVersionDo) Assign(attrs ...field.AssignExpr) *gomodVersionDo {
+	return g.withDO(g.DO.Assign(attrs...))
+}
+
+func (g gomodVersionDo) Joins(fields ...field.RelationField) *gomodVersionDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Joins(_f))
+	}
+	return &g
+}
+
+func (g gomodVersionDo) Preload(fields ...field.RelationField) *gomodVersionDo {
+	for _, _f := range fields {
+		g = *g.withDO(g.DO.Preload(_f))
+	}
+	return &g
+}
+
+func (g gomodVersionDo) FirstOrInit() (*model.GomodVersion, error) {
+	if result, err := g.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodVersion), nil
+	}
+}
+
+func (g gomodVersionDo) FirstOrCreate() (*model.GomodVersion, error) {
+	if result, err := g.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.GomodVersion), nil
+	}
+}
+
+func (g gomodVersionDo) FindByPage(offset int, limit int) (result []*model.GomodVersion, count int64, err error) {
+	result, err = g.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = g.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (g gomodVersionDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = g.Count()
+	if err != nil {
+		return
+	}
+
+	err = g.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (g gomodVersionDo) Scan(result interface{}) (err error) {
+	return g.DO.Scan(result)
+}
+
+func (g gomodVersionDo) Delete(models ...*model.GomodVersion) (result gen.ResultInfo, err error) {
+	return g.DO.Delete(models)
+}
+
+func (g *gomodVersionDo) withDO(do gen.Dao) *gomodVersionDo {
+	g.DO = *do.(*gen.DO)
+	return g
+}
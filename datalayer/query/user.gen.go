@@ -30,6 +30,10 @@ func newUser(db *gorm.DB, opts ...gen.DOOption) user {
 	_user.JobNumber = field.NewString(tableName, "job_number")
 	_user.Name = field.NewString(tableName, "name")
 	_user.Admin = field.NewBool(tableName, "admin")
+	_user.Disabled = field.NewBool(tableName, "disabled")
+	_user.LastLoginAt = field.NewTime(tableName, "last_login_at")
+	_user.CreatedAt = field.NewTime(tableName, "created_at")
+	_user.UpdatedAt = field.NewTime(tableName, "updated_at")
 
 	_user.fillFieldMap()
 
@@ -39,10 +43,14 @@ func newUser(db *gorm.DB, opts ...gen.DOOption) user {
 type user struct {
 	userDo userDo
 
-	ALL       field.Asterisk
-	JobNumber field.String // 工号
-	Name      field.String // 名字
-	Admin     field.Bool   // 是否管理员
+	ALL         field.Asterisk
+	JobNumber   field.String // 工号
+	Name        field.String // 名字
+	Admin       field.Bool   // 是否管理员
+	Disabled    field.Bool   // 是否禁用
+	LastLoginAt field.Time   // 最近登录时间
+	CreatedAt   field.Time   // 创建时间
+	UpdatedAt   field.Time   // 更新时间
 
 	fieldMap map[string]field.Expr
 }
@@ -62,6 +70,10 @@ func (u *user) updateTableName(table string) *user {
 	u.JobNumber = field.NewString(table, "job_number")
 	u.Name = field.NewString(table, "name")
 	u.Admin = field.NewBool(table, "admin")
+	u.Disabled = field.NewBool(table, "disabled")
+	u.LastLoginAt = field.NewTime(table, "last_login_at")
+	u.CreatedAt = field.NewTime(table, "created_at")
+	u.UpdatedAt = field.NewTime(table, "updated_at")
 
 	u.fillFieldMap()
 
@@ -86,10 +98,14 @@ func (u *user) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (u *user) fillFieldMap() {
-	u.fieldMap = make(map[string]field.Expr, 3)
+	u.fieldMap = make(map[string]field.Expr, 7)
 	u.fieldMap["job_number"] = u.JobNumber
 	u.fieldMap["name"] = u.Name
 	u.fieldMap["admin"] = u.Admin
+	u.fieldMap["disabled"] = u.Disabled
+	u.fieldMap["last_login_at"] = u.LastLoginAt
+	u.fieldMap["created_at"] = u.CreatedAt
+	u.fieldMap["updated_at"] = u.UpdatedAt
 }
 
 func (u user) clone(db *gorm.DB) user {
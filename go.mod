@@ -5,6 +5,7 @@ go 1.25.1
 require (
 	github.com/glebarez/sqlite v1.11.0
 	github.com/golang-jwt/jwt/v5 v5.3.0
+	github.com/klauspost/compress v1.20.1
 	github.com/xgfone/ship/v5 v5.3.2
 	golang.org/x/mod v0.28.0
 	golang.org/x/net v0.44.0
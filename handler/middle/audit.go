@@ -0,0 +1,93 @@
+package middle
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/handler/session"
+	"github.com/dfcfw/goproxy/handler/shipx"
+	"github.com/xgfone/ship/v5"
+)
+
+// NewAudit 审计中间件，记录所有非只读请求。
+//
+// 该中间件需要放在认证中间件之前，这样认证失败的请求也会被记录。
+func NewAudit(svc *service.Audit) ship.Middleware {
+	adm := &auditMiddle{svc: svc}
+	return adm.call
+}
+
+type auditMiddle struct {
+	svc *service.Audit
+}
+
+func (adm *auditMiddle) call(h ship.Handler) ship.Handler {
+	return func(c *ship.Context) error {
+		r := c.Request()
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return h(c)
+		}
+
+		ctx := service.NewAuditContext(r.Context())
+		c.SetRequest(r.WithContext(ctx))
+		service.AuditTarget(ctx, adm.target(c))
+
+		err := h(c)
+
+		evt := &model.AuditEvent{
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			ClientIP:   c.ClientIP(),
+			Outcome:    model.AuditSucceed,
+			StatusCode: c.StatusCode(),
+			CreatedAt:  time.Now(),
+		}
+		if info := shipx.DetectRouteInfo(c.Route.Data); info != nil {
+			evt.Action = info.Name()
+		}
+		if sess := session.FromMap(c.Data); sess != nil {
+			evt.JobNumber = sess.JobNumber
+			evt.Impersonator = sess.Impersonator
+			if sess.TokenName != "" {
+				service.AuditDetail(ctx, "token_name", sess.TokenName)
+			}
+		} else if name, _, ok := r.BasicAuth(); ok && name != "" && !strings.HasPrefix(name, "pat_") {
+			// 匿名接口（例如：访问申请）或认证失败时，请求中声称的工号未经认证，
+			// 只记录在详细信息中，不能作为操作人。go 命令会将 PAT 放在用户名中，不能记录。
+			service.AuditDetail(ctx, "claimed_job_number", name)
+		}
+		if err != nil {
+			code, _, detail := shipx.UnwrapError(err)
+			evt.Outcome = model.AuditFailed
+			evt.StatusCode = code
+			service.AuditDetail(ctx, "error", detail)
+		}
+		adm.svc.Write(ctx, evt)
+
+		return err
+	}
+}
+
+// target 根据常见的请求参数推测操作对象，业务代码可以通过 service.AuditTarget 覆盖。
+func (adm *auditMiddle) target(c *ship.Context) string {
+	if modpath := c.Query("path"); modpath != "" {
+		if version := c.Query("version"); version != "" {
+			return modpath + "@" + version
+		}
+		return modpath
+	}
+	for _, key := range []string{"job_number", "name", "id"} {
+		if val := c.Query(key); val != "" {
+			return val
+		}
+	}
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+
+	return ""
+}
@@ -2,30 +2,26 @@ package middle
 
 import (
 	"net/http"
-	"net/netip"
 	"strings"
 	"time"
 
 	"github.com/dfcfw/goproxy/handler/session"
 	"github.com/dfcfw/goproxy/handler/shipx"
 	"github.com/xgfone/ship/v5"
-	"golang.org/x/net/publicsuffix"
 )
 
 func NewAuth(valid session.Validator) ship.Middleware {
 	atm := &authMiddle{
-		valid:      valid,
-		period:     time.Hour,
-		cookieName: "goproxy-bearer",
+		valid:  valid,
+		period: time.Hour,
 	}
 
 	return atm.call
 }
 
 type authMiddle struct {
-	valid      session.Validator
-	period     time.Duration
-	cookieName string // JWT 存放的 Cookie Name
+	valid  session.Validator
+	period time.Duration
 }
 
 func (atm *authMiddle) call(h ship.Handler) ship.Handler {
@@ -46,87 +42,101 @@ func (atm *authMiddle) call(h ship.Handler) ship.Handler {
 			return h(c)
 		}
 		if perm.UsePAT { // 如果使用 PAT 认证
-			name, _, _ := r.BasicAuth()
-			if sess, _ := atm.valid.ValidPAT(ctx, name); sess != nil {
+			token := atm.patToken(r)
+			if sess, _ := atm.valid.ValidPAT(ctx, token); sess != nil {
 				c.Data[sessKey] = sess
+				if perm.AdminPAT && !sess.Admin {
+					return ship.ErrForbidden
+				}
 				return h(c)
 			}
 
 			return atm.needAuth(c)
 		}
 
-		sess := atm.parseUser(c)
+		sess, err := atm.parseUser(c, perm.AllowPAT)
+		if err != nil {
+			return err
+		}
 		if sess == nil {
 			return atm.needAuth(c)
 		}
+		// 即使没有权限也先放入 session，便于审计日志记录操作人。
+		c.Data[sessKey] = sess
 		if !perm.Logon && !sess.Admin {
 			return ship.ErrForbidden
 		}
-
-		c.Data[sessKey] = sess
+		// 模拟登录只用于排查问题，禁止以他人身份做任何修改操作。
+		if sess.Impersonated() && !perm.Impersonated && !atm.safeMethod(r.Method) {
+			return errImpersonateReadOnly
+		}
 
 		return h(c)
 	}
 }
 
-func (atm *authMiddle) parseUser(c *ship.Context) *session.Userinfo {
-	// 先从 cookie 中的解析 jwt。
+var (
+	errImpersonateReadOnly = ship.ErrForbidden.Newf("模拟登录期间禁止修改操作")
+	errPATNotAllowed       = ship.ErrForbidden.Newf("该接口不允许使用 PAT 访问")
+)
+
+// parseUser 解析登录会话，allowPAT 为 true 时也接受 Bearer PAT。
+//
+// PAT 只用于 CI 等自动化场景，其它接口（例如管理用户、创建 PAT）必须使用登录会话，
+// 避免 PAT 泄露后被用来提权或者签发新的 PAT。
+func (atm *authMiddle) parseUser(c *ship.Context, allowPAT bool) (*session.Userinfo, error) {
 	r := c.Request()
 	ctx := r.Context()
-	if cookie, _ := r.Cookie(atm.cookieName); cookie != nil {
+	if token, found := strings.CutPrefix(r.Header.Get(ship.HeaderAuthorization), "Bearer "); found {
+		if !allowPAT {
+			return nil, errPATNotAllowed
+		}
+		info, _ := atm.valid.ValidPAT(ctx, strings.TrimSpace(token))
+		return info, nil
+	}
+	// 先从 cookie 中的解析 jwt。
+	if cookie, _ := r.Cookie(session.CookieName); cookie != nil {
 		info, err := atm.valid.ValidJWT(ctx, cookie.Value)
 		if err == nil {
-			return info
+			return info, nil
 		}
 	}
 
 	jobNumber, passwd, ok := r.BasicAuth()
 	if !ok || jobNumber == "" || passwd == "" {
-		return nil
+		return nil, nil
 	}
 
 	info, err := atm.valid.ValidCAS(ctx, jobNumber, passwd)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 	bearer, err := atm.valid.SignJWT(jobNumber, atm.period)
 	if err != nil {
-		return nil
+		return nil, nil
 	}
 
 	expiredAt := time.Now().Add(atm.period)
-	domain := atm.cookieDomain(c.Host())
-	cookie := &http.Cookie{
-		Name:     atm.cookieName,
-		Value:    bearer,
-		Expires:  expiredAt,
-		Domain:   domain,
-		Path:     "/",
-		SameSite: http.SameSiteLaxMode,
-		HttpOnly: true,
-	}
+	cookie := session.NewCookie(c.Host(), bearer, expiredAt)
 	c.SetCookie(cookie)
 
-	return info
+	return info, nil
 }
 
-func (atm *authMiddle) cookieDomain(host string) string {
-	if addr, err := netip.ParseAddr(host); err == nil {
-		return addr.String()
+// patToken 获取 PAT：支持 Bearer Token，或者 Basic Auth 的用户名（go 命令使用该方式）。
+func (atm *authMiddle) patToken(r *http.Request) string {
+	if token, found := strings.CutPrefix(r.Header.Get(ship.HeaderAuthorization), "Bearer "); found {
+		return strings.TrimSpace(token)
 	}
+	name, _, _ := r.BasicAuth()
 
-	suffix, _ := publicsuffix.PublicSuffix(host)
-	if suffix == "" {
-		return ""
-	}
-
-	before, _ := strings.CutSuffix(host, suffix)
-	splits := strings.Split(strings.Trim(before, "."), ".")
-	if size := len(splits); size != 0 {
-		return splits[size-1] + "." + suffix
-	}
+	return name
+}
 
-	return ""
+func (atm *authMiddle) safeMethod(method string) bool {
+	return method == http.MethodGet ||
+		method == http.MethodHead ||
+		method == http.MethodOptions
 }
 
 func (atm *authMiddle) needAuth(c *ship.Context) error {
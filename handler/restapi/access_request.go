@@ -0,0 +1,112 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/handler/session"
+	"github.com/dfcfw/goproxy/handler/shipx"
+	"github.com/xgfone/ship/v5"
+)
+
+func NewAccessRequest(svc *service.AccessRequest) *AccessRequest {
+	return &AccessRequest{svc: svc}
+}
+
+type AccessRequest struct {
+	svc *service.AccessRequest
+}
+
+func (acr *AccessRequest) RegisterRoute(r *ship.RouteGroupBuilder) error {
+	// 申请人还不是系统用户，所以这两个接口允许匿名访问，在接口内部通过 CAS 校验身份。
+	r.Route("/api/access-request").
+		Data(shipx.NewRouteInfo("查看自己的访问申请").Anonymous().Map()).GET(acr.latest).
+		Data(shipx.NewRouteInfo("提交访问申请").Anonymous().Map()).POST(acr.submit)
+	r.Route("/api/access-requests").
+		Data(shipx.NewRouteInfo("查看访问申请列表").AllowPAT().Map()).GET(acr.list)
+	r.Route("/api/access-request/approve").
+		Data(shipx.NewRouteInfo("同意访问申请").AllowPAT().Map()).PUT(acr.approve)
+	r.Route("/api/access-request/deny").
+		Data(shipx.NewRouteInfo("拒绝访问申请").AllowPAT().Map()).PUT(acr.deny)
+
+	return nil
+}
+
+func (acr *AccessRequest) latest(c *ship.Context) error {
+	jobNumber, passwd, ok := c.Request().BasicAuth()
+	if !ok || jobNumber == "" || passwd == "" {
+		return acr.needAuth(c)
+	}
+
+	ctx := c.Request().Context()
+	ret, err := acr.svc.Latest(ctx, jobNumber, passwd)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (acr *AccessRequest) submit(c *ship.Context) error {
+	jobNumber, passwd, ok := c.Request().BasicAuth()
+	if !ok || jobNumber == "" || passwd == "" {
+		return acr.needAuth(c)
+	}
+
+	req := new(request.AccessRequestSubmit)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ret, err := acr.svc.Submit(ctx, jobNumber, passwd, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (acr *AccessRequest) list(c *ship.Context) error {
+	req := new(request.AccessRequestList)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ret, err := acr.svc.List(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (acr *AccessRequest) approve(c *ship.Context) error {
+	req := new(request.AccessRequestReview)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+
+	return acr.svc.Approve(ctx, sess.ID(), req)
+}
+
+func (acr *AccessRequest) deny(c *ship.Context) error {
+	req := new(request.AccessRequestReview)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+
+	return acr.svc.Deny(ctx, sess.ID(), req)
+}
+
+func (acr *AccessRequest) needAuth(c *ship.Context) error {
+	c.SetRespHeader(ship.HeaderWWWAuthenticate, `Basic realm="Restricted"`)
+
+	return ship.ErrUnauthorized
+}
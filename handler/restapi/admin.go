@@ -4,7 +4,9 @@ import (
 	"net/http"
 
 	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/errcode"
 	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/handler/session"
 	"github.com/dfcfw/goproxy/handler/shipx"
 	"github.com/xgfone/ship/v5"
 )
@@ -21,11 +23,15 @@ type User struct {
 
 func (usr *User) RegisterRoute(r *ship.RouteGroupBuilder) error {
 	r.Route("/api/users").
-		Data(shipx.NewRouteInfo("查看用户列表").Map()).GET(usr.list)
+		Data(shipx.NewRouteInfo("查看用户列表").AllowPAT().Map()).GET(usr.list)
 	r.Route("/api/user").
-		Data(shipx.NewRouteInfo("创建用户").Map()).POST(usr.create).
-		Data(shipx.NewRouteInfo("修改用户").Map()).PUT(usr.update).
-		Data(shipx.NewRouteInfo("删除用户").Map()).DELETE(usr.delete)
+		Data(shipx.NewRouteInfo("创建用户").AllowPAT().Map()).POST(usr.create).
+		Data(shipx.NewRouteInfo("修改用户").AllowPAT().Map()).PUT(usr.update).
+		Data(shipx.NewRouteInfo("删除用户").AllowPAT().Map()).DELETE(usr.delete)
+	r.Route("/api/user/disable").
+		Data(shipx.NewRouteInfo("禁用用户").AllowPAT().Map()).PUT(usr.disable)
+	r.Route("/api/user/enable").
+		Data(shipx.NewRouteInfo("启用用户").AllowPAT().Map()).PUT(usr.enable)
 
 	return nil
 }
@@ -69,3 +75,27 @@ func (usr *User) delete(c *ship.Context) error {
 
 	return usr.svc.Delete(ctx, req.JobNumber)
 }
+
+func (usr *User) disable(c *ship.Context) error {
+	req := new(request.JobNumber)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+	if sess.ID() == req.JobNumber {
+		return errcode.ErrDisableSelf
+	}
+
+	return usr.svc.Disable(ctx, req.JobNumber, true)
+}
+
+func (usr *User) enable(c *ship.Context) error {
+	req := new(request.JobNumber)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+
+	return usr.svc.Disable(ctx, req.JobNumber, false)
+}
@@ -0,0 +1,61 @@
+package restapi
+
+import (
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/handler/shipx"
+	"github.com/xgfone/ship/v5"
+)
+
+func NewAudit(svc *service.Audit) *Audit {
+	return &Audit{svc: svc}
+}
+
+type Audit struct {
+	svc *service.Audit
+}
+
+func (aud *Audit) RegisterRoute(r *ship.RouteGroupBuilder) error {
+	r.Route("/api/audit-events").
+		Data(shipx.NewRouteInfo("查看审计日志").AllowPAT().Map()).GET(aud.page)
+	r.Route("/api/audit-events/export").
+		Data(shipx.NewRouteInfo("导出审计日志").AllowPAT().Map()).GET(aud.export)
+
+	return nil
+}
+
+func (aud *Audit) page(c *ship.Context) error {
+	req := new(request.AuditEventPage)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ret, err := aud.svc.Page(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (aud *Audit) export(c *ship.Context) error {
+	req := new(request.AuditEventFilter)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+
+	name := "audit-" + time.Now().Format("20060102150405") + ".jsonl"
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	c.SetRespHeader(ship.HeaderContentDisposition, disposition)
+	c.SetContentType("application/x-ndjson; charset=utf-8")
+	c.WriteHeader(http.StatusOK)
+
+	ctx := c.Request().Context()
+
+	return aud.svc.Export(ctx, c.Response(), req)
+}
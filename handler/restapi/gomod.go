@@ -1,44 +1,81 @@
 package restapi
 
 import (
-	"archive/zip"
-	"bytes"
+	"io"
 	"mime"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/dfcfw/goproxy/business/service"
 	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/handler/session"
 	"github.com/dfcfw/goproxy/handler/shipx"
 	"github.com/xgfone/ship/v5"
 )
 
-func NewGomod(svc *service.Gomod) *Gomod {
+// CI 流水线上传模块时可以携带的元数据请求头。
+const (
+	HeaderCICommitSHA   = "X-Ci-Commit-Sha"
+	HeaderCIPipelineURL = "X-Ci-Pipeline-Url"
+	HeaderCIRepository  = "X-Ci-Repository"
+)
+
+// 格式转换下载 zip 时通过响应头返回转换报告。
+const (
+	HeaderGomodPath    = "X-Gomod-Path"    // 模块路径
+	HeaderGomodRoot    = "X-Gomod-Root"    // 剥离的目录前缀
+	HeaderGomodOmitted = "X-Gomod-Omitted" // 被排除的文件个数
+)
+
+// HeaderGomodMissing 导出离线依赖包时通过响应头返回仓库中缺失的依赖个数。
+const HeaderGomodMissing = "X-Gomod-Missing"
+
+func NewGomod(svc *service.Gomod, vcs *service.GomodVCS) *Gomod {
 	return &Gomod{
 		svc: svc,
+		vcs: vcs,
 	}
 }
 
 type Gomod struct {
 	svc *service.Gomod
+	vcs *service.GomodVCS
 }
 
 func (gmd *Gomod) RegisterRoute(r *ship.RouteGroupBuilder) error {
 	r.Route("/api/gomod/walk").
-		Data(shipx.NewRouteInfo("查看目录").Logon().Map()).GET(gmd.walk)
+		Data(shipx.NewRouteInfo("查看目录").Logon().AllowPAT().Map()).GET(gmd.walk)
 	r.Route("/api/gomod/stat").
-		Data(shipx.NewRouteInfo("获取特定版本文件列表").Logon().Map()).GET(gmd.stat)
+		Data(shipx.NewRouteInfo("获取特定版本文件列表").Logon().AllowPAT().Map()).GET(gmd.stat)
 	r.Route("/api/gomod/file").
-		Data(shipx.NewRouteInfo("下载文件").Logon().Map()).GET(gmd.file)
+		Data(shipx.NewRouteInfo("下载文件").Logon().AllowPAT().Map()).GET(gmd.file)
 	r.Route("/api/gomod/sniff").
-		Data(shipx.NewRouteInfo("探测模块版本信息").Map()).PUT(gmd.sniff)
+		Data(shipx.NewRouteInfo("探测模块版本信息").AllowPAT().Map()).PUT(gmd.sniff)
 	r.Route("/api/gomod/upload").
-		Data(shipx.NewRouteInfo("上传模块文件").Map()).PUT(gmd.upload)
+		Data(shipx.NewRouteInfo("上传模块文件").AllowPAT().Map()).PUT(gmd.upload)
+	r.Route("/api/gomod/upload-repo").
+		Data(shipx.NewRouteInfo("上传多模块仓库").AllowPAT().Map()).PUT(gmd.uploadRepo)
+	r.Route("/api/gomod/publish-vcs").
+		Data(shipx.NewRouteInfo("从 git 仓库发布模块").AllowPAT().Map()).PUT(gmd.publishVCS)
 	r.Route("/api/gomod/format").
-		Data(shipx.NewRouteInfo("格式转换").Map()).PUT(gmd.format)
+		Data(shipx.NewRouteInfo("格式转换").AllowPAT().Map()).PUT(gmd.format)
+	r.Route("/api/gomod/bundle").
+		Data(shipx.NewRouteInfo("导出离线依赖包").Logon().AllowPAT().Map()).GET(gmd.bundle).
+		Data(shipx.NewRouteInfo("根据 go.mod 导出离线依赖包").Logon().AllowPAT().Map()).PUT(gmd.bundleGomod)
+	r.Route("/api/gomod/graph").
+		Data(shipx.NewRouteInfo("查看模块依赖图").Logon().AllowPAT().Map()).GET(gmd.graph)
+	r.Route("/api/gomod/dependents").
+		Data(shipx.NewRouteInfo("查看反向依赖").Logon().AllowPAT().Map()).GET(gmd.dependents)
 	r.Route("/api/gomod").
-		Data(shipx.NewRouteInfo("格式转换").Map()).DELETE(gmd.delete)
+		Data(shipx.NewRouteInfo("删除模块").AllowPAT().Map()).DELETE(gmd.delete)
+	r.Route("/api/gomod/trash").
+		Data(shipx.NewRouteInfo("查看回收站").AllowPAT().Map()).GET(gmd.trash)
+	r.Route("/api/gomod/trash/restore").
+		Data(shipx.NewRouteInfo("从回收站恢复").AllowPAT().Map()).PUT(gmd.restore)
 
 	return nil
 }
@@ -97,17 +134,66 @@ func (gmd *Gomod) upload(c *ship.Context) error {
 		return err
 	}
 
+	size := req.File.Size
 	file, err := req.File.Open()
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	return gmd.svc.Upload(file, req.Path, req.Version)
+	ctx := c.Request().Context()
+	pub := publisher(c)
+
+	ret, err := gmd.svc.Publish(ctx, pub, file, size, req.Path, req.Version, req.DryRun)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (gmd *Gomod) uploadRepo(c *ship.Context) error {
+	req := new(request.GomodRepoUpload)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	size := req.File.Size
+	file, err := req.File.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tags := strings.FieldsFunc(req.Tags, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	ctx := c.Request().Context()
+	pub := publisher(c)
+	ret, err := gmd.svc.PublishRepo(ctx, pub, file, size, tags, req.DryRun)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (gmd *Gomod) publishVCS(c *ship.Context) error {
+	req := new(request.GomodVCSPublish)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	pub := publisher(c)
+	ret, err := gmd.vcs.Publish(ctx, pub, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
 }
 
 func (gmd *Gomod) format(c *ship.Context) error {
-	req := new(request.GomodUpload)
+	req := new(request.GomodFormat)
 	if err := c.Bind(req); err != nil {
 		return err
 	}
@@ -119,18 +205,137 @@ func (gmd *Gomod) format(c *ship.Context) error {
 	}
 	defer file.Close()
 
-	rd, err := zip.NewReader(file, size)
+	rd, cleanup, err := gmd.svc.OpenArchive(file, size)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	temp, report, err := gmd.svc.FormatTemp(rd, req.Path, req.Version)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	if req.Publish {
+		ctx := c.Request().Context()
+		pub := publisher(c)
+		ret, err := gmd.svc.Upload(ctx, pub, temp, report.Path, report.Version, req.DryRun)
+		if err != nil {
+			return err
+		}
+		ret.Format = report
+
+		return c.JSON(http.StatusOK, ret)
+	}
+
+	c.SetRespHeader(HeaderGomodPath, report.Path)
+	c.SetRespHeader(HeaderGomodRoot, report.Root)
+	c.SetRespHeader(HeaderGomodOmitted, strconv.Itoa(len(report.Omitted)))
+	if inf, _ := temp.Stat(); inf != nil {
+		c.SetRespHeader(ship.HeaderContentLength, strconv.FormatInt(inf.Size(), 10))
+	}
+
+	return c.Stream(http.StatusOK, "application/zip", temp)
+}
+
+func (gmd *Gomod) bundle(c *ship.Context) error {
+	req := new(request.GomodBundle)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	bdl, err := gmd.svc.BundleVersion(ctx, req.Path, req.Version)
+	if err != nil {
+		return err
+	}
+
+	return gmd.writeBundle(c, bdl, req.Plan, req.Path+"@"+req.Version)
+}
+
+func (gmd *Gomod) bundleGomod(c *ship.Context) error {
+	req := new(request.GomodBundleFile)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, 1<<20))
+	_ = file.Close()
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	bdl, err := gmd.svc.BundleGomod(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	return gmd.writeBundle(c, bdl, req.Plan, bdl.Report().Root)
+}
+
+// writeBundle plan 时只返回依赖清单，否则以附件形式下载离线依赖包。
+func (gmd *Gomod) writeBundle(c *ship.Context, bdl *service.GomodBundle, plan bool, name string) error {
+	report := bdl.Report()
+	if plan {
+		return c.JSON(http.StatusOK, report)
+	}
+
+	temp, err := bdl.WriteTemp()
 	if err != nil {
 		return err
 	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	if name == "" {
+		name = "bundle"
+	}
+	filename := "gomod-" + strings.NewReplacer("/", "_", "@", "-").Replace(name) + ".zip"
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	c.SetRespHeader(ship.HeaderContentDisposition, disposition)
+	c.SetRespHeader(HeaderGomodMissing, strconv.Itoa(len(report.Missing)))
+	if inf, _ := temp.Stat(); inf != nil {
+		c.SetRespHeader(ship.HeaderContentLength, strconv.FormatInt(inf.Size(), 10))
+	}
+
+	return c.Stream(http.StatusOK, "application/zip", temp)
+}
+
+func (gmd *Gomod) graph(c *ship.Context) error {
+	req := new(request.GomodGraph)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ret, err := gmd.svc.Graph(ctx, req.Path, req.Version)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (gmd *Gomod) dependents(c *ship.Context) error {
+	req := new(request.GomodDependents)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
 
-	buf := new(bytes.Buffer)
-	err = gmd.svc.Format(buf, rd, req.Path, req.Version)
+	ctx := c.Request().Context()
+	ret, err := gmd.svc.Dependents(ctx, req.Path, req.Version)
 	if err != nil {
 		return err
 	}
 
-	return c.Stream(http.StatusOK, "application/zip", buf)
+	return c.JSON(http.StatusOK, ret)
 }
 
 func (gmd *Gomod) file(c *ship.Context) error {
@@ -166,7 +371,54 @@ func (gmd *Gomod) delete(c *ship.Context) error {
 	if err := c.BindQuery(req); err != nil {
 		return err
 	}
-	modpath, name := req.Path, req.Version
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+
+	return gmd.svc.Delete(ctx, sess.JobNumber, req)
+}
+
+func (gmd *Gomod) trash(c *ship.Context) error {
+	req := new(request.GomodTrashList)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ret, err := gmd.svc.Trash(ctx, req.Path)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (gmd *Gomod) restore(c *ship.Context) error {
+	req := new(request.GomodTrashRestore)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	ret, err := gmd.svc.Restore(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+// publisher 根据 session 与 CI 请求头构造发布人信息。
+func publisher(c *ship.Context) *request.GomodPublisher {
+	pub := &request.GomodPublisher{
+		ClientIP:    c.ClientIP(),
+		CommitSHA:   c.GetReqHeader(HeaderCICommitSHA),
+		PipelineURL: c.GetReqHeader(HeaderCIPipelineURL),
+		Repository:  c.GetReqHeader(HeaderCIRepository),
+	}
+	if sess := session.FromMap(c.Data); sess != nil {
+		pub.JobNumber = sess.JobNumber
+		pub.TokenName = sess.TokenName
+	}
 
-	return gmd.svc.Delete(modpath, name)
+	return pub
 }
@@ -0,0 +1,227 @@
+package restapi
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/handler/session"
+	"github.com/dfcfw/goproxy/handler/shipx"
+	"github.com/xgfone/ship/v5"
+)
+
+// tus 断点续传协议 https://tus.io/protocols/resumable-upload 相关的请求头。
+const (
+	HeaderTusResumable   = "Tus-Resumable"
+	HeaderTusVersion     = "Tus-Version"
+	HeaderTusExtension   = "Tus-Extension"
+	HeaderTusMaxSize     = "Tus-Max-Size"
+	HeaderUploadLength   = "Upload-Length"
+	HeaderUploadOffset   = "Upload-Offset"
+	HeaderUploadMetadata = "Upload-Metadata"
+	HeaderUploadExpires  = "Upload-Expires"
+
+	tusVersion           = "1.0.0"
+	tusOffsetContentType = "application/offset+octet-stream"
+)
+
+// NewGomodUpload 断点续传接口，兼容 tus 1.0.0 核心协议以及 creation、expiration、termination 扩展。
+//
+// 文件全部上传完毕后需要调用 finalize 接口才会校验并发布，元数据通过 Upload-Metadata 传递：
+// path 模块路径，version 版本号，dry_run 只校验不发布。
+func NewGomodUpload(svc *service.GomodUpload) *GomodUpload {
+	return &GomodUpload{
+		svc: svc,
+	}
+}
+
+type GomodUpload struct {
+	svc *service.GomodUpload
+}
+
+func (gu *GomodUpload) RegisterRoute(r *ship.RouteGroupBuilder) error {
+	r.Route("/api/gomod/uploads").
+		Data(shipx.NewRouteInfo("断点续传协议信息").Anonymous().Map()).OPTIONS(gu.options).
+		Data(shipx.NewRouteInfo("创建断点续传").AllowPAT().Map()).POST(gu.create)
+	r.Route("/api/gomod/uploads/:id").
+		Data(shipx.NewRouteInfo("查询断点续传进度").AllowPAT().Map()).HEAD(gu.head).
+		Data(shipx.NewRouteInfo("查询断点续传进度").AllowPAT().Map()).GET(gu.get).
+		Data(shipx.NewRouteInfo("断点续传分块").AllowPAT().Map()).PATCH(gu.patch).
+		Data(shipx.NewRouteInfo("取消断点续传").AllowPAT().Map()).DELETE(gu.delete)
+	r.Route("/api/gomod/uploads/:id/finalize").
+		Data(shipx.NewRouteInfo("完成断点续传").AllowPAT().Map()).POST(gu.finalize)
+
+	return nil
+}
+
+func (gu *GomodUpload) options(c *ship.Context) error {
+	c.SetRespHeader(HeaderTusResumable, tusVersion)
+	c.SetRespHeader(HeaderTusVersion, tusVersion)
+	c.SetRespHeader(HeaderTusExtension, "creation,expiration,termination")
+	c.SetRespHeader(HeaderTusMaxSize, strconv.FormatInt(gu.svc.MaxSize(), 10))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (gu *GomodUpload) create(c *ship.Context) error {
+	if err := gu.checkVersion(c); err != nil {
+		return err
+	}
+
+	length, err := strconv.ParseInt(c.GetReqHeader(HeaderUploadLength), 10, 64)
+	if err != nil {
+		return err
+	}
+	meta := gu.metadata(c.GetReqHeader(HeaderUploadMetadata))
+	req := &request.GomodUploadCreate{
+		Path:    meta["path"],
+		Version: meta["version"],
+		DryRun:  meta["dry_run"] == "true",
+		Length:  length,
+	}
+	if req.Path == "" || req.Version == "" {
+		return ship.ErrBadRequest.Newf("Upload-Metadata 中缺少 path 或 version")
+	}
+
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+	dat, err := gu.svc.Create(ctx, sess.JobNumber, req)
+	if err != nil {
+		return err
+	}
+
+	location := strings.TrimSuffix(c.Request().URL.Path, "/") + "/" + strconv.FormatInt(dat.ID, 10)
+	c.SetRespHeader(ship.HeaderLocation, location)
+	gu.setHeaders(c, dat)
+
+	return c.JSON(http.StatusCreated, dat)
+}
+
+func (gu *GomodUpload) head(c *ship.Context) error {
+	dat, err := gu.load(c)
+	if err != nil {
+		return err
+	}
+	gu.setHeaders(c, dat)
+	c.SetRespHeader(HeaderUploadLength, strconv.FormatInt(dat.Length, 10))
+	c.SetRespHeader(ship.HeaderCacheControl, "no-store")
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (gu *GomodUpload) get(c *ship.Context) error {
+	dat, err := gu.load(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, dat)
+}
+
+func (gu *GomodUpload) patch(c *ship.Context) error {
+	if err := gu.checkVersion(c); err != nil {
+		return err
+	}
+	if ct := c.GetReqHeader(ship.HeaderContentType); ct != tusOffsetContentType {
+		return ship.ErrUnsupportedMediaType.Newf("Content-Type 必须是 " + tusOffsetContentType)
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	offset, err := strconv.ParseInt(c.GetReqHeader(HeaderUploadOffset), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+	dat, err := gu.svc.Append(ctx, sess.JobNumber, id, offset, c.Body())
+	if dat != nil {
+		gu.setHeaders(c, dat)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (gu *GomodUpload) delete(c *ship.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+	if err = gu.svc.Delete(ctx, sess.JobNumber, id); err != nil {
+		return err
+	}
+	c.SetRespHeader(HeaderTusResumable, tusVersion)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (gu *GomodUpload) finalize(c *ship.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	pub := publisher(c)
+	ret, err := gu.svc.Finalize(ctx, pub, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (gu *GomodUpload) load(c *ship.Context) (*model.GomodUpload, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+
+	return gu.svc.Get(ctx, sess.JobNumber, id)
+}
+
+func (gu *GomodUpload) setHeaders(c *ship.Context, dat *model.GomodUpload) {
+	c.SetRespHeader(HeaderTusResumable, tusVersion)
+	c.SetRespHeader(HeaderUploadOffset, strconv.FormatInt(dat.Offset, 10))
+	c.SetRespHeader(HeaderUploadExpires, dat.ExpiredAt.UTC().Format(http.TimeFormat))
+}
+
+// checkVersion 携带了 Tus-Resumable 请求头时校验协议版本，不携带时视为普通客户端。
+func (gu *GomodUpload) checkVersion(c *ship.Context) error {
+	if ver := c.GetReqHeader(HeaderTusResumable); ver != "" && ver != tusVersion {
+		c.SetRespHeader(HeaderTusVersion, tusVersion)
+		return ship.NewHTTPServerError(http.StatusPreconditionFailed).Newf("不支持的 tus 协议版本：%s", ver)
+	}
+
+	return nil
+}
+
+// metadata 解析 Upload-Metadata：逗号分隔的键值对，键与 base64 编码的值之间以空格分隔。
+func (gu *GomodUpload) metadata(s string) map[string]string {
+	ret := make(map[string]string, 4)
+	for _, pair := range strings.Split(s, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		raw, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(val))
+		ret[key] = string(raw)
+	}
+
+	return ret
+}
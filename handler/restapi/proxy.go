@@ -1,23 +1,119 @@
 package restapi
 
 import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/errcode"
 	"github.com/dfcfw/goproxy/handler/shipx"
 	"github.com/xgfone/ship/v5"
+	"golang.org/x/mod/module"
 )
 
-func NewProxy(dir string) *Proxy {
+func NewProxy(dir string, gmd *service.Gomod, mirror *service.GomodMirror) *Proxy {
 	return &Proxy{
-		dir: dir,
+		dir:    dir,
+		gmd:    gmd,
+		mirror: mirror,
 	}
 }
 
 type Proxy struct {
-	dir string
+	dir    string
+	gmd    *service.Gomod
+	mirror *service.GomodMirror
 }
 
 func (prx *Proxy) RegisterRoute(r *ship.RouteGroupBuilder) error {
-	r.Route("/private").
-		Data(shipx.NewRouteInfo("模块代理").UsePAT().Map()).Static(prx.dir)
+	r.Route("/private/*path").
+		Data(shipx.NewRouteInfo("模块代理").UsePAT().Map()).
+		GET(prx.serve).HEAD(prx.serve)
 
 	return nil
 }
+
+// serve 实现 GOPROXY 协议：模块存储中已有的文件直接返回，git 镜像中的模块按需解析版本并生成文件。
+func (prx *Proxy) serve(c *ship.Context) error {
+	name := strings.TrimPrefix(path.Clean("/"+c.Param("path")), "/")
+	escpath, file, found := strings.Cut(name, "/@v/")
+	latest := false
+	if !found {
+		escpath, latest = strings.CutSuffix(name, "/@latest")
+	}
+	modpath, err := module.UnescapePath(escpath)
+	if (!found && !latest) || err != nil || !prx.mirror.Match(modpath) {
+		if err = c.File(filepath.Join(prx.dir, filepath.FromSlash(name))); err == nil && found {
+			prx.record(c, modpath, file)
+		}
+		return err
+	}
+
+	ctx := c.Request().Context()
+	if latest {
+		info, err := prx.mirror.Latest(ctx, modpath)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, info)
+	}
+	if file == "list" {
+		versions, err := prx.mirror.List(ctx, modpath)
+		if err != nil {
+			return err
+		}
+		buf := new(strings.Builder)
+		for _, version := range versions {
+			buf.WriteString(version + "\n")
+		}
+		return c.Text(http.StatusOK, "%s", buf.String())
+	}
+
+	ext := path.Ext(file)
+	version, err := module.UnescapeVersion(strings.TrimSuffix(file, ext))
+	if err != nil {
+		return errcode.ErrNotFound
+	}
+	fpath := filepath.Join(prx.dir, escpath, "@v", filepath.FromSlash(file))
+	if inf, exx := os.Stat(fpath); exx == nil && !inf.IsDir() {
+		if err = c.File(fpath); err == nil {
+			prx.record(c, modpath, file)
+		}
+		return err
+	}
+
+	switch ext {
+	case ".info":
+		info, err := prx.mirror.Query(ctx, modpath, version)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, info)
+	case ".mod", ".zip":
+		if err = prx.mirror.Download(ctx, modpath, version); err != nil {
+			return err
+		}
+		if err = c.File(fpath); err == nil {
+			prx.record(c, modpath, file)
+		}
+		return err
+	}
+
+	return errcode.ErrNotFound
+}
+
+// record 记录模块 zip 的下载，file 为 @v 目录下的文件名，HEAD 请求与其它文件不记录。
+func (prx *Proxy) record(c *ship.Context, modpath, file string) {
+	escver, ok := strings.CutSuffix(file, ".zip")
+	if !ok || c.Method() != http.MethodGet {
+		return
+	}
+	version, err := module.UnescapeVersion(escver)
+	if err != nil {
+		return
+	}
+	prx.gmd.RecordDownload(c.Request().Context(), publisher(c), modpath, version)
+}
@@ -0,0 +1,258 @@
+package restapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/scim"
+	"github.com/dfcfw/goproxy/handler/shipx"
+	"github.com/xgfone/ship/v5"
+)
+
+func NewSCIM(svc *service.SCIM) *SCIM {
+	return &SCIM{
+		svc:   svc,
+		limit: 1 << 20,
+	}
+}
+
+// SCIM 供 HR 系统等身份提供方推送人员变动，使用管理员的 PAT（Bearer Token）认证。
+type SCIM struct {
+	svc   *service.SCIM
+	limit int64
+}
+
+func (sc *SCIM) RegisterRoute(r *ship.RouteGroupBuilder) error {
+	r.Route("/scim/v2/ServiceProviderConfig").
+		Data(shipx.NewRouteInfo("SCIM 服务配置").UseAdminPAT().Map()).GET(sc.wrap(sc.config))
+	r.Route("/scim/v2/Users").
+		Data(shipx.NewRouteInfo("SCIM 查询用户").UseAdminPAT().Map()).GET(sc.wrap(sc.listUsers)).
+		Data(shipx.NewRouteInfo("SCIM 创建用户").UseAdminPAT().Map()).POST(sc.wrap(sc.createUser))
+	r.Route("/scim/v2/Users/:id").
+		Data(shipx.NewRouteInfo("SCIM 获取用户").UseAdminPAT().Map()).GET(sc.wrap(sc.getUser)).
+		Data(shipx.NewRouteInfo("SCIM 替换用户").UseAdminPAT().Map()).PUT(sc.wrap(sc.replaceUser)).
+		Data(shipx.NewRouteInfo("SCIM 修改用户").UseAdminPAT().Map()).PATCH(sc.wrap(sc.patchUser)).
+		Data(shipx.NewRouteInfo("SCIM 删除用户").UseAdminPAT().Map()).DELETE(sc.wrap(sc.deleteUser))
+	r.Route("/scim/v2/Groups").
+		Data(shipx.NewRouteInfo("SCIM 查询用户组").UseAdminPAT().Map()).GET(sc.wrap(sc.listGroups)).
+		Data(shipx.NewRouteInfo("SCIM 创建用户组").UseAdminPAT().Map()).POST(sc.wrap(sc.createGroup))
+	r.Route("/scim/v2/Groups/:id").
+		Data(shipx.NewRouteInfo("SCIM 获取用户组").UseAdminPAT().Map()).GET(sc.wrap(sc.getGroup)).
+		Data(shipx.NewRouteInfo("SCIM 替换用户组").UseAdminPAT().Map()).PUT(sc.wrap(sc.replaceGroup)).
+		Data(shipx.NewRouteInfo("SCIM 修改用户组").UseAdminPAT().Map()).PATCH(sc.wrap(sc.patchGroup)).
+		Data(shipx.NewRouteInfo("SCIM 删除用户组").UseAdminPAT().Map()).DELETE(sc.wrap(sc.deleteGroup))
+
+	return nil
+}
+
+func (sc *SCIM) config(c *ship.Context) error {
+	supported := map[string]bool{"supported": true}
+	unsupported := map[string]bool{"supported": false}
+	ret := map[string]any{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          supported,
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": 1000},
+		"changePassword": unsupported,
+		"sort":           unsupported,
+		"etag":           unsupported,
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Personal Access Token",
+			"description": "使用管理员的 PAT 作为 Bearer Token",
+		}},
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) listUsers(c *ship.Context) error {
+	req := new(request.SCIMList)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.ListUsers(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) getUser(c *ship.Context) error {
+	ctx := c.Request().Context()
+	ret, err := sc.svc.GetUser(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) createUser(c *ship.Context) error {
+	req := new(scim.User)
+	if err := sc.bind(c, req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.CreateUser(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusCreated, ret)
+}
+
+func (sc *SCIM) replaceUser(c *ship.Context) error {
+	req := new(scim.User)
+	if err := sc.bind(c, req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.ReplaceUser(ctx, c.Param("id"), req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) patchUser(c *ship.Context) error {
+	req := new(scim.PatchOp)
+	if err := sc.bind(c, req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.PatchUser(ctx, c.Param("id"), req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) deleteUser(c *ship.Context) error {
+	ctx := c.Request().Context()
+	if err := sc.svc.DeleteUser(ctx, c.Param("id")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sc *SCIM) listGroups(c *ship.Context) error {
+	req := new(request.SCIMList)
+	if err := c.BindQuery(req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.ListGroups(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) getGroup(c *ship.Context) error {
+	ctx := c.Request().Context()
+	ret, err := sc.svc.GetGroup(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) createGroup(c *ship.Context) error {
+	req := new(scim.Group)
+	if err := sc.bind(c, req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.CreateGroup(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusCreated, ret)
+}
+
+func (sc *SCIM) replaceGroup(c *ship.Context) error {
+	req := new(scim.Group)
+	if err := sc.bind(c, req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.ReplaceGroup(ctx, c.Param("id"), req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) patchGroup(c *ship.Context) error {
+	req := new(scim.PatchOp)
+	if err := sc.bind(c, req); err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	ret, err := sc.svc.PatchGroup(ctx, c.Param("id"), req)
+	if err != nil {
+		return err
+	}
+
+	return sc.reply(c, http.StatusOK, ret)
+}
+
+func (sc *SCIM) deleteGroup(c *ship.Context) error {
+	ctx := c.Request().Context()
+	if err := sc.svc.DeleteGroup(ctx, c.Param("id")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// bind SCIM 报文的 Content-Type 为 application/scim+json，ship 默认的 Binder 无法识别。
+func (sc *SCIM) bind(c *ship.Context, v any) error {
+	rd := io.LimitReader(c.Body(), sc.limit)
+	if err := json.NewDecoder(rd).Decode(v); err != nil {
+		return scim.NewError(http.StatusBadRequest, "invalidSyntax", "报文格式错误："+err.Error())
+	}
+
+	return nil
+}
+
+func (sc *SCIM) reply(c *ship.Context, code int, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(code, scim.MIMEType, raw)
+}
+
+// wrap 将错误转换为 SCIM 规范的错误报文。
+func (sc *SCIM) wrap(h ship.Handler) ship.Handler {
+	return func(c *ship.Context) error {
+		err := h(c)
+		if err == nil {
+			return nil
+		}
+
+		se := new(scim.Error)
+		if !errors.As(err, &se) {
+			code, _, detail := shipx.UnwrapError(err)
+			se = scim.NewError(code, "", detail)
+		}
+
+		return sc.reply(c, se.StatusCode(), se)
+	}
+}
@@ -1,22 +1,42 @@
 package restapi
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/errcode"
+	"github.com/dfcfw/goproxy/contract/request"
 	"github.com/dfcfw/goproxy/handler/session"
 	"github.com/dfcfw/goproxy/handler/shipx"
 	"github.com/xgfone/ship/v5"
 )
 
-func NewSession() *Session {
-	return &Session{}
+func NewSession(valid session.Validator, log *slog.Logger) *Session {
+	return &Session{
+		valid:     valid,
+		log:       log,
+		period:    time.Hour,
+		impPeriod: 30 * time.Minute,
+		impLimit:  2 * time.Hour,
+	}
 }
 
-type Session struct{}
+type Session struct {
+	valid     session.Validator
+	log       *slog.Logger
+	period    time.Duration // 正常登录的 JWT 有效期
+	impPeriod time.Duration // 模拟登录默认有效期
+	impLimit  time.Duration // 模拟登录最长有效期
+}
 
 func (ses *Session) RegisterRoute(r *ship.RouteGroupBuilder) error {
 	r.Route("/api/session/info").
-		Data(shipx.NewRouteInfo("获取 session 信息").Logon().Map()).GET(ses.info)
+		Data(shipx.NewRouteInfo("获取 session 信息").Logon().AllowPAT().Map()).GET(ses.info)
+	r.Route("/api/session/impersonate").
+		Data(shipx.NewRouteInfo("模拟用户登录").Map()).POST(ses.impersonate).
+		Data(shipx.NewRouteInfo("退出模拟登录").Logon().Impersonated().Map()).DELETE(ses.unimpersonate)
 
 	return nil
 }
@@ -26,3 +46,63 @@ func (ses *Session) info(c *ship.Context) error {
 
 	return c.JSON(http.StatusOK, ret)
 }
+
+func (ses *Session) impersonate(c *ship.Context) error {
+	req := new(request.SessionImpersonate)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	period := ses.impPeriod
+	if req.Minutes > 0 {
+		period = min(time.Duration(req.Minutes)*time.Minute, ses.impLimit)
+	}
+
+	ctx := c.Request().Context()
+	sess := session.FromMap(c.Data)
+	bearer, err := ses.valid.Impersonate(ctx, sess.ID(), req.JobNumber, period)
+	if err != nil {
+		return err
+	}
+
+	expiredAt := time.Now().Add(period)
+	cookie := session.NewCookie(c.Host(), bearer, expiredAt)
+	c.SetCookie(cookie)
+	service.AuditTarget(ctx, req.JobNumber)
+	service.AuditDetail(ctx, "expired_at", expiredAt)
+	ses.log.WarnContext(ctx, "管理员开始模拟用户登录",
+		slog.String("admin", sess.ID()),
+		slog.String("job_number", req.JobNumber),
+		slog.Time("expired_at", expiredAt),
+	)
+
+	ret := &session.Userinfo{JobNumber: req.JobNumber, Impersonator: sess.ID()}
+
+	return c.JSON(http.StatusOK, ret)
+}
+
+// unimpersonate 退出模拟登录，恢复为管理员自己的身份。
+func (ses *Session) unimpersonate(c *ship.Context) error {
+	sess := session.FromMap(c.Data)
+	if !sess.Impersonated() {
+		return errcode.ErrNotImpersonated
+	}
+
+	admin := sess.Impersonator
+	bearer, err := ses.valid.SignJWT(admin, ses.period)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	expiredAt := time.Now().Add(ses.period)
+	cookie := session.NewCookie(c.Host(), bearer, expiredAt)
+	c.SetCookie(cookie)
+	service.AuditTarget(ctx, sess.ID())
+	ses.log.WarnContext(ctx, "管理员退出模拟用户登录",
+		slog.String("admin", admin),
+		slog.String("job_number", sess.ID()),
+	)
+
+	return nil
+}
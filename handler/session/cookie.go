@@ -0,0 +1,45 @@
+package session
+
+import (
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// CookieName JWT 存放的 Cookie Name。
+const CookieName = "goproxy-bearer"
+
+// NewCookie 构造存放 JWT 的 Cookie，host 用于计算 Cookie 的 Domain。
+func NewCookie(host, bearer string, expiredAt time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    bearer,
+		Expires:  expiredAt,
+		Domain:   cookieDomain(host),
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+		HttpOnly: true,
+	}
+}
+
+func cookieDomain(host string) string {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.String()
+	}
+
+	suffix, _ := publicsuffix.PublicSuffix(host)
+	if suffix == "" {
+		return ""
+	}
+
+	before, _ := strings.CutSuffix(host, suffix)
+	splits := strings.Split(strings.Trim(before, "."), ".")
+	if size := len(splits); size != 0 {
+		return splits[size-1] + "." + suffix
+	}
+
+	return ""
+}
@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/dfcfw/goproxy/business/jwtoken"
+	"github.com/dfcfw/goproxy/contract/errcode"
 	"github.com/dfcfw/goproxy/datalayer/model"
 	"github.com/dfcfw/goproxy/datalayer/query"
 	"github.com/dfcfw/goproxy/integration/casauth"
+	"gorm.io/gen/field"
 )
 
 type Validator interface {
@@ -16,6 +18,9 @@ type Validator interface {
 	ValidCAS(ctx context.Context, name, passwd string) (*Userinfo, error)
 	ValidJWT(ctx context.Context, token string) (*Userinfo, error)
 	SignJWT(jobNumber string, period time.Duration) (string, error)
+
+	// Impersonate 管理员 actor 模拟 jobNumber 登录，签发限时的 JWT。
+	Impersonate(ctx context.Context, actor, jobNumber string, period time.Duration) (string, error)
 }
 
 func NewValid(qry *query.Query, cas casauth.Client, tok *jwtoken.Issue, log *slog.Logger) Validator {
@@ -59,7 +64,7 @@ func (idt *identValid) ValidPAT(ctx context.Context, token string) (*Userinfo, e
 	if err != nil {
 		return nil, err
 	}
-	info := &Userinfo{JobNumber: jobNumber, Admin: user.Admin}
+	info := &Userinfo{JobNumber: jobNumber, Admin: user.Admin, TokenName: dat.Name}
 
 	return info, nil
 }
@@ -70,9 +75,11 @@ func (idt *identValid) ValidCAS(ctx context.Context, name, passwd string) (*User
 		return nil, err
 	}
 
-	if err = idt.cas.Auth(ctx, name, passwd); err != nil {
+	profile, err := idt.cas.Auth(ctx, name, passwd)
+	if err != nil {
 		return nil, err
 	}
+	idt.logon(ctx, user, profile)
 
 	info := &Userinfo{JobNumber: name, Admin: user.Admin}
 
@@ -92,6 +99,18 @@ func (idt *identValid) ValidJWT(ctx context.Context, token string) (*Userinfo, e
 	}
 	info := &Userinfo{JobNumber: jobNumber, Admin: user.Admin}
 
+	// 模拟登录：实际操作人必须仍然是有效的管理员。
+	if act := claim.Actor; act != nil {
+		actor, err := idt.valid(ctx, act.JobNumber)
+		if err != nil {
+			return nil, err
+		}
+		if !actor.Admin {
+			return nil, errcode.ErrImpersonateDenied
+		}
+		info.Impersonator = actor.JobNumber
+	}
+
 	return info, nil
 }
 
@@ -99,9 +118,50 @@ func (idt *identValid) SignJWT(jobNumber string, period time.Duration) (string,
 	return idt.tok.Sign(jobNumber, period)
 }
 
+func (idt *identValid) Impersonate(ctx context.Context, actor, jobNumber string, period time.Duration) (string, error) {
+	if actor == jobNumber {
+		return "", errcode.ErrImpersonateSelf
+	}
+	admin, err := idt.valid(ctx, actor)
+	if err != nil {
+		return "", err
+	}
+	if !admin.Admin {
+		return "", errcode.ErrImpersonateDenied
+	}
+	if _, err = idt.valid(ctx, jobNumber); err != nil {
+		return "", err
+	}
+
+	return idt.tok.Impersonate(actor, jobNumber, period)
+}
+
 func (idt *identValid) valid(ctx context.Context, jobNumber string) (*model.User, error) {
 	tbl := idt.qry.User
 	dao := tbl.WithContext(ctx)
 
-	return dao.Where(tbl.JobNumber.Eq(jobNumber)).First()
+	user, err := dao.Where(tbl.JobNumber.Eq(jobNumber)).First()
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, errcode.ErrUserDisabled
+	}
+
+	return user, nil
+}
+
+// logon 记录登录时间，并使用身份提供方返回的姓名更新用户资料。
+func (idt *identValid) logon(ctx context.Context, user *model.User, profile *casauth.Profile) {
+	now := time.Now()
+	tbl := idt.qry.User
+	dao := tbl.WithContext(ctx)
+
+	assigns := []field.AssignExpr{tbl.LastLoginAt.Value(now)}
+	if profile != nil && profile.Name != "" && profile.Name != user.Name {
+		assigns = append(assigns, tbl.Name.Value(profile.Name), tbl.UpdatedAt.Value(now))
+	}
+	if _, err := dao.Where(tbl.JobNumber.Eq(user.JobNumber)).UpdateColumnSimple(assigns...); err != nil {
+		idt.log.WarnContext(ctx, "更新用户登录信息错误", slog.String("job_number", user.JobNumber), slog.Any("error", err))
+	}
 }
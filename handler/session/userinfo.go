@@ -1,14 +1,21 @@
 package session
 
 type Userinfo struct {
-	JobNumber string `json:"job_number"`     // 工号
-	Admin     bool   `json:"admin,omitzero"` // 是否是管理员
+	JobNumber    string `json:"job_number"`            // 工号
+	Admin        bool   `json:"admin,omitzero"`        // 是否是管理员
+	Impersonator string `json:"impersonator,omitzero"` // 模拟登录的管理员工号，不为空说明当前处于模拟登录状态
+	TokenName    string `json:"token_name,omitzero"`   // 通过 PAT 认证时的 PAT 名字
 }
 
 func (u *Userinfo) ID() string {
 	return u.JobNumber
 }
 
+// Impersonated 是否处于模拟登录状态。
+func (u *Userinfo) Impersonated() bool {
+	return u.Impersonator != ""
+}
+
 var Key = sessionKey{}
 
 type sessionKey struct{}
@@ -31,6 +31,10 @@ func HandleError(c *ship.Context, e error) {
 		Method:   c.Method(),
 		Datetime: time.Now().UTC(),
 	}
+	var dt errcode.Detailer
+	if errors.As(e, &dt) {
+		pd.Errors = dt.Details()
+	}
 	_ = c.JSON(statusCode, pd)
 }
 
@@ -74,6 +78,10 @@ func UnwrapError(err error) (statusCode int, title string, detail string) {
 		case errors.Is(err, context.DeadlineExceeded):
 			detail = "操作超时"
 		}
+		var sc errcode.StatusCoder
+		if errors.As(err, &sc) {
+			statusCode = sc.StatusCode()
+		}
 	}
 
 	return
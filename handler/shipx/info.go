@@ -15,8 +15,17 @@ type Permission struct {
 	// UsePAT 是否使用 PAT 认证。
 	UsePAT bool
 
+	// AdminPAT 使用 PAT 认证，且 PAT 所属用户必须是管理员。
+	AdminPAT bool
+
 	// Logon 任何已登录用户均可访问。
 	Logon bool
+
+	// Impersonated 模拟登录期间也允许访问，默认模拟登录期间只允许 GET 等只读请求。
+	Impersonated bool
+
+	// AllowPAT 除登录会话外也接受 Bearer PAT 认证，供 CI 等自动化场景使用，默认只接受登录会话。
+	AllowPAT bool
 }
 
 var RouteInfoKey = routeInfoKey{}
@@ -32,10 +41,13 @@ func NewRouteInfo(name string) *RouteInfo {
 }
 
 type RouteInfo struct {
-	name      string
-	anonymous bool
-	usePAT    bool
-	logon     bool
+	name         string
+	anonymous    bool
+	usePAT       bool
+	adminPAT     bool
+	logon        bool
+	impersonated bool
+	allowPAT     bool
 }
 
 func (ri RouteInfo) Name() string {
@@ -44,14 +56,27 @@ func (ri RouteInfo) Name() string {
 
 func (ri RouteInfo) Perm() Permission {
 	return Permission{
-		Anonymous: ri.anonymous,
-		UsePAT:    ri.usePAT,
-		Logon:     ri.logon,
+		Anonymous:    ri.anonymous,
+		UsePAT:       ri.usePAT,
+		AdminPAT:     ri.adminPAT,
+		Logon:        ri.logon,
+		Impersonated: ri.impersonated,
+		AllowPAT:     ri.allowPAT,
 	}
 }
 
 func (ri RouteInfo) UsePAT() RouteInfo {
 	ri.usePAT = true
+	ri.adminPAT = false
+	ri.anonymous = false
+	ri.logon = false
+
+	return ri
+}
+
+func (ri RouteInfo) UseAdminPAT() RouteInfo {
+	ri.usePAT = true
+	ri.adminPAT = true
 	ri.anonymous = false
 	ri.logon = false
 
@@ -61,6 +86,7 @@ func (ri RouteInfo) UsePAT() RouteInfo {
 func (ri RouteInfo) Anonymous() RouteInfo {
 	ri.anonymous = true
 	ri.usePAT = false
+	ri.adminPAT = false
 	ri.logon = false
 
 	return ri
@@ -70,6 +96,21 @@ func (ri RouteInfo) Logon() RouteInfo {
 	ri.logon = true
 	ri.anonymous = false
 	ri.usePAT = false
+	ri.adminPAT = false
+
+	return ri
+}
+
+// Impersonated 模拟登录期间也允许访问（即使不是 GET 请求）。
+func (ri RouteInfo) Impersonated() RouteInfo {
+	ri.impersonated = true
+
+	return ri
+}
+
+// AllowPAT 除登录会话外也接受 Bearer PAT，权限仍按 Logon 或管理员判断。
+func (ri RouteInfo) AllowPAT() RouteInfo {
+	ri.allowPAT = true
 
 	return ri
 }
@@ -22,7 +22,13 @@ type Configurer interface {
 }
 
 type Client interface {
-	Auth(ctx context.Context, name, passwd string) error
+	// Auth 认证用户名密码，认证成功返回 CAS 提供的用户资料。
+	Auth(ctx context.Context, name, passwd string) (*Profile, error)
+}
+
+// Profile CAS 认证成功后返回的用户资料，部分字段取决于 CAS 服务端是否返回。
+type Profile struct {
+	Name string // 用户姓名
 }
 
 func NewClient(cfg Configurer, rtp http.RoundTripper, log *slog.Logger) Client {
@@ -39,21 +45,21 @@ type casClient struct {
 	log *slog.Logger
 }
 
-func (c casClient) Auth(ctx context.Context, name, passwd string) error {
+func (c casClient) Auth(ctx context.Context, name, passwd string) (*Profile, error) {
 	attrs := []any{slog.String("name", name)}
 	c.log.DebugContext(ctx, "开始CAS认证", attrs...)
 	strURL, err := c.cfg.Configure(ctx)
 	if err != nil {
 		attrs = append(attrs, slog.Any("error", err))
 		c.log.ErrorContext(ctx, "获取CAS配置错误", attrs...)
-		return err
+		return nil, err
 	}
 
 	reqURL, err := url.Parse(strURL)
 	if err != nil {
 		attrs = append(attrs, slog.Any("error", err))
 		c.log.ErrorContext(ctx, "获取CAS服务器地址错误", attrs...)
-		return err
+		return nil, err
 	}
 
 	sum := md5.Sum([]byte(passwd))
@@ -71,13 +77,13 @@ func (c casClient) Auth(ctx context.Context, name, passwd string) error {
 	if err != nil {
 		attrs = append(attrs, slog.Any("error", err))
 		c.log.ErrorContext(ctx, "构造 http.Request 错误", attrs...)
-		return err
+		return nil, err
 	}
 	resp, err := c.rtp.RoundTrip(req)
 	if err != nil {
 		attrs = append(attrs, slog.Any("error", err))
 		c.log.ErrorContext(ctx, "请求CAS服务器错误", attrs...)
-		return err
+		return nil, err
 	}
 	//goland:noinspection GoUnhandledErrorResult
 	defer resp.Body.Close()
@@ -86,10 +92,14 @@ func (c casClient) Auth(ctx context.Context, name, passwd string) error {
 	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
 		attrs = append(attrs, slog.Any("error", err))
 		c.log.ErrorContext(ctx, "读取 CAS 响应数据错误", attrs...)
-		return err
+		return nil, err
+	}
+
+	if err = res.checkError(); err != nil {
+		return nil, err
 	}
 
-	return res.checkError()
+	return &Profile{Name: res.UsrCnm}, nil
 }
 
 var errorCodes = map[string]string{
@@ -103,6 +113,7 @@ var errorCodes = map[string]string{
 type responseBody struct {
 	RspCde string `json:"rspCde"` // 业务响应码
 	RspMsg string `json:"rspMsg"` // 响应消息
+	UsrCnm string `json:"usrCnm"` // 用户姓名
 }
 
 func (rb responseBody) checkError() error {
@@ -0,0 +1,89 @@
+package launch
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/user"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/datalayer/model"
+	"github.com/dfcfw/goproxy/datalayer/query"
+)
+
+// CreateAdmin 直接操作数据库创建一个管理员，用户已存在时报错。
+func CreateAdmin(ctx context.Context, cfgFile, jobNumber, name string) error {
+	qry, err := openConfigQuery(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	log := slog.Default()
+	req := &request.UserUpsert{JobNumber: jobNumber, Name: name, Admin: true}
+	err = service.NewUser(qry, log).Create(ctx, req)
+	auditCLI(ctx, qry, "modsrv admin", "命令行创建管理员", jobNumber, err)
+
+	return err
+}
+
+// GrantAdmin 直接操作数据库将用户设置为管理员，用户不存在时会自动创建。
+//
+// 该方法不依赖 HTTP 服务与 CAS 认证，用于所有管理员都无法登录时的紧急授权。
+func GrantAdmin(ctx context.Context, cfgFile, jobNumber, name string) error {
+	qry, err := openConfigQuery(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	log := slog.Default()
+	err = service.NewUser(qry, log).Grant(ctx, jobNumber, name)
+	auditCLI(ctx, qry, "modsrv admin", "命令行授予管理员", jobNumber, err)
+
+	return err
+}
+
+// ListAdmins 直接查询数据库中的管理员。
+func ListAdmins(ctx context.Context, cfgFile string) ([]*model.User, error) {
+	qry, err := openConfigQuery(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewUser(qry, slog.Default()).Admins(ctx)
+}
+
+func openConfigQuery(cfgFile string) (*query.Query, error) {
+	cfg, err := readConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return openQuery(cfg.Database)
+}
+
+// auditCLI 命令行绕过了 HTTP 接口，需要主动写入审计日志，操作人记录为操作系统用户。
+func auditCLI(ctx context.Context, qry *query.Query, command, action, target string, err error) {
+	operator := "unknown"
+	if u, _ := user.Current(); u != nil {
+		operator = u.Username
+	}
+	hostname, _ := os.Hostname()
+
+	ctx = service.NewAuditContext(ctx)
+	service.AuditDetail(ctx, "os_user", operator)
+	evt := &model.AuditEvent{
+		Action:   action,
+		Method:   "CLI",
+		Path:     command,
+		Target:   target,
+		ClientIP: hostname,
+		Outcome:  model.AuditSucceed,
+	}
+	if err != nil {
+		evt.Outcome = model.AuditFailed
+		service.AuditDetail(ctx, "error", err.Error())
+	}
+
+	service.NewAudit(qry, slog.Default()).Write(ctx, evt)
+}
@@ -0,0 +1,102 @@
+package launch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/dfcfw/goproxy/business/service"
+	"github.com/dfcfw/goproxy/contract/request"
+	"github.com/dfcfw/goproxy/contract/response"
+)
+
+// ImportOptions 批量导入参数。
+type ImportOptions struct {
+	Dir        string   // 其它代理的磁盘目录或者 $GOMODCACHE/cache/download
+	GoSums     []string // 用于校验的 go.sum 文件，没有 .ziphash 文件时使用
+	Prefixes   []string // 只导入这些前缀的模块，为空表示全部
+	JobNumber  string   // 记录为发布人的工号
+	Unverified bool     // 没有 .ziphash 与 go.sum 记录的版本也导入
+	Force      bool     // 覆盖仓库中内容不同的同名版本
+	DryRun     bool     // 只校验不导入
+}
+
+// Import 直接读写模块存储与数据库，将其它代理或 GOMODCACHE 中的模块批量导入，
+// 每处理完一个版本调用一次 progress。导入遵循上传策略，可以重复执行。
+func Import(ctx context.Context, cfgFile string, opt *ImportOptions, progress func(i, total int, ret *response.GomodImported)) ([]*response.GomodImported, error) {
+	cfg, err := readConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	qry, err := openQuery(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := service.ReadGoSum(opt.GoSums...)
+	if err != nil {
+		return nil, err
+	}
+	ents, err := service.ScanImport(opt.Dir, sums)
+	if err != nil {
+		return nil, err
+	}
+	if len(opt.Prefixes) != 0 {
+		filtered := ents[:0]
+		for _, ent := range ents {
+			if matchPrefix(ent.Path, opt.Prefixes) {
+				filtered = append(filtered, ent)
+			}
+		}
+		ents = filtered
+	}
+
+	hostname, _ := os.Hostname()
+	pub := &request.GomodPublisher{JobNumber: opt.JobNumber, ClientIP: hostname}
+	gmd := service.NewGomod(moddir, trashdir, trashRetention(cfg), qry, newUploadPolicy(cfg), slog.Default())
+	rets := make([]*response.GomodImported, 0, len(ents))
+	for i, ent := range ents {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		ret := gmd.Import(ctx, pub, ent, opt.Unverified, opt.Force, opt.DryRun)
+		rets = append(rets, ret)
+		if progress != nil {
+			progress(i+1, len(ents), ret)
+		}
+	}
+	if !opt.DryRun {
+		result := err
+		if failed := countFailed(rets); result == nil && failed != 0 {
+			result = fmt.Errorf("%d 个版本导入失败或冲突", failed)
+		}
+		auditCLI(context.WithoutCancel(ctx), qry, "modsrv import", "命令行导入模块", opt.Dir, result)
+	}
+
+	return rets, err
+}
+
+func countFailed(rets []*response.GomodImported) int {
+	var n int
+	for _, ret := range rets {
+		if ret.Status == response.GomodImportFailed || ret.Status == response.GomodImportConflict {
+			n++
+		}
+	}
+
+	return n
+}
+
+// matchPrefix 模块路径是否属于某个前缀，前缀按路径元素匹配，例如 git.corp 匹配 git.corp/lib/log。
+func matchPrefix(modpath string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if modpath == prefix || strings.HasPrefix(modpath, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/dfcfw/goproxy/business/jwtoken"
 	"github.com/dfcfw/goproxy/business/service"
@@ -22,10 +23,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// 模块存储目录、断点续传的临时目录与回收站目录。
+const moddir, uploaddir, trashdir = "resources/mod/", "resources/upload/", "resources/trash/"
+
 func Run(ctx context.Context, cfgFile string) error {
-	const safeSize = 1 << 20
-	cfg := new(config.Config)
-	if err := jsonc.ReadFile(cfgFile, cfg, safeSize); err != nil { // 读取主配置文件
+	cfg, err := readConfig(cfgFile)
+	if err != nil {
 		return err
 	}
 
@@ -35,35 +38,51 @@ func Run(ctx context.Context, cfgFile string) error {
 //goland:noinspection GoUnhandledErrorResult
 func Exec(ctx context.Context, cfg *config.Config) error {
 	log := slog.Default()
-	srvCfg, dbCfg := cfg.Server, cfg.Database
-	db, err := gorm.Open(sqlite.Open(dbCfg.DSN))
+	srvCfg := cfg.Server
+	qry, err := openQuery(cfg.Database)
 	if err != nil {
 		return err
 	}
-	if err = db.AutoMigrate(model.All()...); err != nil {
-		return err
-	}
-	qry := query.Use(db)
 
 	httpClient := httpx.NewClient(http.DefaultClient)
 	casCfg := casauth.StringURL(srvCfg.CAS)
 	casClient := casauth.NewClient(casCfg, httpClient, log)
 
-	const moddir = "resources/mod/"
 	userSvc := service.NewUser(qry, log)
 	accessTokenSvc := service.NewAccessToken(qry, log)
-	gomodSvc := service.NewGomod(moddir, log)
+	accessRequestSvc := service.NewAccessRequest(qry, casClient, log)
+	gomodSvc := service.NewGomod(moddir, trashdir, trashRetention(cfg), qry, newUploadPolicy(cfg), log)
+	gomodUploadSvc := service.NewGomodUpload(uploaddir, gomodSvc, qry, log)
+	gomodVCSSvc := service.NewGomodVCS(cfg.Gomod.VCSRoots, gomodSvc, log)
+	gitMirrors := make([]service.GitMirror, 0, len(cfg.Gomod.Mirrors))
+	for _, m := range cfg.Gomod.Mirrors {
+		gitMirrors = append(gitMirrors, service.GitMirror{Module: m.Module, Dir: m.Dir})
+	}
+	gomodMirrorSvc := service.NewGomodMirror(gitMirrors, gomodSvc, log)
+	scimSvc := service.NewSCIM(qry, log)
+	auditSvc := service.NewAudit(qry, log)
+	if err = userSvc.Bootstrap(ctx, cfg.Admin.Bootstrap); err != nil {
+		return err
+	}
+
+	go gomodUploadSvc.Run(ctx, 10*time.Minute)
+	go gomodSvc.RunPurge(ctx, time.Hour)
 
 	jwtIssue := jwtoken.NewIssue(nil, log)
 	sessValid := session.NewValid(qry, casClient, jwtIssue, log)
 	authMiddle := middle.NewAuth(sessValid)
+	auditMiddle := middle.NewAudit(auditSvc)
 
 	restAPIs := []shipx.RouteRegister{
+		restapi.NewAccessRequest(accessRequestSvc),
 		restapi.NewAccessToken(accessTokenSvc),
-		restapi.NewGomod(gomodSvc),
-		restapi.NewSession(),
+		restapi.NewAudit(auditSvc),
+		restapi.NewGomod(gomodSvc, gomodVCSSvc),
+		restapi.NewGomodUpload(gomodUploadSvc),
+		restapi.NewSession(sessValid, log),
 		restapi.NewUser(userSvc),
-		restapi.NewProxy(moddir),
+		restapi.NewProxy(moddir, gomodSvc, gomodMirrorSvc),
+		restapi.NewSCIM(scimSvc),
 	}
 
 	shipHTTP := ship.Default()
@@ -76,7 +95,7 @@ func Exec(ctx context.Context, cfg *config.Config) error {
 		}
 	}
 
-	restRBG := rootRGB.Use(authMiddle)
+	restRBG := rootRGB.Use(auditMiddle, authMiddle)
 	if err = shipx.RegisterRoutes(restRBG, restAPIs); err != nil {
 		return err
 	}
@@ -96,6 +115,50 @@ func Exec(ctx context.Context, cfg *config.Config) error {
 	return err
 }
 
+func newUploadPolicy(cfg *config.Config) *service.UploadPolicy {
+	policyCfg := cfg.Gomod.Policy
+
+	return &service.UploadPolicy{
+		AllowedPrefixes: policyCfg.AllowedPrefixes,
+		ForbidReplace:   policyCfg.ForbidReplace,
+		ForbidExclude:   policyCfg.ForbidExclude,
+		RequireLicense:  policyCfg.RequireLicense,
+		MaxSize:         policyCfg.MaxSize,
+	}
+}
+
+// trashRetention 回收站的保留时长，默认 30 天。
+func trashRetention(cfg *config.Config) time.Duration {
+	days := cfg.Gomod.TrashDays
+	if days <= 0 {
+		days = 30
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
 func listenAndServe(errs chan error, srv *http.Server) {
 	errs <- srv.ListenAndServe()
 }
+
+func readConfig(cfgFile string) (*config.Config, error) {
+	const safeSize = 1 << 20
+	cfg := new(config.Config)
+	if err := jsonc.ReadFile(cfgFile, cfg, safeSize); err != nil { // 读取主配置文件
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func openQuery(dbCfg config.Database) (*query.Query, error) {
+	db, err := gorm.Open(sqlite.Open(dbCfg.DSN))
+	if err != nil {
+		return nil, err
+	}
+	if err = db.AutoMigrate(model.All()...); err != nil {
+		return nil, err
+	}
+
+	return query.Use(db), nil
+}
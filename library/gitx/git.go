@@ -0,0 +1,279 @@
+// Package gitx 调用本机 git 命令读取仓库信息，用于从 git 仓库发布 go 模块。
+package gitx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Open 打开 git 仓库，支持工作区与裸仓库（例如 git clone --mirror 的镜像）。
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repo{dir: dir}
+	out, err := r.run(ctx, nil, "rev-parse", "--is-bare-repository")
+	if err != nil {
+		return nil, fmt.Errorf("%s 不是 git 仓库：%w", dir, err)
+	}
+	r.bare = strings.TrimSpace(string(out)) == "true"
+
+	return r, nil
+}
+
+type Repo struct {
+	dir  string
+	bare bool
+}
+
+// Dir 仓库目录。
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Bare 是否是裸仓库。
+func (r *Repo) Bare() bool {
+	return r.bare
+}
+
+// Toplevel 工作区的根目录，裸仓库没有工作区。
+func (r *Repo) Toplevel(ctx context.Context) (string, error) {
+	out, err := r.run(ctx, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Dirty 工作区中 pathspec 范围内是否有未提交的修改（包括未跟踪的文件）。
+func (r *Repo) Dirty(ctx context.Context, pathspec string) (bool, error) {
+	out, err := r.run(ctx, nil, "status", "--porcelain", "--", pathspec)
+	if err != nil {
+		return false, err
+	}
+
+	return len(bytes.TrimSpace(out)) != 0, nil
+}
+
+// Commit 提交信息。
+type Commit struct {
+	Hash string    // 完整的提交哈希
+	Time time.Time // 提交时间（UTC）
+}
+
+// Commit 解析 rev（分支、标签或提交哈希）对应的提交。
+func (r *Repo) Commit(ctx context.Context, rev string) (*Commit, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("无效的版本引用：%s", rev)
+	}
+	out, err := r.run(ctx, nil, "-c", "log.showsignature=false", "log", "-n1", "--format=format:%H %ct", rev+"^{commit}", "--")
+	if err != nil {
+		return nil, err
+	}
+	hash, ts, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	var sec int64
+	if _, err = fmt.Sscan(ts, &sec); err != nil {
+		return nil, fmt.Errorf("解析提交时间出错：%w", err)
+	}
+
+	return &Commit{Hash: hash, Time: time.Unix(sec, 0).UTC()}, nil
+}
+
+// TagsAt 指向该提交的所有标签。
+func (r *Repo) TagsAt(ctx context.Context, hash string) ([]string, error) {
+	out, err := r.run(ctx, nil, "tag", "--points-at", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+// Tags 仓库中 prefix 开头且形如版本号的标签。
+func (r *Repo) Tags(ctx context.Context, prefix string) ([]string, error) {
+	out, err := r.run(ctx, nil, "tag", "--list", prefix+"v[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+// MergedTags 该提交可以追溯到的（包括指向该提交的）prefix 开头的 v* 标签，不校验标签是否为合法的版本号。
+func (r *Repo) MergedTags(ctx context.Context, hash, prefix string) ([]string, error) {
+	out, err := r.run(ctx, nil, "tag", "--merged", hash, "--list", prefix+"v*")
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(out)), nil
+}
+
+// ReadFile 读取某个提交中的文件。
+func (r *Repo) ReadFile(ctx context.Context, hash, name string) ([]byte, error) {
+	return r.run(ctx, nil, "cat-file", "blob", hash+":"+name)
+}
+
+// Archive 将某个提交的 subdir 目录打包为 zip 写入 w，subdir 为空表示整个仓库。
+func (r *Repo) Archive(ctx context.Context, w io.Writer, hash, subdir string) error {
+	args := []string{"-c", "core.autocrlf=input", "-c", "core.eol=lf", "archive", "--format=zip", hash}
+	if subdir != "" {
+		args = append(args, subdir)
+	}
+	_, err := r.run(ctx, w, args...)
+
+	return err
+}
+
+// Revision 模块版本在 git 仓库中对应的提交。
+type Revision struct {
+	Commit
+	Version string // 模块版本号，没有对应标签时为伪版本号
+	Ref     string // 对应的标签引用，伪版本号时为空
+}
+
+// Resolve 计算 rev 对应的模块版本号。
+//
+// 子目录中的模块（subdir 不为空）只匹配 subdir/vX.Y.Z 形式的标签，主版本号子目录（例如 api/v2）
+// 的标签前缀不包含主版本号目录。rev 本身或者指向该提交的标签是合法的版本号时使用该版本，
+// 否则根据可以追溯到的版本号最高的标签计算伪版本号。
+func (r *Repo) Resolve(ctx context.Context, rev, subdir, modpath string) (*Revision, error) {
+	commit, err := r.Commit(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Revision{Commit: *commit}
+
+	prefix := TagPrefix(subdir, modpath)
+	valid := func(tag string) (string, bool) {
+		version, ok := strings.CutPrefix(tag, prefix)
+		if !ok || !semver.IsValid(version) || module.Check(modpath, version) != nil {
+			return "", false
+		}
+		return version, true
+	}
+	if version, ok := valid(strings.TrimPrefix(rev, "refs/tags/")); ok {
+		ret.Version, ret.Ref = version, "refs/tags/"+prefix+version
+		return ret, nil
+	}
+	tags, err := r.TagsAt(ctx, commit.Hash)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if version, ok := valid(tag); ok && semver.Compare(version, ret.Version) > 0 {
+			ret.Version, ret.Ref = version, "refs/tags/"+tag
+		}
+	}
+	if ret.Version != "" {
+		return ret, nil
+	}
+
+	// 与 go 命令一致，基础版本是可以追溯到的版本号最高的标签，而不是距离最近的标签。
+	merged, err := r.MergedTags(ctx, commit.Hash, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var older string
+	for _, tag := range merged {
+		if version, ok := valid(tag); ok && semver.Compare(version, older) > 0 {
+			older = version
+		}
+	}
+	_, pathMajor, _ := module.SplitPathVersion(modpath)
+	major := module.PathMajorPrefix(pathMajor)
+	short := commit.Hash
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	ret.Version = module.PseudoVersion(major, older, commit.Time, short)
+
+	return ret, nil
+}
+
+// PseudoValid 伪版本号是否与提交一致：时间与提交时间相同，修订号是提交哈希的前 12 位。
+//
+// 与 go 命令校验伪版本号的规则相同，基础版本可以是该提交可以追溯到的任意一个合法的标签，
+// 没有基础版本时主版本号必须与模块路径一致（根目录的模块为 v0）。
+func (r *Repo) PseudoValid(ctx context.Context, pseudo string, commit *Commit, prefix, modpath string) bool {
+	if module.Check(modpath, pseudo) != nil {
+		return false
+	}
+	if t, err := module.PseudoVersionTime(pseudo); err != nil || !t.Equal(commit.Time) {
+		return false
+	}
+	if rev, err := module.PseudoVersionRev(pseudo); err != nil || len(rev) != 12 || !strings.HasPrefix(commit.Hash, rev) {
+		return false
+	}
+	base, err := module.PseudoVersionBase(pseudo)
+	if err != nil {
+		return false
+	}
+	if base == "" {
+		_, pathMajor, _ := module.SplitPathVersion(modpath)
+		major := module.PathMajorPrefix(pathMajor)
+		if major == "" {
+			major = "v0"
+		}
+		return semver.Major(pseudo) == major
+	}
+	if module.Check(modpath, base) != nil {
+		return false
+	}
+	merged, err := r.MergedTags(ctx, commit.Hash, prefix)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(merged, prefix+base)
+}
+
+// TagPrefix 模块对应的标签前缀，主版本号子目录不属于标签前缀，例如 api/v2 目录中的
+// example.com/repo/api/v2 模块的标签为 api/v2.0.0。
+func TagPrefix(subdir, modpath string) string {
+	dir := strings.Trim(subdir, "/")
+	if _, major, ok := module.SplitPathVersion(modpath); ok && strings.HasPrefix(major, "/") {
+		if dir == major[1:] {
+			dir = ""
+		} else {
+			dir = strings.TrimSuffix(dir, major)
+		}
+	}
+	if dir == "" {
+		return ""
+	}
+
+	return dir + "/"
+}
+
+func (r *Repo) run(ctx context.Context, w io.Writer, args ...string) ([]byte, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stderr = stderr
+	if w != nil {
+		cmd.Stdout = w
+	} else {
+		cmd.Stdout = stdout
+	}
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		return nil, fmt.Errorf("执行 git 命令出错：%w: %s", err, msg)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package gitx_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dfcfw/goproxy/library/gitx"
+	"golang.org/x/mod/module"
+)
+
+func TestResolve(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, data string) {
+		name = filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(name), 0o755)
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("go.mod", "module example.com/repo\n")
+	write("tools/go.mod", "module example.com/repo/tools\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+	git("tag", "v1.2.0")
+	git("tag", "tools/v0.3.0")
+	write("a.go", "package repo\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "next")
+	head := git("rev-parse", "HEAD")
+
+	ctx := context.Background()
+	repo, err := gitx.Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		rev, subdir, modpath string
+		version, ref         string
+	}{
+		{"v1.2.0", "", "example.com/repo", "v1.2.0", "refs/tags/v1.2.0"},
+		{"HEAD~1", "", "example.com/repo", "v1.2.0", "refs/tags/v1.2.0"},
+		{"HEAD~1", "tools", "example.com/repo/tools", "v0.3.0", "refs/tags/tools/v0.3.0"},
+		{"HEAD", "", "example.com/repo", "v1.2.1-0.", ""},
+		{"HEAD", "tools", "example.com/repo/tools", "v0.3.1-0.", ""},
+	}
+	for _, c := range cases {
+		rev, err := repo.Resolve(ctx, c.rev, c.subdir, c.modpath)
+		if err != nil {
+			t.Fatalf("Resolve(%s, %s): %v", c.rev, c.subdir, err)
+		}
+		if !strings.HasPrefix(rev.Version, c.version) || rev.Ref != c.ref {
+			t.Errorf("Resolve(%s, %s) = %s %s, want %s %s", c.rev, c.subdir, rev.Version, rev.Ref, c.version, c.ref)
+		}
+		if c.ref == "" {
+			if !module.IsPseudoVersion(rev.Version) || !strings.HasSuffix(rev.Version, head[:12]) {
+				t.Errorf("Resolve(%s, %s) = %s, want pseudo-version of %s", c.rev, c.subdir, rev.Version, head)
+			}
+		}
+	}
+	if dirty, err := repo.Dirty(ctx, "."); err != nil || dirty {
+		t.Errorf("Dirty = %v, %v, want false", dirty, err)
+	}
+	write("tools/b.go", "package tools\n")
+	if dirty, err := repo.Dirty(ctx, "tools"); err != nil || !dirty {
+		t.Errorf("Dirty(tools) = %v, %v, want true", dirty, err)
+	}
+	if tags, err := repo.Tags(ctx, "tools/"); err != nil || len(tags) != 1 || tags[0] != "tools/v0.3.0" {
+		t.Errorf("Tags(tools/) = %v, %v", tags, err)
+	}
+
+	// 基础版本是可以追溯到的版本号最高的合法标签：不合法的 v1-rc 与距离更近但版本更低的
+	// v1.1.5 都不能作为基础版本。
+	git("tag", "v1.1.5")
+	git("tag", "v1-rc")
+	git("commit", "-q", "--allow-empty", "-m", "last")
+	last, err := repo.Commit(ctx, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := repo.Resolve(ctx, "HEAD", "", "example.com/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base, _ := module.PseudoVersionBase(rev.Version); base != "v1.2.0" {
+		t.Errorf("Resolve(HEAD) = %s, want base v1.2.0", rev.Version)
+	}
+	pseudos := []struct {
+		base, major string
+		valid       bool
+	}{
+		{"v1.2.0", "", true},
+		{"v1.1.5", "", true},
+		{"", "", true},
+		{"", "v1", false},
+		{"v1.3.0", "", false},
+	}
+	for _, p := range pseudos {
+		pseudo := module.PseudoVersion(p.major, p.base, last.Time, last.Hash[:12])
+		if got := repo.PseudoValid(ctx, pseudo, last, "", "example.com/repo"); got != p.valid {
+			t.Errorf("PseudoValid(%s) = %v, want %v", pseudo, got, p.valid)
+		}
+	}
+
+	bare := filepath.Join(t.TempDir(), "repo.git")
+	git("clone", "-q", "--mirror", dir, bare)
+	mirror, err := gitx.Open(ctx, bare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mirror.Bare() {
+		t.Errorf("%s should be a bare repository", bare)
+	}
+	if rev, err := mirror.Resolve(ctx, "tools/v0.3.0", "tools", "example.com/repo/tools"); err != nil || rev.Version != "v0.3.0" {
+		t.Errorf("mirror Resolve = %v, %v", rev, err)
+	}
+}
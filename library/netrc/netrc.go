@@ -0,0 +1,68 @@
+// Package netrc 读取 .netrc 文件中的登录信息。
+package netrc
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Login 读取 .netrc 中该主机的 login，与 go 命令一致，服务端把 login 当作 PAT。
+//
+// 文件位置优先使用 NETRC 环境变量，否则为用户目录下的 .netrc（Windows 为 _netrc）。
+func Login(host string) string {
+	name := os.Getenv("NETRC")
+	if name == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		base := ".netrc"
+		if runtime.GOOS == "windows" {
+			base = "_netrc"
+		}
+		name = filepath.Join(home, base)
+	}
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+
+	var machine, login, fallback string
+	fields := strings.Fields(string(raw))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if machine == host && login != "" {
+				return login
+			}
+			machine, login = "", ""
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "default":
+			if machine == host && login != "" {
+				return login
+			}
+			machine, login = "*", ""
+		case "login":
+			if i+1 < len(fields) {
+				i++
+				login = fields[i]
+				if machine == "*" && fallback == "" {
+					fallback = login
+				}
+			}
+		case "macdef":
+			// 宏定义到空行结束，这里只按字段解析，遇到宏定义后停止。
+			fields = fields[:i]
+		}
+	}
+	if machine == host && login != "" {
+		return login
+	}
+
+	return fallback
+}